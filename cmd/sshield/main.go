@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/Hootrix/sshield/internal/core/honeypot"
+	"github.com/Hootrix/sshield/internal/core/knock"
 	"github.com/Hootrix/sshield/internal/core/notify"
 	"github.com/Hootrix/sshield/internal/core/service"
 	"github.com/Hootrix/sshield/internal/core/ssh"
@@ -29,6 +31,9 @@ func init() {
 		ssh.NewCommand(),
 		// firewall.NewCommand(),
 		notify.NewCommand(),
+		notify.NewSessionsCommand(),
 		service.NewCommand(),
+		knock.NewCommand(),
+		honeypot.NewCommand(),
 	)
 }
@@ -4,7 +4,12 @@ go 1.21
 
 require (
 	github.com/fatih/color v1.16.0
+	github.com/klauspost/compress v1.17.9
+	github.com/oschwald/maxminddb-golang v1.13.1
 	github.com/spf13/cobra v1.8.1
+	github.com/ulikunitz/xz v0.5.12
+	golang.org/x/crypto v0.31.0
+	golang.org/x/sys v0.28.0
 	golang.org/x/term v0.27.0
 )
 
@@ -13,5 +18,4 @@ require (
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
-	golang.org/x/sys v0.28.0 // indirect
 )
@@ -0,0 +1,137 @@
+package firewall
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	// KnockTable 端口敲门使用的 nftables 表名
+	KnockTable = "sshield_knock"
+	// BanTable 自动封禁使用的 nftables 表名
+	BanTable = "sshield_ban"
+)
+
+// NFTables 通过 nft 命令管理 sshield 专用的 inet 表
+// 每个子系统使用独立的表，互不影响，卸载时整表删除即可
+type NFTables struct {
+	Table string
+}
+
+// NewNFTables 创建指定表名的 nftables 管理器
+func NewNFTables(table string) *NFTables {
+	return &NFTables{Table: table}
+}
+
+// Available 检查 nft 命令是否可用
+func Available() bool {
+	_, err := exec.LookPath("nft")
+	return err == nil
+}
+
+// KnockGate 端口敲门的防火墙规则参数
+type KnockGate struct {
+	SSHPort   int    // 需要保护的 SSH 端口
+	LogPorts  []int  // 需要记录 SYN 的敲门端口（tcp 模式）
+	LogPrefix string // 内核日志前缀
+}
+
+// SetupKnockGate 创建默认关闭 SSH 端口、仅放行已敲门来源的规则（重复调用会重建）
+func (n *NFTables) SetupKnockGate(gate KnockGate) error {
+	return n.run(n.knockGateScript(gate))
+}
+
+func (n *NFTables) knockGateScript(gate KnockGate) string {
+	var b strings.Builder
+	// 先声明再删除，保证表不存在时脚本也能执行
+	fmt.Fprintf(&b, "table inet %s\n", n.Table)
+	fmt.Fprintf(&b, "delete table inet %s\n", n.Table)
+	fmt.Fprintf(&b, "table inet %s {\n", n.Table)
+	b.WriteString("\tset allow4 { type ipv4_addr; flags timeout; }\n")
+	b.WriteString("\tset allow6 { type ipv6_addr; flags timeout; }\n")
+	b.WriteString("\tchain input {\n")
+	b.WriteString("\t\ttype filter hook input priority -10; policy accept;\n")
+	if len(gate.LogPorts) > 0 {
+		fmt.Fprintf(&b, "\t\ttcp dport { %s } tcp flags & (syn | ack) == syn log prefix %q drop\n",
+			joinPorts(gate.LogPorts), gate.LogPrefix)
+	}
+	fmt.Fprintf(&b, "\t\ttcp dport %d ct state established,related accept\n", gate.SSHPort)
+	fmt.Fprintf(&b, "\t\ttcp dport %d ip saddr @allow4 accept\n", gate.SSHPort)
+	fmt.Fprintf(&b, "\t\ttcp dport %d ip6 saddr @allow6 accept\n", gate.SSHPort)
+	fmt.Fprintf(&b, "\t\ttcp dport %d drop\n", gate.SSHPort)
+	b.WriteString("\t}\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// AllowSource 在限定时间内放行来源 IP，到期由 nftables 自动移除
+func (n *NFTables) AllowSource(ip net.IP, ttl time.Duration) error {
+	return n.run(n.elementScript("allow", ip, ttl))
+}
+
+func (n *NFTables) elementScript(setPrefix string, ip net.IP, ttl time.Duration) string {
+	set := setPrefix + "6"
+	if ip4 := ip.To4(); ip4 != nil {
+		set = setPrefix + "4"
+		ip = ip4
+	}
+	seconds := int(ttl.Round(time.Second) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return fmt.Sprintf("add element inet %s %s { %s timeout %ds }\n", n.Table, set, ip.String(), seconds)
+}
+
+// SetupBanSet 创建封禁集合与丢弃规则；已有的封禁条目会保留
+func (n *NFTables) SetupBanSet() error {
+	return n.run(n.banSetScript())
+}
+
+func (n *NFTables) banSetScript() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "table inet %s {\n", n.Table)
+	b.WriteString("\tset ban4 { type ipv4_addr; flags timeout; }\n")
+	b.WriteString("\tset ban6 { type ipv6_addr; flags timeout; }\n")
+	b.WriteString("\tchain input {\n")
+	b.WriteString("\t\ttype filter hook input priority -20; policy accept;\n")
+	b.WriteString("\t}\n")
+	b.WriteString("}\n")
+	// 只清空规则，不影响集合中的封禁条目
+	fmt.Fprintf(&b, "flush chain inet %s input\n", n.Table)
+	fmt.Fprintf(&b, "add rule inet %s input ip saddr @ban4 drop\n", n.Table)
+	fmt.Fprintf(&b, "add rule inet %s input ip6 saddr @ban6 drop\n", n.Table)
+	return b.String()
+}
+
+// BanSource 在限定时间内丢弃来源 IP 的所有入站流量
+func (n *NFTables) BanSource(ip net.IP, ttl time.Duration) error {
+	return n.run(n.elementScript("ban", ip, ttl))
+}
+
+// Teardown 删除整张表
+func (n *NFTables) Teardown() error {
+	return n.run(fmt.Sprintf("table inet %s\ndelete table inet %s\n", n.Table, n.Table))
+}
+
+func (n *NFTables) run(script string) error {
+	cmd := exec.Command("nft", "-f", "-")
+	cmd.Stdin = strings.NewReader(script)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("执行 nft 失败: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func joinPorts(ports []int) string {
+	parts := make([]string, len(ports))
+	for i, p := range ports {
+		parts[i] = fmt.Sprintf("%d", p)
+	}
+	return strings.Join(parts, ", ")
+}
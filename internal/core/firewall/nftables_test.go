@@ -0,0 +1,70 @@
+package firewall
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKnockGateScript(t *testing.T) {
+	n := NewNFTables(KnockTable)
+	script := n.knockGateScript(KnockGate{SSHPort: 2222, LogPorts: []int{7000, 8000}, LogPrefix: "sshield-knock "})
+
+	for _, want := range []string{
+		"delete table inet sshield_knock",
+		"set allow4 { type ipv4_addr; flags timeout; }",
+		`tcp dport { 7000, 8000 } tcp flags & (syn | ack) == syn log prefix "sshield-knock " drop`,
+		"tcp dport 2222 ip saddr @allow4 accept",
+		"tcp dport 2222 drop",
+	} {
+		if !strings.Contains(script, want) {
+			t.Fatalf("script missing %q:\n%s", want, script)
+		}
+	}
+
+	noLog := n.knockGateScript(KnockGate{SSHPort: 22})
+	if strings.Contains(noLog, "log prefix") {
+		t.Fatalf("expected no log rule without log ports:\n%s", noLog)
+	}
+}
+
+func TestElementScript(t *testing.T) {
+	n := NewNFTables(KnockTable)
+
+	got := n.elementScript("allow", net.ParseIP("203.0.113.5"), 30*time.Second)
+	want := "add element inet sshield_knock allow4 { 203.0.113.5 timeout 30s }\n"
+	if got != want {
+		t.Fatalf("ipv4 element = %q, want %q", got, want)
+	}
+
+	got = n.elementScript("allow", net.ParseIP("2001:db8::1"), 1500*time.Millisecond)
+	want = "add element inet sshield_knock allow6 { 2001:db8::1 timeout 2s }\n"
+	if got != want {
+		t.Fatalf("ipv6 element = %q, want %q", got, want)
+	}
+}
+
+func TestBanSetScriptKeepsElements(t *testing.T) {
+	n := NewNFTables(BanTable)
+	script := n.banSetScript()
+
+	if strings.Contains(script, "delete table") {
+		t.Fatalf("ban setup must not delete existing bans:\n%s", script)
+	}
+	for _, want := range []string{
+		"flush chain inet sshield_ban input",
+		"add rule inet sshield_ban input ip saddr @ban4 drop",
+		"add rule inet sshield_ban input ip6 saddr @ban6 drop",
+	} {
+		if !strings.Contains(script, want) {
+			t.Fatalf("script missing %q:\n%s", want, script)
+		}
+	}
+
+	got := n.elementScript("ban", net.ParseIP("192.0.2.10"), time.Hour)
+	want := "add element inet sshield_ban ban4 { 192.0.2.10 timeout 3600s }\n"
+	if got != want {
+		t.Fatalf("ban element = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,114 @@
+package honeypot
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Hootrix/sshield/internal/core/firewall"
+	"github.com/Hootrix/sshield/internal/core/notify"
+	"github.com/spf13/cobra"
+)
+
+const hostKeyFileName = "honeypot_ed25519_key"
+
+// NewCommand 返回 honeypot 子命令
+func NewCommand() *cobra.Command {
+	var (
+		port        int
+		hostKey     string
+		version     string
+		maxTries    int
+		notifyOn    bool
+		failLimit   int
+		failWindow  time.Duration
+		ban         bool
+		banAfter    int
+		banDuration time.Duration
+		passwords   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "honeypot",
+		Short: "在空闲端口运行 SSH 蜜罐",
+		Long: `在空闲端口（如修改 SSH 端口后的 22）运行 SSH 蜜罐。
+
+蜜罐完成 SSH 握手并记录尝试的用户名、密码、公钥指纹与客户端版本，所有认证均被拒绝。
+密码默认只记录长度与哈希（同一次运行内相同密码哈希相同）：事件会发送到所有通知渠道，
+管理员误连蜜罐时输入的真实密码不应出现在第三方服务中。确需明文时使用 --record-passwords。
+每次尝试会生成 honeypot_attempt 事件，并通过已配置的通知渠道发送。
+
+示例：
+  # 修改 SSH 端口后在 22 端口运行蜜罐
+  sudo sshield ssh port 2222
+  sudo sshield honeypot --port 22
+
+  # 每 IP 每小时最多通知 3 次，尝试 3 次后封禁 24 小时（依赖 nftables）
+  sudo sshield honeypot --port 22 --fail-limit 3 --fail-window 1h --ban --ban-after 3 --ban-duration 24h`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if hostKey == "" {
+				var err error
+				hostKey, err = notify.DefaultStatePath(hostKeyFileName)
+				if err != nil {
+					return err
+				}
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			sigCh := make(chan os.Signal, 1)
+			signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+			go func() {
+				<-sigCh
+				cancel()
+			}()
+
+			var b banner
+			if ban {
+				if !firewall.Available() {
+					return fmt.Errorf("未找到 nft 命令，自动封禁依赖 nftables")
+				}
+				nft := firewall.NewNFTables(firewall.BanTable)
+				if err := nft.SetupBanSet(); err != nil {
+					return err
+				}
+				b = nft
+			}
+
+			emitter := notify.NewEmitter(notify.EmitterOptions{
+				Notify:     notifyOn,
+				FailLimit:  failLimit,
+				FailWindow: failWindow,
+			})
+
+			server := NewServer(Options{
+				ListenAddr:      fmt.Sprintf(":%d", port),
+				HostKeyPath:     hostKey,
+				ServerVersion:   version,
+				MaxAuthTries:    maxTries,
+				BanAfter:        banAfter,
+				BanDuration:     banDuration,
+				RecordPasswords: passwords,
+			}, emitter, b)
+			return server.Run(ctx)
+		},
+	}
+
+	cmd.Flags().IntVar(&port, "port", 22, "蜜罐监听端口")
+	cmd.Flags().StringVar(&hostKey, "host-key", "", "主机密钥路径（默认自动选择，不存在时自动生成）")
+	cmd.Flags().StringVar(&version, "server-version", defaultServerVersion, "伪装的 SSH 版本标识")
+	cmd.Flags().IntVar(&maxTries, "max-auth-tries", 6, "每个连接允许的认证次数")
+	cmd.Flags().BoolVar(&notifyOn, "notify", true, "是否通过通知渠道发送事件")
+	cmd.Flags().IntVar(&failLimit, "fail-limit", 0, "每个 IP 通知限制数量（0 表示不限制）")
+	cmd.Flags().DurationVar(&failWindow, "fail-window", time.Hour, "通知限制时间窗口")
+	cmd.Flags().BoolVar(&ban, "ban", false, "自动封禁尝试登录的来源 IP（依赖 nftables）")
+	cmd.Flags().IntVar(&banAfter, "ban-after", 1, "同一 IP 尝试次数达到后封禁")
+	cmd.Flags().DurationVar(&banDuration, "ban-duration", 24*time.Hour, "封禁时长")
+	cmd.Flags().BoolVar(&passwords, "record-passwords", false, "在事件中记录明文密码（会发送到所有通知渠道，默认只记录长度与哈希）")
+
+	return cmd
+}
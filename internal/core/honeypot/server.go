@@ -0,0 +1,298 @@
+package honeypot
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Hootrix/sshield/internal/core/notify"
+	"github.com/Hootrix/sshield/internal/debug"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// 伪装的服务端版本号，与常见发行版的 OpenSSH 保持一致
+	defaultServerVersion = "SSH-2.0-OpenSSH_8.9p1 Ubuntu-3ubuntu0.10"
+	// 单个连接的最长存活时间
+	connDeadline = 30 * time.Second
+	// 同时处理的最大连接数，超出直接关闭
+	maxConcurrentConns = 64
+	// 记录到事件中的密码最大长度
+	maxSecretLen = 64
+)
+
+var errRejected = errors.New("permission denied")
+
+// banner 封禁来源 IP 的防火墙接口
+type banner interface {
+	BanSource(ip net.IP, ttl time.Duration) error
+}
+
+// eventSink 接收蜜罐事件（通常为 notify.Emitter）
+type eventSink interface {
+	Emit(event *notify.LoginEvent)
+}
+
+// Options 蜜罐参数
+type Options struct {
+	ListenAddr    string        // 监听地址，如 :22
+	HostKeyPath   string        // 主机密钥路径，不存在时自动生成
+	ServerVersion string        // SSH 版本标识
+	MaxAuthTries  int           // 每个连接允许的认证次数
+	BanAfter      int           // 同一 IP 尝试次数达到后封禁，0 表示不封禁
+	BanDuration   time.Duration // 封禁时长
+	// RecordPasswords 在事件中记录明文密码。事件会发送到所有通知渠道（包括第三方服务），
+	// 默认只记录长度与哈希，需要显式开启
+	RecordPasswords bool
+}
+
+// Server SSH 蜜罐：记录所有认证尝试并始终拒绝
+type Server struct {
+	opts    Options
+	emitter eventSink
+	banner  banner
+
+	// 密码哈希的随机密钥：同一次运行内相同密码的哈希一致，便于关联，又无法离线反查
+	hashKey []byte
+
+	mu       sync.Mutex
+	attempts map[string]int
+	banned   map[string]time.Time
+}
+
+// NewServer 创建蜜罐服务
+func NewServer(opts Options, emitter eventSink, b banner) *Server {
+	if opts.ServerVersion == "" {
+		opts.ServerVersion = defaultServerVersion
+	}
+	if opts.MaxAuthTries <= 0 {
+		opts.MaxAuthTries = 6
+	}
+	hashKey := make([]byte, 32)
+	_, _ = rand.Read(hashKey)
+	return &Server{
+		opts:     opts,
+		emitter:  emitter,
+		banner:   b,
+		hashKey:  hashKey,
+		attempts: make(map[string]int),
+		banned:   make(map[string]time.Time),
+	}
+}
+
+// Run 监听并处理连接，直到 ctx 结束
+func (s *Server) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.opts.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("监听 %s 失败: %w", s.opts.ListenAddr, err)
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve 在已有监听器上处理连接，直到 ctx 结束
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	signer, err := loadOrCreateHostKey(s.opts.HostKeyPath)
+	if err != nil {
+		_ = ln.Close()
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = ln.Close()
+	}()
+
+	fmt.Printf(">>> 蜜罐已启动：%s（版本 %s）\n", ln.Addr(), s.opts.ServerVersion)
+
+	sem := make(chan struct{}, maxConcurrentConns)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			return fmt.Errorf("接受连接失败: %w", err)
+		}
+
+		select {
+		case sem <- struct{}{}:
+		default:
+			debug.Printf("honeypot: 连接数已满，关闭 %s", conn.RemoteAddr())
+			_ = conn.Close()
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			s.handleConn(conn, signer)
+		}()
+	}
+}
+
+func (s *Server) handleConn(conn net.Conn, signer ssh.Signer) {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(connDeadline))
+
+	cfg := &ssh.ServerConfig{
+		ServerVersion: s.opts.ServerVersion,
+		MaxAuthTries:  s.opts.MaxAuthTries,
+		PasswordCallback: func(meta ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			s.record(meta, "password", s.passwordDetail(string(password)))
+			return nil, errRejected
+		},
+		PublicKeyCallback: func(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			s.record(meta, "publickey", fmt.Sprintf("key=%s %s", key.Type(), ssh.FingerprintSHA256(key)))
+			return nil, errRejected
+		},
+		KeyboardInteractiveCallback: func(meta ssh.ConnMetadata, challenge ssh.KeyboardInteractiveChallenge) (*ssh.Permissions, error) {
+			answers, err := challenge("", "", []string{"Password: "}, []bool{false})
+			if err != nil || len(answers) == 0 {
+				return nil, errRejected
+			}
+			s.record(meta, "keyboard-interactive", s.passwordDetail(answers[0]))
+			return nil, errRejected
+		},
+	}
+	cfg.AddHostKey(signer)
+
+	// 认证必然失败，握手返回错误后直接断开
+	sshConn, _, _, err := ssh.NewServerConn(conn, cfg)
+	if err == nil {
+		_ = sshConn.Close()
+		return
+	}
+	debug.Printf("honeypot: %s 断开: %v", conn.RemoteAddr(), err)
+}
+
+// record 生成蜜罐事件，并在达到阈值时封禁来源
+func (s *Server) record(meta ssh.ConnMetadata, method, detail string) {
+	ip, port := splitRemote(meta.RemoteAddr())
+	clientVersion := string(meta.ClientVersion())
+
+	event := &notify.LoginEvent{
+		Type:      notify.EventHoneypotAttempt,
+		User:      meta.User(),
+		IP:        ip,
+		Port:      port,
+		Method:    method,
+		Timestamp: time.Now(),
+		LogPath:   "honeypot:" + s.opts.ListenAddr,
+		Message:   fmt.Sprintf("%s client=%q", detail, clientVersion),
+	}
+	s.emitter.Emit(event)
+	s.maybeBan(ip)
+}
+
+func (s *Server) maybeBan(ip string) {
+	if s.banner == nil || s.opts.BanAfter <= 0 {
+		return
+	}
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return
+	}
+
+	s.mu.Lock()
+	now := time.Now()
+	if until, ok := s.banned[ip]; ok && now.Before(until) {
+		s.mu.Unlock()
+		return
+	}
+	s.attempts[ip]++
+	if s.attempts[ip] < s.opts.BanAfter {
+		s.mu.Unlock()
+		return
+	}
+	delete(s.attempts, ip)
+	s.banned[ip] = now.Add(s.opts.BanDuration)
+	for k, until := range s.banned {
+		if now.After(until) {
+			delete(s.banned, k)
+		}
+	}
+	s.mu.Unlock()
+
+	if err := s.banner.BanSource(addr, s.opts.BanDuration); err != nil {
+		log.Printf("封禁 %s 失败: %v", ip, err)
+		return
+	}
+	fmt.Printf(">>> 已封禁 %s（%v）\n", ip, s.opts.BanDuration)
+}
+
+// loadOrCreateHostKey 读取主机密钥，不存在时生成 ed25519 密钥并保存，保证重启后指纹不变
+func loadOrCreateHostKey(path string) (ssh.Signer, error) {
+	if data, err := os.ReadFile(path); err == nil {
+		signer, err := ssh.ParsePrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("解析主机密钥 %s 失败: %w", path, err)
+		}
+		return signer, nil
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("读取主机密钥失败: %w", err)
+	}
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("生成主机密钥失败: %w", err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "sshield honeypot")
+	if err != nil {
+		return nil, fmt.Errorf("编码主机密钥失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("创建密钥目录失败: %w", err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		return nil, fmt.Errorf("保存主机密钥失败: %w", err)
+	}
+	return ssh.NewSignerFromKey(priv)
+}
+
+func splitRemote(addr net.Addr) (string, int) {
+	host, portStr, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String(), 0
+	}
+	port, _ := strconv.Atoi(portStr)
+	return strings.TrimPrefix(host, "::ffff:"), port
+}
+
+// passwordDetail 返回写入事件的密码信息，默认不包含明文
+func (s *Server) passwordDetail(password string) string {
+	if s.opts.RecordPasswords {
+		return fmt.Sprintf("password=%q", truncate(password))
+	}
+	mac := hmac.New(sha256.New, s.hashKey)
+	mac.Write([]byte(password))
+	return fmt.Sprintf("password=<redacted len=%d hmac=%s>", len(password), hex.EncodeToString(mac.Sum(nil))[:12])
+}
+
+func truncate(s string) string {
+	if len(s) > maxSecretLen {
+		return s[:maxSecretLen] + "..."
+	}
+	return s
+}
@@ -0,0 +1,124 @@
+package honeypot
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Hootrix/sshield/internal/core/notify"
+	"golang.org/x/crypto/ssh"
+)
+
+type captureSink struct {
+	mu     sync.Mutex
+	events []notify.LoginEvent
+}
+
+func (c *captureSink) Emit(event *notify.LoginEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events = append(c.events, *event)
+}
+
+func (c *captureSink) snapshot() []notify.LoginEvent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]notify.LoginEvent(nil), c.events...)
+}
+
+type captureBanner struct {
+	mu  sync.Mutex
+	ips []string
+}
+
+func (b *captureBanner) BanSource(ip net.IP, ttl time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.ips = append(b.ips, ip.String())
+	return nil
+}
+
+func TestHoneypotCapturesPasswordAndRejects(t *testing.T) {
+	sink := &captureSink{}
+	ban := &captureBanner{}
+	server := NewServer(Options{
+		HostKeyPath: filepath.Join(t.TempDir(), "host_key"),
+		BanAfter:    1,
+		BanDuration: time.Hour,
+	}, sink, ban)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(ctx, ln) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	clientCfg := &ssh.ClientConfig{
+		User:            "admin",
+		Auth:            []ssh.AuthMethod{ssh.Password("hunter2")},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		ClientVersion:   "SSH-2.0-scanner_1.0",
+		Timeout:         5 * time.Second,
+	}
+	if _, err := ssh.Dial("tcp", ln.Addr().String(), clientCfg); err == nil {
+		t.Fatalf("expected honeypot to reject login")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(sink.snapshot()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	events := sink.snapshot()
+	if len(events) == 0 {
+		t.Fatalf("expected honeypot event")
+	}
+
+	ev := events[0]
+	if ev.Type != notify.EventHoneypotAttempt || ev.User != "admin" || ev.Method != "password" || ev.IP != "127.0.0.1" {
+		t.Fatalf("unexpected event: %+v", ev)
+	}
+	if strings.Contains(ev.Message, "hunter2") {
+		t.Fatalf("password must be redacted by default, got %q", ev.Message)
+	}
+	if !strings.Contains(ev.Message, "password=<redacted len=7 hmac=") || !strings.Contains(ev.Message, "SSH-2.0-scanner_1.0") {
+		t.Fatalf("expected redacted password and client version in message, got %q", ev.Message)
+	}
+
+	ban.mu.Lock()
+	defer ban.mu.Unlock()
+	if len(ban.ips) != 1 || ban.ips[0] != "127.0.0.1" {
+		t.Fatalf("expected source to be banned once, got %v", ban.ips)
+	}
+}
+
+func TestPasswordDetail(t *testing.T) {
+	s := NewServer(Options{}, nil, nil)
+	redacted := s.passwordDetail("hunter2")
+	if strings.Contains(redacted, "hunter2") || !strings.HasPrefix(redacted, "password=<redacted len=7 hmac=") {
+		t.Fatalf("unexpected redacted detail %q", redacted)
+	}
+	if again := s.passwordDetail("hunter2"); again != redacted {
+		t.Fatalf("same password must hash identically within a run: %q vs %q", again, redacted)
+	}
+	if other := s.passwordDetail("hunter3"); other == redacted {
+		t.Fatalf("different passwords must not share a hash")
+	}
+	if NewServer(Options{}, nil, nil).passwordDetail("hunter2") == redacted {
+		t.Fatalf("hash key must differ between runs")
+	}
+
+	s = NewServer(Options{RecordPasswords: true}, nil, nil)
+	if got := s.passwordDetail("hunter2"); got != `password="hunter2"` {
+		t.Fatalf("opt-in must record plaintext, got %q", got)
+	}
+}
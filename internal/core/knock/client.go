@@ -0,0 +1,83 @@
+package knock
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// 相邻两次敲门之间的间隔，避免乱序到达
+const knockInterval = 200 * time.Millisecond
+
+// tcp 模式下 SYN 会被丢弃，只需短暂等待即可
+const tcpKnockTimeout = 300 * time.Millisecond
+
+// SendOptions 敲门客户端参数
+type SendOptions struct {
+	Host     string
+	Mode     string
+	Sequence []int
+	SPAPort  int
+	Secret   []byte
+	AllowIP  net.IP // spa 模式下请求放行的地址（可选，默认使用数据包来源）
+}
+
+// Send 向目标主机发送敲门序列或 SPA 数据包
+func Send(opts SendOptions) error {
+	if opts.Host == "" {
+		return fmt.Errorf("目标主机不能为空")
+	}
+
+	switch opts.Mode {
+	case modeSPA:
+		if len(opts.Secret) == 0 {
+			return fmt.Errorf("spa 模式需要 --secret 或环境变量 %s", envSecretKey)
+		}
+		packet, err := buildSPAPacket(opts.Secret, time.Now(), opts.AllowIP)
+		if err != nil {
+			return err
+		}
+		conn, err := net.Dial("udp", net.JoinHostPort(opts.Host, strconv.Itoa(opts.SPAPort)))
+		if err != nil {
+			return fmt.Errorf("连接 %s 失败: %w", opts.Host, err)
+		}
+		defer conn.Close()
+		if _, err := conn.Write(packet); err != nil {
+			return fmt.Errorf("发送 SPA 数据包失败: %w", err)
+		}
+		fmt.Printf("✓ 已发送 SPA 数据包到 %s udp/%d\n", opts.Host, opts.SPAPort)
+		return nil
+	case modeUDP:
+		for i, port := range opts.Sequence {
+			if i > 0 {
+				time.Sleep(knockInterval)
+			}
+			conn, err := net.Dial("udp", net.JoinHostPort(opts.Host, strconv.Itoa(port)))
+			if err != nil {
+				return fmt.Errorf("连接 %s:%d 失败: %w", opts.Host, port, err)
+			}
+			_, err = conn.Write([]byte{0})
+			conn.Close()
+			if err != nil {
+				return fmt.Errorf("敲门 udp/%d 失败: %w", port, err)
+			}
+		}
+		fmt.Printf("✓ 已发送 UDP 敲门序列到 %s：%v\n", opts.Host, opts.Sequence)
+		return nil
+	case modeTCP:
+		for i, port := range opts.Sequence {
+			if i > 0 {
+				time.Sleep(knockInterval)
+			}
+			conn, err := net.DialTimeout("tcp", net.JoinHostPort(opts.Host, strconv.Itoa(port)), tcpKnockTimeout)
+			if err == nil {
+				conn.Close()
+			}
+		}
+		fmt.Printf("✓ 已发送 TCP 敲门序列到 %s：%v\n", opts.Host, opts.Sequence)
+		return nil
+	default:
+		return fmt.Errorf("不支持的敲门模式: %s（可选 spa|udp|tcp）", opts.Mode)
+	}
+}
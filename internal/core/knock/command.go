@@ -0,0 +1,146 @@
+package knock
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const envSecretKey = "SSHIELD_KNOCK_SECRET"
+
+// NewCommand 返回 knock 子命令
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "knock",
+		Short: "端口敲门 / 单包授权（SPA）",
+		Long: `端口敲门 / 单包授权（SPA）
+
+守护进程通过 nftables 默认关闭 SSH 端口，收到正确的敲门序列或签名的 SPA 数据包后，
+仅对来源 IP 在限定时间内放行。已建立的连接不受放行到期影响。
+
+模式：
+  spa   在 UDP 端口接收 HMAC-SHA256 签名的单个数据包（推荐）
+  udp   按顺序向若干 UDP 端口发送数据包
+  tcp   按顺序向若干 TCP 端口发送 SYN（由 nftables log 规则记录到内核日志）
+
+共享密钥可通过 --secret 或环境变量 ` + envSecretKey + ` 指定。`,
+	}
+
+	cmd.AddCommand(
+		newDaemonCmd(),
+		newSendCmd(),
+	)
+
+	return cmd
+}
+
+func newDaemonCmd() *cobra.Command {
+	var (
+		opts     DaemonOptions
+		sequence []int
+		secret   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "运行敲门守护进程（需要 root 权限）",
+		Long: `运行敲门守护进程（需要 root 权限）
+
+示例：
+  # SPA 模式，放行 30 秒
+  sudo sshield knock daemon --mode spa --spa-port 62201 --ssh-port 22 --open 30s
+
+  # TCP SYN 序列模式
+  sudo sshield knock daemon --mode tcp --sequence 7000,8000,9000`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if os.Geteuid() != 0 {
+				return fmt.Errorf("需要 root 权限，请使用 sudo 运行")
+			}
+
+			opts.Mode = strings.ToLower(strings.TrimSpace(opts.Mode))
+			opts.Sequence = sequence
+			opts.Secret = resolveSecret(secret)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			sigCh := make(chan os.Signal, 1)
+			signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+			go func() {
+				<-sigCh
+				cancel()
+			}()
+
+			return RunDaemon(ctx, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Mode, "mode", modeSPA, "敲门模式：spa｜udp｜tcp")
+	cmd.Flags().IntVar(&opts.SSHPort, "ssh-port", 22, "受保护的 SSH 端口")
+	cmd.Flags().IntSliceVar(&sequence, "sequence", nil, "敲门端口序列（udp/tcp 模式，如 7000,8000,9000）")
+	cmd.Flags().IntVar(&opts.SPAPort, "spa-port", 62201, "SPA 监听的 UDP 端口")
+	cmd.Flags().StringVar(&secret, "secret", "", "SPA 共享密钥（也可使用环境变量 "+envSecretKey+"）")
+	cmd.Flags().DurationVar(&opts.OpenFor, "open", 30*time.Second, "敲门成功后的放行时长")
+	cmd.Flags().DurationVar(&opts.StepTimeout, "step-timeout", 10*time.Second, "序列中相邻两次敲门的最大间隔")
+	cmd.Flags().DurationVar(&opts.MaxSkew, "max-skew", defaultSPAAge, "SPA 数据包允许的时间偏差")
+
+	return cmd
+}
+
+func newSendCmd() *cobra.Command {
+	var (
+		opts     SendOptions
+		sequence []int
+		secret   string
+		allowIP  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "send <host>",
+		Short: "向目标主机发送敲门序列或 SPA 数据包",
+		Long: `向目标主机发送敲门序列或 SPA 数据包
+
+示例：
+  SSHIELD_KNOCK_SECRET=xxx sshield knock send example.com --spa-port 62201 && ssh example.com
+  sshield knock send example.com --mode tcp --sequence 7000,8000,9000`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Host = args[0]
+			opts.Mode = strings.ToLower(strings.TrimSpace(opts.Mode))
+			opts.Sequence = sequence
+			opts.Secret = resolveSecret(secret)
+			if allowIP != "" {
+				opts.AllowIP = net.ParseIP(allowIP)
+				if opts.AllowIP == nil {
+					return fmt.Errorf("无效的放行地址: %s", allowIP)
+				}
+			}
+			if opts.Mode != modeSPA && len(opts.Sequence) == 0 {
+				return fmt.Errorf("%s 模式需要 --sequence", opts.Mode)
+			}
+			return Send(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Mode, "mode", modeSPA, "敲门模式：spa｜udp｜tcp")
+	cmd.Flags().IntSliceVar(&sequence, "sequence", nil, "敲门端口序列（udp/tcp 模式）")
+	cmd.Flags().IntVar(&opts.SPAPort, "spa-port", 62201, "SPA 目标 UDP 端口")
+	cmd.Flags().StringVar(&secret, "secret", "", "SPA 共享密钥（也可使用环境变量 "+envSecretKey+"）")
+	cmd.Flags().StringVar(&allowIP, "allow-ip", "", "请求放行的地址（默认使用数据包来源地址，NAT 后可显式指定）")
+
+	return cmd
+}
+
+func resolveSecret(flagValue string) []byte {
+	if flagValue != "" {
+		return []byte(flagValue)
+	}
+	return []byte(strings.TrimSpace(os.Getenv(envSecretKey)))
+}
@@ -0,0 +1,330 @@
+package knock
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Hootrix/sshield/internal/core/firewall"
+	"github.com/Hootrix/sshield/internal/debug"
+)
+
+const (
+	modeSPA = "spa"
+	modeUDP = "udp"
+	modeTCP = "tcp"
+)
+
+// 内核日志前缀，用于从 /dev/kmsg 中识别 nftables 记录的敲门 SYN
+const kmsgLogPrefix = "sshield-knock "
+
+var kmsgKnockRe = regexp.MustCompile(`SRC=(\S+) .*DPT=(\d+)`)
+
+// 读取 /dev/kmsg 连续出错时的退避间隔（按次数递增）与最大重试次数
+const (
+	kmsgRetryDelay    = 200 * time.Millisecond
+	kmsgMaxReadErrors = 10
+)
+
+// gate 放行来源 IP 的防火墙接口
+type gate interface {
+	AllowSource(ip net.IP, ttl time.Duration) error
+}
+
+// DaemonOptions 敲门守护进程参数
+type DaemonOptions struct {
+	Mode        string        // spa｜udp｜tcp
+	SSHPort     int           // 受保护的 SSH 端口
+	Sequence    []int         // udp/tcp 模式的敲门端口序列
+	SPAPort     int           // spa 模式监听的 UDP 端口
+	Secret      []byte        // spa 模式共享密钥
+	OpenFor     time.Duration // 敲门成功后的放行时长
+	StepTimeout time.Duration // 相邻两次敲门的最大间隔
+	MaxSkew     time.Duration // spa 数据包允许的时间偏差
+}
+
+// RunDaemon 建立防火墙规则并监听敲门请求，退出时删除规则
+func RunDaemon(ctx context.Context, opts DaemonOptions) error {
+	if err := validateDaemonOptions(opts); err != nil {
+		return err
+	}
+	if !firewall.Available() {
+		return fmt.Errorf("未找到 nft 命令，端口敲门依赖 nftables")
+	}
+
+	nft := firewall.NewNFTables(firewall.KnockTable)
+	gateCfg := firewall.KnockGate{SSHPort: opts.SSHPort}
+	if opts.Mode == modeTCP {
+		gateCfg.LogPorts = opts.Sequence
+		gateCfg.LogPrefix = kmsgLogPrefix
+	}
+	if err := nft.SetupKnockGate(gateCfg); err != nil {
+		return err
+	}
+	defer func() {
+		if err := nft.Teardown(); err != nil {
+			log.Printf("清理防火墙规则失败: %v", err)
+		}
+	}()
+	fmt.Printf(">>> SSH 端口 %d 已关闭，仅放行敲门成功的来源（%v）\n", opts.SSHPort, opts.OpenFor)
+
+	switch opts.Mode {
+	case modeSPA:
+		return serveSPA(ctx, opts, nft)
+	case modeUDP:
+		return serveUDPSequence(ctx, opts, nft)
+	case modeTCP:
+		return serveTCPSequence(ctx, opts, nft)
+	default:
+		return fmt.Errorf("不支持的敲门模式: %s", opts.Mode)
+	}
+}
+
+func validateDaemonOptions(opts DaemonOptions) error {
+	if opts.SSHPort < 1 || opts.SSHPort > 65535 {
+		return fmt.Errorf("无效的 SSH 端口: %d", opts.SSHPort)
+	}
+	if opts.OpenFor <= 0 {
+		return fmt.Errorf("放行时长必须大于 0")
+	}
+	switch opts.Mode {
+	case modeSPA:
+		if len(opts.Secret) == 0 {
+			return fmt.Errorf("spa 模式需要 --secret 或环境变量 %s", envSecretKey)
+		}
+		if opts.SPAPort < 1 || opts.SPAPort > 65535 {
+			return fmt.Errorf("无效的 SPA 端口: %d", opts.SPAPort)
+		}
+	case modeUDP, modeTCP:
+		if len(opts.Sequence) < 2 {
+			return fmt.Errorf("敲门序列至少需要 2 个端口")
+		}
+		for _, p := range opts.Sequence {
+			if p < 1 || p > 65535 || p == opts.SSHPort {
+				return fmt.Errorf("无效的敲门端口: %d", p)
+			}
+		}
+		// 间隔不大于 0 时每一步都会立即过期，序列永远无法完成
+		if opts.StepTimeout <= 0 {
+			return fmt.Errorf("敲门间隔（--step-timeout）必须大于 0")
+		}
+	default:
+		return fmt.Errorf("不支持的敲门模式: %s（可选 spa|udp|tcp）", opts.Mode)
+	}
+	return nil
+}
+
+func openFor(g gate, ip net.IP, ttl time.Duration, reason string) {
+	if err := g.AllowSource(ip, ttl); err != nil {
+		log.Printf("放行 %s 失败: %v", ip, err)
+		return
+	}
+	fmt.Printf("[%s] 已放行 %s（%s，%v）\n", time.Now().Format("2006-01-02 15:04:05"), ip, reason, ttl)
+}
+
+func serveSPA(ctx context.Context, opts DaemonOptions, g gate) error {
+	conn, err := net.ListenPacket("udp", fmt.Sprintf(":%d", opts.SPAPort))
+	if err != nil {
+		return fmt.Errorf("监听 SPA 端口失败: %w", err)
+	}
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+	fmt.Printf(">>> 等待 SPA 数据包：udp/%d\n", opts.SPAPort)
+
+	maxSkew := opts.MaxSkew
+	if maxSkew <= 0 {
+		maxSkew = defaultSPAAge
+	}
+	nonces := newNonceCache(2 * maxSkew)
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("读取 SPA 数据包失败: %w", err)
+		}
+		src, ok := addr.(*net.UDPAddr)
+		if !ok {
+			continue
+		}
+
+		now := time.Now()
+		req, err := parseSPAPacket(opts.Secret, buf[:n], now, maxSkew)
+		if err == nil {
+			err = nonces.check(req.Nonce, now)
+		}
+		if err != nil {
+			debug.Printf("knock: 丢弃来自 %s 的数据包: %v", src.IP, err)
+			continue
+		}
+
+		target := src.IP
+		if req.AllowIP != nil {
+			target = req.AllowIP
+		}
+		openFor(g, target, opts.OpenFor, "spa")
+	}
+}
+
+func serveUDPSequence(ctx context.Context, opts DaemonOptions, g gate) error {
+	type knockHit struct {
+		ip   net.IP
+		port int
+	}
+
+	hits := make(chan knockHit, 64)
+	var conns []net.PacketConn
+	for _, port := range uniquePorts(opts.Sequence) {
+		conn, err := net.ListenPacket("udp", fmt.Sprintf(":%d", port))
+		if err != nil {
+			for _, c := range conns {
+				_ = c.Close()
+			}
+			return fmt.Errorf("监听敲门端口 %d 失败: %w", port, err)
+		}
+		conns = append(conns, conn)
+	}
+
+	var wg sync.WaitGroup
+	for _, conn := range conns {
+		port := conn.LocalAddr().(*net.UDPAddr).Port
+		wg.Add(1)
+		go func(conn net.PacketConn, port int) {
+			defer wg.Done()
+			buf := make([]byte, 64)
+			for {
+				_, addr, err := conn.ReadFrom(buf)
+				if err != nil {
+					return
+				}
+				if src, ok := addr.(*net.UDPAddr); ok {
+					select {
+					case hits <- knockHit{ip: src.IP, port: port}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}(conn, port)
+	}
+	fmt.Printf(">>> 等待 UDP 敲门序列：%v\n", opts.Sequence)
+
+	tracker := newSequenceTracker(opts.Sequence, opts.StepTimeout)
+	for {
+		select {
+		case <-ctx.Done():
+			for _, c := range conns {
+				_ = c.Close()
+			}
+			wg.Wait()
+			return nil
+		case hit := <-hits:
+			if tracker.hit(hit.ip.String(), hit.port, time.Now()) {
+				openFor(g, hit.ip, opts.OpenFor, "udp 序列")
+			}
+		}
+	}
+}
+
+// serveTCPSequence 读取 nftables 写入内核日志的 SYN 记录来识别 TCP 敲门序列
+func serveTCPSequence(ctx context.Context, opts DaemonOptions, g gate) error {
+	kmsg, err := os.Open("/dev/kmsg")
+	if err != nil {
+		return fmt.Errorf("打开 /dev/kmsg 失败: %w", err)
+	}
+	// 只处理启动后的新记录
+	if _, err := kmsg.Seek(0, io.SeekEnd); err != nil {
+		_ = kmsg.Close()
+		return fmt.Errorf("定位 /dev/kmsg 失败: %w", err)
+	}
+	go func() {
+		<-ctx.Done()
+		_ = kmsg.Close()
+	}()
+	fmt.Printf(">>> 等待 TCP 敲门序列：%v\n", opts.Sequence)
+
+	tracker := newSequenceTracker(opts.Sequence, opts.StepTimeout)
+	reader := bufio.NewReaderSize(kmsg, 8192)
+	failures := 0
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			// 环形缓冲区被覆盖时返回 EPIPE，继续读取即可
+			if errors.Is(err, syscall.EPIPE) {
+				debug.Printf("knock: /dev/kmsg 记录被覆盖，继续读取")
+				continue
+			}
+			// 其他错误持续出现时退避重试，超过次数后退出，避免空转
+			failures++
+			if failures >= kmsgMaxReadErrors {
+				return fmt.Errorf("读取 /dev/kmsg 失败: %w", err)
+			}
+			debug.Printf("knock: 读取 /dev/kmsg 失败（第 %d 次）: %v", failures, err)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(time.Duration(failures) * kmsgRetryDelay):
+			}
+			continue
+		}
+		failures = 0
+		ip, port, ok := parseKmsgKnock(line)
+		if !ok {
+			continue
+		}
+		if tracker.hit(ip.String(), port, time.Now()) {
+			openFor(g, ip, opts.OpenFor, "tcp 序列")
+		}
+	}
+}
+
+// parseKmsgKnock 解析 nftables log 规则写入的内核日志
+func parseKmsgKnock(line string) (net.IP, int, bool) {
+	if !strings.Contains(line, kmsgLogPrefix) {
+		return nil, 0, false
+	}
+	matches := kmsgKnockRe.FindStringSubmatch(line)
+	if len(matches) != 3 {
+		return nil, 0, false
+	}
+	ip := net.ParseIP(matches[1])
+	port, err := strconv.Atoi(matches[2])
+	if ip == nil || err != nil {
+		return nil, 0, false
+	}
+	return ip, port, true
+}
+
+func uniquePorts(ports []int) []int {
+	seen := make(map[int]struct{}, len(ports))
+	var result []int
+	for _, p := range ports {
+		if _, ok := seen[p]; ok {
+			continue
+		}
+		seen[p] = struct{}{}
+		result = append(result, p)
+	}
+	return result
+}
@@ -0,0 +1,72 @@
+package knock
+
+import (
+	"sync"
+	"time"
+)
+
+// sequenceTracker 跟踪每个来源 IP 的敲门进度
+type sequenceTracker struct {
+	mu       sync.Mutex
+	sequence []int
+	timeout  time.Duration // 相邻两次敲门的最大间隔
+	progress map[string]*knockProgress
+}
+
+type knockProgress struct {
+	next int // 下一个期望的端口序号
+	last time.Time
+}
+
+func newSequenceTracker(sequence []int, timeout time.Duration) *sequenceTracker {
+	return &sequenceTracker{
+		sequence: append([]int(nil), sequence...),
+		timeout:  timeout,
+		progress: make(map[string]*knockProgress),
+	}
+}
+
+// hit 记录一次敲门，返回 true 表示该来源已完成完整序列
+func (t *sequenceTracker) hit(ip string, port int, now time.Time) bool {
+	if len(t.sequence) == 0 {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.expire(now)
+
+	p, ok := t.progress[ip]
+	if !ok || now.Sub(p.last) > t.timeout {
+		p = &knockProgress{}
+		t.progress[ip] = p
+	}
+
+	switch {
+	case port == t.sequence[p.next]:
+		p.next++
+	case port == t.sequence[0]:
+		// 顺序错误但命中起始端口，重新开始计数
+		p.next = 1
+	default:
+		delete(t.progress, ip)
+		return false
+	}
+	p.last = now
+
+	if p.next == len(t.sequence) {
+		delete(t.progress, ip)
+		return true
+	}
+	return false
+}
+
+// expire 清理超时的进度，避免扫描器占用内存
+func (t *sequenceTracker) expire(now time.Time) {
+	for ip, p := range t.progress {
+		if now.Sub(p.last) > t.timeout {
+			delete(t.progress, ip)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package knock
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// SPA 数据包格式（共 92 字节）：
+//
+//	magic(4) | timestamp(8, 大端 unix 秒) | nonce(16) | ip(16, 全零表示使用来源地址) | hmac-sha256(32)
+//
+// HMAC 覆盖前 44 字节，密钥为双方共享的 secret。
+const (
+	spaMagic      = "SSK1"
+	spaNonceLen   = 16
+	spaIPLen      = 16
+	spaSignedLen  = len(spaMagic) + 8 + spaNonceLen + spaIPLen
+	spaPacketLen  = spaSignedLen + sha256.Size
+	defaultSPAAge = 30 * time.Second
+)
+
+var (
+	errSPALength    = errors.New("spa: invalid packet length")
+	errSPAMagic     = errors.New("spa: invalid magic")
+	errSPASignature = errors.New("spa: signature mismatch")
+	errSPAExpired   = errors.New("spa: timestamp outside allowed window")
+	errSPAReplay    = errors.New("spa: replayed packet")
+)
+
+// spaRequest 解析后的 SPA 请求
+type spaRequest struct {
+	Timestamp time.Time
+	Nonce     [spaNonceLen]byte
+	AllowIP   net.IP // 为空时放行数据包来源地址
+}
+
+// buildSPAPacket 构造签名后的 SPA 数据包
+func buildSPAPacket(secret []byte, now time.Time, allowIP net.IP) ([]byte, error) {
+	packet := make([]byte, spaPacketLen)
+	copy(packet, spaMagic)
+	binary.BigEndian.PutUint64(packet[4:12], uint64(now.Unix()))
+	if _, err := rand.Read(packet[12 : 12+spaNonceLen]); err != nil {
+		return nil, fmt.Errorf("生成随机数失败: %w", err)
+	}
+	if allowIP != nil {
+		ip16 := allowIP.To16()
+		if ip16 == nil {
+			return nil, fmt.Errorf("无效的放行地址: %s", allowIP)
+		}
+		copy(packet[12+spaNonceLen:spaSignedLen], ip16)
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(packet[:spaSignedLen])
+	copy(packet[spaSignedLen:], mac.Sum(nil))
+	return packet, nil
+}
+
+// parseSPAPacket 校验签名与时间窗口并解析 SPA 数据包
+func parseSPAPacket(secret, packet []byte, now time.Time, maxAge time.Duration) (*spaRequest, error) {
+	if len(packet) != spaPacketLen {
+		return nil, errSPALength
+	}
+	if string(packet[:4]) != spaMagic {
+		return nil, errSPAMagic
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(packet[:spaSignedLen])
+	if !hmac.Equal(mac.Sum(nil), packet[spaSignedLen:]) {
+		return nil, errSPASignature
+	}
+
+	ts := time.Unix(int64(binary.BigEndian.Uint64(packet[4:12])), 0)
+	if maxAge <= 0 {
+		maxAge = defaultSPAAge
+	}
+	if d := now.Sub(ts); d > maxAge || d < -maxAge {
+		return nil, errSPAExpired
+	}
+
+	req := &spaRequest{Timestamp: ts}
+	copy(req.Nonce[:], packet[12:12+spaNonceLen])
+	ipBytes := packet[12+spaNonceLen : spaSignedLen]
+	for _, b := range ipBytes {
+		if b != 0 {
+			req.AllowIP = net.IP(append([]byte(nil), ipBytes...))
+			break
+		}
+	}
+	return req, nil
+}
+
+// nonceCache 记录时间窗口内已使用的 nonce，防止重放
+type nonceCache struct {
+	mu   sync.Mutex
+	ttl  time.Duration
+	seen map[[spaNonceLen]byte]time.Time
+}
+
+func newNonceCache(ttl time.Duration) *nonceCache {
+	return &nonceCache{ttl: ttl, seen: make(map[[spaNonceLen]byte]time.Time)}
+}
+
+// check 返回 errSPAReplay 表示 nonce 已被使用
+func (c *nonceCache) check(nonce [spaNonceLen]byte, now time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, t := range c.seen {
+		if now.Sub(t) > c.ttl {
+			delete(c.seen, k)
+		}
+	}
+	if _, ok := c.seen[nonce]; ok {
+		return errSPAReplay
+	}
+	c.seen[nonce] = now
+	return nil
+}
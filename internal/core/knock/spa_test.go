@@ -0,0 +1,130 @@
+package knock
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestSPAPacketRoundTrip(t *testing.T) {
+	secret := []byte("s3cret")
+	now := time.Unix(1735689600, 0)
+
+	packet, err := buildSPAPacket(secret, now, nil)
+	if err != nil {
+		t.Fatalf("buildSPAPacket: %v", err)
+	}
+	req, err := parseSPAPacket(secret, packet, now.Add(5*time.Second), defaultSPAAge)
+	if err != nil {
+		t.Fatalf("parseSPAPacket: %v", err)
+	}
+	if req.AllowIP != nil {
+		t.Fatalf("expected empty allow ip, got %s", req.AllowIP)
+	}
+	if !req.Timestamp.Equal(now) {
+		t.Fatalf("timestamp = %s, want %s", req.Timestamp, now)
+	}
+
+	withIP, err := buildSPAPacket(secret, now, net.ParseIP("198.51.100.7"))
+	if err != nil {
+		t.Fatalf("buildSPAPacket with ip: %v", err)
+	}
+	req, err = parseSPAPacket(secret, withIP, now, defaultSPAAge)
+	if err != nil {
+		t.Fatalf("parseSPAPacket with ip: %v", err)
+	}
+	if !req.AllowIP.Equal(net.ParseIP("198.51.100.7")) {
+		t.Fatalf("allow ip = %s", req.AllowIP)
+	}
+}
+
+func TestSPAPacketRejects(t *testing.T) {
+	secret := []byte("s3cret")
+	now := time.Unix(1735689600, 0)
+	packet, _ := buildSPAPacket(secret, now, nil)
+
+	if _, err := parseSPAPacket([]byte("wrong"), packet, now, defaultSPAAge); !errors.Is(err, errSPASignature) {
+		t.Fatalf("expected signature error, got %v", err)
+	}
+	if _, err := parseSPAPacket(secret, packet, now.Add(time.Minute), defaultSPAAge); !errors.Is(err, errSPAExpired) {
+		t.Fatalf("expected expired error, got %v", err)
+	}
+	if _, err := parseSPAPacket(secret, packet[:10], now, defaultSPAAge); !errors.Is(err, errSPALength) {
+		t.Fatalf("expected length error, got %v", err)
+	}
+
+	tampered := append([]byte(nil), packet...)
+	tampered[20] ^= 0xff
+	if _, err := parseSPAPacket(secret, tampered, now, defaultSPAAge); !errors.Is(err, errSPASignature) {
+		t.Fatalf("expected signature error for tampered packet, got %v", err)
+	}
+}
+
+func TestNonceCacheRejectsReplay(t *testing.T) {
+	cache := newNonceCache(time.Minute)
+	now := time.Now()
+	var nonce [spaNonceLen]byte
+	nonce[0] = 1
+
+	if err := cache.check(nonce, now); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	if err := cache.check(nonce, now.Add(time.Second)); !errors.Is(err, errSPAReplay) {
+		t.Fatalf("expected replay error, got %v", err)
+	}
+	if err := cache.check(nonce, now.Add(2*time.Minute)); err != nil {
+		t.Fatalf("expected expired nonce to be accepted, got %v", err)
+	}
+}
+
+func TestSequenceTracker(t *testing.T) {
+	tracker := newSequenceTracker([]int{7000, 8000, 9000}, 5*time.Second)
+	now := time.Now()
+
+	if tracker.hit("1.1.1.1", 7000, now) || tracker.hit("1.1.1.1", 8000, now.Add(time.Second)) {
+		t.Fatalf("sequence should not complete early")
+	}
+	if !tracker.hit("1.1.1.1", 9000, now.Add(2*time.Second)) {
+		t.Fatalf("expected sequence to complete")
+	}
+
+	// 顺序错误后重置
+	tracker.hit("2.2.2.2", 7000, now)
+	tracker.hit("2.2.2.2", 9000, now)
+	if tracker.hit("2.2.2.2", 9000, now) {
+		t.Fatalf("out-of-order knock must not open")
+	}
+
+	// 超时后重置
+	tracker.hit("3.3.3.3", 7000, now)
+	tracker.hit("3.3.3.3", 8000, now.Add(10*time.Second))
+	if tracker.hit("3.3.3.3", 9000, now.Add(11*time.Second)) {
+		t.Fatalf("timed-out sequence must not open")
+	}
+}
+
+func TestParseKmsgKnock(t *testing.T) {
+	line := "4,1234,5678,-;sshield-knock IN=eth0 OUT= MAC=00 SRC=203.0.113.9 DST=192.0.2.1 LEN=60 PROTO=TCP SPT=51000 DPT=8000 WINDOW=64240 SYN\n"
+	ip, port, ok := parseKmsgKnock(line)
+	if !ok || ip.String() != "203.0.113.9" || port != 8000 {
+		t.Fatalf("parseKmsgKnock = %v %d %v", ip, port, ok)
+	}
+
+	if _, _, ok := parseKmsgKnock("6,1,2,-;eth0: link up\n"); ok {
+		t.Fatalf("unrelated kernel message must be ignored")
+	}
+}
+
+func TestValidateDaemonStepTimeout(t *testing.T) {
+	opts := DaemonOptions{Mode: modeTCP, SSHPort: 22, Sequence: []int{7000, 8000}, OpenFor: time.Minute, StepTimeout: 10 * time.Second}
+	if err := validateDaemonOptions(opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, timeout := range []time.Duration{0, -time.Second} {
+		opts.StepTimeout = timeout
+		if err := validateDaemonOptions(opts); err == nil {
+			t.Fatalf("expected error for step timeout %v", timeout)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"regexp"
+	"time"
+)
+
+// accountPattern 账户管理工具的日志格式
+type accountPattern struct {
+	re     *regexp.Regexp
+	tool   string // 产生该日志的工具
+	target int    // 受影响账户所在的分组序号
+}
+
+var accountPatterns = []accountPattern{
+	// "new user: name=bob, UID=1001, GID=1001, home=/home/bob, shell=/bin/bash, from=/dev/pts/0"
+	{regexp.MustCompile(`^new user: name=([^,]+),`), "useradd", 1},
+	// "new group: name=dev, GID=1002"
+	{regexp.MustCompile(`^new group: name=([^,]+),`), "groupadd", 1},
+	// "delete user 'bob'"
+	{regexp.MustCompile(`^delete user '([^']+)'`), "userdel", 1},
+	// "group 'dev' removed from /etc/group"
+	{regexp.MustCompile(`^group '([^']+)' removed from /etc/group`), "groupdel", 1},
+	// "add 'bob' to group 'sudo'" / "delete 'bob' from group 'sudo'"
+	{regexp.MustCompile(`^(?:add '([^']+)' to|delete '([^']+)' from) group '[^']+'`), "usermod", 0},
+	// "change user 'bob' shell from '/bin/sh' to '/bin/bash'" / "lock user 'bob' password"
+	{regexp.MustCompile(`^(?:change|lock|unlock) user (?:name )?'([^']+)'`), "usermod", 1},
+	// "pam_unix(passwd:chauthtok): password changed for bob"
+	{regexp.MustCompile(`^pam_unix\(passwd:chauthtok\): password changed for (\S+)`), "passwd", 1},
+	// "pam_unix(chpasswd:chauthtok): password changed for bob"
+	{regexp.MustCompile(`^pam_unix\(chpasswd:chauthtok\): password changed for (\S+)`), "chpasswd", 1},
+	// "user bob added by root to group sudo"
+	{regexp.MustCompile(`^user (\S+) (?:added|removed) by \S+ (?:to|from) group \S+`), "gpasswd", 1},
+}
+
+// parseAccountMessage 解析 useradd/usermod/passwd 等账户管理日志
+func parseAccountMessage(message, host string, ts time.Time) (*LoginEvent, bool) {
+	for _, p := range accountPatterns {
+		matches := p.re.FindStringSubmatch(message)
+		if matches == nil {
+			continue
+		}
+
+		user := ""
+		if p.target > 0 {
+			user = matches[p.target]
+		} else {
+			// 多个可选分组时取第一个非空值
+			for _, m := range matches[1:] {
+				if m != "" {
+					user = m
+					break
+				}
+			}
+		}
+
+		return &LoginEvent{
+			Type:      EventAccountChanged,
+			User:      user,
+			Method:    p.tool,
+			Timestamp: ts,
+			Hostname:  host,
+			Message:   message,
+			HostIP:    getHostIP(),
+		}, true
+	}
+	return nil, false
+}
@@ -0,0 +1,138 @@
+package notify
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// logArchive logrotate 轮转出的历史日志
+type logArchive struct {
+	path    string
+	modTime time.Time
+	seq     int64 // 数字后缀（auth.log.2）或日期后缀（secure-20250301）
+	numeric bool  // 数字后缀越大越旧，日期后缀越大越新
+}
+
+// findLogArchives 查找日志文件轮转出的历史文件，按时间从旧到新排序
+// 支持数字后缀（auth.log.1、auth.log.2.gz）与日期后缀（secure-20250301、auth.log-20250301.xz），压缩格式支持 gz/xz/zst
+func findLogArchives(path string) []logArchive {
+	base := filepath.Base(path)
+	re := regexp.MustCompile(`^` + regexp.QuoteMeta(base) + `([.-])(\d+)(?:\.(?:gz|xz|zst))?$`)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return nil
+	}
+	var archives []logArchive
+	for _, entry := range entries {
+		m := re.FindStringSubmatch(entry.Name())
+		if m == nil || !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		seq, _ := strconv.ParseInt(m[2], 10, 64)
+		archives = append(archives, logArchive{
+			path:    filepath.Join(filepath.Dir(path), entry.Name()),
+			modTime: info.ModTime(),
+			seq:     seq,
+			numeric: m[1] == "." && len(m[2]) < 8,
+		})
+	}
+
+	// 优先按修改时间（最后一次写入）排序，相同时按后缀推断先后
+	sort.SliceStable(archives, func(a, b int) bool {
+		x, y := archives[a], archives[b]
+		if !x.modTime.Equal(y.modTime) {
+			return x.modTime.Before(y.modTime)
+		}
+		if x.numeric && y.numeric {
+			return x.seq > y.seq
+		}
+		return x.seq < y.seq
+	})
+	return archives
+}
+
+// openLogArchive 打开历史日志，按扩展名透明解压
+func openLogArchive(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	var r io.Reader
+	switch {
+	case strings.HasSuffix(path, ".gz"):
+		r, err = gzip.NewReader(file)
+	case strings.HasSuffix(path, ".xz"):
+		r, err = xz.NewReader(bufio.NewReader(file))
+	case strings.HasSuffix(path, ".zst"):
+		var dec *zstd.Decoder
+		dec, err = zstd.NewReader(file, zstd.WithDecoderConcurrency(1))
+		if err == nil {
+			return &archiveReader{Reader: dec, close: func() error { dec.Close(); return file.Close() }}, nil
+		}
+	default:
+		return file, nil
+	}
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("解压 %s 失败: %w", path, err)
+	}
+	return &archiveReader{Reader: r, close: file.Close}, nil
+}
+
+type archiveReader struct {
+	io.Reader
+	close func() error
+}
+
+func (r *archiveReader) Close() error {
+	return r.close()
+}
+
+// readLogArchive 读取历史日志中的全部事件
+func readLogArchive(ctx context.Context, path string, handle func(*LoginEvent), loc *time.Location, match *sourceMatcher) error {
+	r, err := openLogArchive(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	reader := bufio.NewReader(r)
+	for {
+		select {
+		case <-ctx.Done():
+			return context.Canceled
+		default:
+		}
+
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			if event, ok := parseAuthLogLine(strings.TrimRight(line, "\r\n"), loc, match); ok {
+				handle(event)
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("读取 %s 失败: %w", path, err)
+		}
+	}
+}
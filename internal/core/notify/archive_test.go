@@ -0,0 +1,128 @@
+package notify
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+func TestFindLogArchivesOrder(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	files := map[string]time.Duration{
+		"secure":          0,
+		"secure-20250301": 72 * time.Hour,
+		"secure-20250308": 24 * time.Hour,
+		"secure.1":        48 * time.Hour,
+		"secure.bak":      time.Hour,
+		"secure-old.gz":   time.Hour,
+	}
+	for name, age := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var names []string
+	for _, a := range findLogArchives(filepath.Join(dir, "secure")) {
+		names = append(names, filepath.Base(a.path))
+	}
+	if strings.Join(names, ",") != "secure-20250301,secure.1,secure-20250308" {
+		t.Fatalf("unexpected archives: %v", names)
+	}
+}
+
+func TestSweepLogFileWithArchives(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "auth.log")
+	now := time.Now()
+
+	line := func(age time.Duration, port int) string {
+		return now.Add(-age).Format("Jan _2 15:04:05") +
+			fmt.Sprintf(" web sshd[1]: Accepted password for alice from 203.0.113.9 port %d ssh2\n", port)
+	}
+	compress := map[string]func(data string) []byte{
+		"": func(data string) []byte { return []byte(data) },
+		".gz": func(data string) []byte {
+			var buf bytes.Buffer
+			w := gzip.NewWriter(&buf)
+			w.Write([]byte(data))
+			w.Close()
+			return buf.Bytes()
+		},
+		".xz": func(data string) []byte {
+			var buf bytes.Buffer
+			w, _ := xz.NewWriter(&buf)
+			w.Write([]byte(data))
+			w.Close()
+			return buf.Bytes()
+		},
+		".zst": func(data string) []byte {
+			enc, _ := zstd.NewWriter(nil)
+			defer enc.Close()
+			return enc.EncodeAll([]byte(data), nil)
+		},
+	}
+	archives := []struct {
+		name string
+		ext  string
+		age  time.Duration // 最后一次写入
+		data string
+	}{
+		{"auth.log.4", ".zst", 30 * time.Hour, line(31*time.Hour, 1)},
+		{"auth.log.3", ".xz", 20 * time.Hour, line(21*time.Hour, 2) + line(20*time.Hour, 3)},
+		{"auth.log.2", ".gz", 10 * time.Hour, line(11*time.Hour, 4)},
+		{"auth.log.1", "", 5 * time.Hour, line(6*time.Hour, 5)},
+	}
+	for _, a := range archives {
+		p := filepath.Join(dir, a.name+a.ext)
+		if err := os.WriteFile(p, compress[a.ext](a.data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(p, now.Add(-a.age), now.Add(-a.age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	live := line(time.Hour, 6)
+	if err := os.WriteFile(path, []byte(live), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewCursorStore(filepath.Join(dir, "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := &SourceState{FileOffsets: make(map[string]int64)}
+	out := &collectHandler{}
+	if err := sweepLogFileWithFilter(context.Background(), store, state, path, newSourceMatcher(nil, nil, nil), 20*time.Hour+30*time.Minute, nil, out); err != nil {
+		t.Fatal(err)
+	}
+
+	var ports []string
+	for _, e := range out.events {
+		ports = append(ports, strconv.Itoa(e.Port))
+	}
+	if strings.Join(ports, ",") != "3,4,5,6" {
+		t.Fatalf("unexpected events: %v", ports)
+	}
+	if out.events[0].LogPath != filepath.Join(dir, "auth.log.3.xz") || out.events[3].LogPath != path {
+		t.Fatalf("unexpected log paths: %s, %s", out.events[0].LogPath, out.events[3].LogPath)
+	}
+	if state.FileOffsets[path] != int64(len(live)) {
+		t.Fatalf("unexpected live offset %d", state.FileOffsets[path])
+	}
+}
@@ -0,0 +1,418 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// 审计基线文件，保存各文件的内容哈希与内容
+	auditBaselineFileName = "audit_baseline.json"
+	// inotify 事件合并等待时间
+	auditDebounce = time.Second
+	// 兜底轮询间隔：inotify 可用时防止遗漏，不可用时作为主要检测方式
+	auditPollInterval        = 5 * time.Minute
+	auditPollIntervalNoWatch = 30 * time.Second
+	// 差异摘要中每类最多展示的行数
+	auditDiffMaxLines = 5
+)
+
+// auditTarget 需要审计的文件
+type auditTarget struct {
+	path      string
+	eventType string
+	owner     string // authorized_keys 所属用户
+}
+
+// fileBaseline 文件基线
+type fileBaseline struct {
+	Hash  string   `json:"hash"`
+	Lines []string `json:"lines"`
+	Owner string   `json:"owner,omitempty"`
+	Type  string   `json:"type"`
+}
+
+// fileAuditor 对比账户与 SSH 相关文件的内容哈希，发现变化时生成事件
+type fileAuditor struct {
+	baselinePath string
+	sshdConfig   string
+	sudoers      string
+	passwd       string
+
+	baseline    map[string]fileBaseline
+	initialized bool // 基线是否已存在，首次运行只记录不告警
+}
+
+func newFileAuditor(baselinePath string) (*fileAuditor, error) {
+	a := &fileAuditor{
+		baselinePath: baselinePath,
+		sshdConfig:   "/etc/ssh/sshd_config",
+		sudoers:      "/etc/sudoers",
+		passwd:       "/etc/passwd",
+		baseline:     make(map[string]fileBaseline),
+	}
+
+	data, err := os.ReadFile(baselinePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return a, nil
+		}
+		return nil, fmt.Errorf("failed to read audit baseline: %w", err)
+	}
+	if err := json.Unmarshal(data, &a.baseline); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal audit baseline: %w", err)
+	}
+	a.initialized = true
+	return a, nil
+}
+
+// targets 返回当前需要审计的文件（包括尚不存在的候选路径）
+func (a *fileAuditor) targets() []auditTarget {
+	var targets []auditTarget
+	seen := make(map[string]bool)
+	add := func(t auditTarget) {
+		// 多个用户可能共用同一家目录
+		if !seen[t.path] {
+			seen[t.path] = true
+			targets = append(targets, t)
+		}
+	}
+
+	add(auditTarget{path: a.sshdConfig, eventType: EventSSHDConfigChanged})
+	matches, _ := filepath.Glob(filepath.Join(filepath.Dir(a.sshdConfig), "sshd_config.d", "*.conf"))
+	for _, m := range matches {
+		add(auditTarget{path: m, eventType: EventSSHDConfigChanged})
+	}
+
+	add(auditTarget{path: a.sudoers, eventType: EventAccountChanged})
+	matches, _ = filepath.Glob(a.sudoers + ".d/*")
+	for _, m := range matches {
+		add(auditTarget{path: m, eventType: EventAccountChanged})
+	}
+
+	homes := readPasswdHomes(a.passwd)
+	users := make([]string, 0, len(homes))
+	for user := range homes {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+	for _, user := range users {
+		for _, name := range []string{"authorized_keys", "authorized_keys2"} {
+			add(auditTarget{
+				path:      filepath.Join(homes[user], ".ssh", name),
+				eventType: EventAuthorizedKeysChanged,
+				owner:     user,
+			})
+		}
+	}
+
+	// 基线中已有但不再是候选的文件（如 .d 目录中被删除的文件）也需要检查
+	for path, b := range a.baseline {
+		add(auditTarget{path: path, eventType: b.Type, owner: b.Owner})
+	}
+
+	sort.Slice(targets, func(i, j int) bool { return targets[i].path < targets[j].path })
+	return targets
+}
+
+// watchDirs 返回需要 inotify 监听的目录
+func (a *fileAuditor) watchDirs() []string {
+	dirs := map[string]bool{
+		filepath.Dir(a.sshdConfig):                                 true,
+		filepath.Join(filepath.Dir(a.sshdConfig), "sshd_config.d"): true,
+		filepath.Dir(a.sudoers):                                    true,
+		a.sudoers + ".d":                                           true,
+	}
+	for _, home := range readPasswdHomes(a.passwd) {
+		// 监听家目录以发现新建的 .ssh 目录
+		dirs[home] = true
+		dirs[filepath.Join(home, ".ssh")] = true
+	}
+
+	var list []string
+	for dir := range dirs {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			list = append(list, dir)
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+// scan 对比所有目标文件与基线，返回变化事件并更新基线
+func (a *fileAuditor) scan() []*LoginEvent {
+	var events []*LoginEvent
+	changed := false
+
+	for _, target := range a.targets() {
+		lines, hash, exists := readAuditFile(target.path)
+		old, known := a.baseline[target.path]
+
+		switch {
+		case !exists && !known:
+			continue
+		case !exists:
+			delete(a.baseline, target.path)
+			changed = true
+			events = append(events, a.newEvent(target, fmt.Sprintf("文件 %s 已删除", target.path), old.Lines, nil))
+			continue
+		case known && old.Hash == hash:
+			continue
+		}
+
+		a.baseline[target.path] = fileBaseline{Hash: hash, Lines: lines, Owner: target.owner, Type: target.eventType}
+		changed = true
+		if !a.initialized {
+			continue
+		}
+		if !known {
+			events = append(events, a.newEvent(target, fmt.Sprintf("文件 %s 已创建", target.path), nil, lines))
+			continue
+		}
+		events = append(events, a.newEvent(target, fmt.Sprintf("文件 %s 已修改", target.path), old.Lines, lines))
+	}
+
+	if !a.initialized || changed {
+		a.initialized = true
+		a.save()
+	}
+	return events
+}
+
+func (a *fileAuditor) newEvent(target auditTarget, title string, before, after []string) *LoginEvent {
+	hostname, _ := os.Hostname()
+	user := target.owner
+	if user == "" {
+		user = "-"
+	}
+	return &LoginEvent{
+		Type:      target.eventType,
+		User:      user,
+		Method:    "file",
+		Timestamp: time.Now(),
+		Hostname:  hostname,
+		LogPath:   target.path,
+		Message:   title + diffSummary(target.eventType, before, after),
+		HostIP:    getHostIP(),
+	}
+}
+
+func (a *fileAuditor) save() {
+	data, err := json.MarshalIndent(a.baseline, "", "  ")
+	if err != nil {
+		log.Printf("写入审计基线失败: %v", err)
+		return
+	}
+	if err := os.WriteFile(a.baselinePath, data, 0600); err != nil {
+		log.Printf("写入审计基线失败: %v", err)
+	}
+}
+
+// readAuditFile 读取文件内容与哈希，文件不存在时 exists 为 false
+func readAuditFile(path string) (lines []string, hash string, exists bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", false
+	}
+	sum := sha256.Sum256(data)
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, hex.EncodeToString(sum[:]), true
+}
+
+// readPasswdHomes 返回 /etc/passwd 中家目录存在的用户
+func readPasswdHomes(path string) map[string]string {
+	homes := make(map[string]string)
+	f, err := os.Open(path)
+	if err != nil {
+		return homes
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 7 || fields[5] == "" || fields[5] == "/" {
+			continue
+		}
+		if info, err := os.Stat(fields[5]); err == nil && info.IsDir() {
+			homes[fields[0]] = fields[5]
+		}
+	}
+	return homes
+}
+
+// diffSummary 生成新增/删除行的摘要，忽略空行与注释
+func diffSummary(eventType string, before, after []string) string {
+	added, removed := diffLines(before, after)
+	if len(added) == 0 && len(removed) == 0 {
+		return "（仅注释或空白变化）"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "：新增 %d 行，删除 %d 行", len(added), len(removed))
+	for _, group := range []struct {
+		prefix string
+		lines  []string
+	}{{"+", added}, {"-", removed}} {
+		for i, line := range group.lines {
+			if i == auditDiffMaxLines {
+				fmt.Fprintf(&b, "\n%s ...（另有 %d 行）", group.prefix, len(group.lines)-auditDiffMaxLines)
+				break
+			}
+			if eventType == EventAuthorizedKeysChanged {
+				line = describeAuthorizedKey(line)
+			}
+			fmt.Fprintf(&b, "\n%s %s", group.prefix, line)
+		}
+	}
+	return b.String()
+}
+
+// diffLines 按行内容比较（不考虑顺序），返回新增与删除的有效行
+func diffLines(before, after []string) (added, removed []string) {
+	count := make(map[string]int)
+	for _, line := range before {
+		if line = significantLine(line); line != "" {
+			count[line]++
+		}
+	}
+	for _, line := range after {
+		line = significantLine(line)
+		if line == "" {
+			continue
+		}
+		if count[line] > 0 {
+			count[line]--
+			continue
+		}
+		added = append(added, line)
+	}
+	for _, line := range before {
+		line = significantLine(line)
+		if line != "" && count[line] > 0 {
+			count[line]--
+			removed = append(removed, line)
+		}
+	}
+	return added, removed
+}
+
+func significantLine(line string) string {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "#") {
+		// sudoers 的 #include/#includedir 是有效指令
+		if !strings.HasPrefix(line, "#include") {
+			return ""
+		}
+	}
+	return line
+}
+
+// describeAuthorizedKey 将公钥行缩写为 "类型 指纹 注释"，避免在通知中输出完整公钥
+func describeAuthorizedKey(line string) string {
+	key, comment, options, _, err := ssh.ParseAuthorizedKey([]byte(line))
+	if err != nil {
+		if len(line) > 80 {
+			return line[:77] + "..."
+		}
+		return line
+	}
+	desc := key.Type() + " " + ssh.FingerprintSHA256(key)
+	if comment != "" {
+		desc += " " + comment
+	}
+	if len(options) > 0 {
+		desc += " [" + strings.Join(options, ",") + "]"
+	}
+	return desc
+}
+
+// runFileAudit 持续审计账户与 SSH 相关文件，变化时通过 handle 输出事件
+func runFileAudit(ctx context.Context, auditor *fileAuditor, handle func(*LoginEvent)) {
+	for _, event := range auditor.scan() {
+		handle(event)
+	}
+
+	pollInterval := auditPollInterval
+	var events <-chan string
+	watcher, err := newFileWatcher()
+	if err != nil {
+		log.Printf("文件监听不可用，改为每 %v 轮询: %v", auditPollIntervalNoWatch, err)
+		pollInterval = auditPollIntervalNoWatch
+	} else {
+		defer watcher.Close()
+		for _, dir := range auditor.watchDirs() {
+			if err := watcher.Add(dir); err != nil {
+				debugf("notify: %v", err)
+			}
+		}
+		events = watcher.Events()
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	debounce := time.NewTimer(auditDebounce)
+	debounce.Stop()
+
+	rescan := func() {
+		for _, event := range auditor.scan() {
+			handle(event)
+		}
+		if watcher != nil {
+			// 新用户或新建的 .ssh 目录需要补充监听
+			for _, dir := range auditor.watchDirs() {
+				_ = watcher.Add(dir)
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case path, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			debugf("notify: 文件变化 %s", path)
+			debounce.Reset(auditDebounce)
+		case <-debounce.C:
+			rescan()
+		case <-ticker.C:
+			rescan()
+		}
+	}
+}
+
+// startFileAudit 在后台启动文件审计，事件交给 proc 处理
+func startFileAudit(ctx context.Context, proc *eventProcessor) {
+	path, err := DefaultStatePath(auditBaselineFileName)
+	if err != nil {
+		log.Printf("文件审计不可用: %v", err)
+		return
+	}
+	auditor, err := newFileAuditor(path)
+	if err != nil {
+		log.Printf("文件审计不可用: %v", err)
+		return
+	}
+	fmt.Println(">>> 文件审计：authorized_keys、sudoers、sshd_config")
+	go runFileAudit(ctx, auditor, proc.handle)
+}
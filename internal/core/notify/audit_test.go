@@ -0,0 +1,131 @@
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testAuthorizedKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIIt0ocxUOs3HexvI+ysZkawQuXwevX7wbl33qVeTPEiw attacker@evil"
+
+func TestParseAccountMessage(t *testing.T) {
+	ts := time.Now()
+	cases := []struct {
+		message string
+		user    string
+		tool    string
+	}{
+		{"new user: name=bob, UID=1001, GID=1001, home=/home/bob, shell=/bin/bash, from=/dev/pts/0", "bob", "useradd"},
+		{"new group: name=dev, GID=1002", "dev", "groupadd"},
+		{"delete user 'bob'", "bob", "userdel"},
+		{"add 'bob' to group 'sudo'", "bob", "usermod"},
+		{"delete 'bob' from group 'sudo'", "bob", "usermod"},
+		{"change user 'bob' shell from '/bin/sh' to '/bin/bash'", "bob", "usermod"},
+		{"lock user 'bob' password", "bob", "usermod"},
+		{"pam_unix(passwd:chauthtok): password changed for bob", "bob", "passwd"},
+		{"pam_unix(chpasswd:chauthtok): password changed for bob", "bob", "chpasswd"},
+		{"user bob added by root to group sudo", "bob", "gpasswd"},
+	}
+	for _, tc := range cases {
+		event, ok := parseJournalMessage(tc.message, "host", ts)
+		if !ok {
+			t.Fatalf("expected event for %q", tc.message)
+		}
+		if event.Type != EventAccountChanged || event.User != tc.user || event.Method != tc.tool {
+			t.Fatalf("unexpected event for %q: %+v", tc.message, event)
+		}
+	}
+
+	event, ok := parseAuthLogLine("Mar  3 10:00:00 web useradd[812]: new user: name=bob, UID=1001, GID=1001, home=/home/bob, shell=/bin/bash", nil, nil)
+	if !ok || event.Type != EventAccountChanged || event.PID != 812 {
+		t.Fatalf("unexpected auth.log event: %+v ok=%v", event, ok)
+	}
+}
+
+func TestFileAuditorScan(t *testing.T) {
+	root := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	home := filepath.Join(root, "home", "alice")
+	if err := os.MkdirAll(home, 0755); err != nil {
+		t.Fatal(err)
+	}
+	write(filepath.Join(root, "passwd"), "alice:x:1000:1000::"+home+":/bin/bash\nnobody:x:65534:65534::/nonexistent:/usr/sbin/nologin\n")
+	write(filepath.Join(root, "ssh", "sshd_config"), "PermitRootLogin no\n")
+	write(filepath.Join(root, "sudoers"), "root ALL=(ALL) ALL\n")
+
+	newAuditor := func() *fileAuditor {
+		a, err := newFileAuditor(filepath.Join(root, "baseline.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.sshdConfig = filepath.Join(root, "ssh", "sshd_config")
+		a.sudoers = filepath.Join(root, "sudoers")
+		a.passwd = filepath.Join(root, "passwd")
+		return a
+	}
+
+	a := newAuditor()
+	if events := a.scan(); len(events) != 0 {
+		t.Fatalf("first scan should only build baseline, got %d events", len(events))
+	}
+
+	// 基线持久化后重新加载，再修改文件
+	a = newAuditor()
+	write(filepath.Join(root, "ssh", "sshd_config"), "# hardened\nPermitRootLogin yes\n")
+	write(filepath.Join(home, ".ssh", "authorized_keys"), testAuthorizedKey+"\n")
+	write(filepath.Join(root, "sudoers.d", "backdoor"), "alice ALL=(ALL) NOPASSWD: ALL\n")
+
+	events := a.scan()
+	byType := make(map[string]*LoginEvent)
+	for _, e := range events {
+		byType[e.Type] = e
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d: %+v", len(events), events)
+	}
+
+	cfg := byType[EventSSHDConfigChanged]
+	if cfg == nil || !strings.Contains(cfg.Message, "+ PermitRootLogin yes") || !strings.Contains(cfg.Message, "- PermitRootLogin no") {
+		t.Fatalf("unexpected sshd_config event: %+v", cfg)
+	}
+	keys := byType[EventAuthorizedKeysChanged]
+	if keys == nil || keys.User != "alice" || !strings.Contains(keys.Message, "SHA256:bwHXFcZxGBxsxMHvtMNwpKiLQWM7i3yx2aNO4SM1PiU attacker@evil") {
+		t.Fatalf("unexpected authorized_keys event: %+v", keys)
+	}
+	if strings.Contains(keys.Message, "AAAAC3Nza") {
+		t.Fatalf("full public key should not be included: %s", keys.Message)
+	}
+	if sudo := byType[EventAccountChanged]; sudo == nil || !strings.Contains(sudo.Message, "已创建") {
+		t.Fatalf("unexpected sudoers event: %+v", sudo)
+	}
+
+	// 未变化时不再产生事件，删除文件产生事件
+	if events := a.scan(); len(events) != 0 {
+		t.Fatalf("expected no events, got %d", len(events))
+	}
+	if err := os.Remove(filepath.Join(root, "sudoers.d", "backdoor")); err != nil {
+		t.Fatal(err)
+	}
+	events = a.scan()
+	if len(events) != 1 || !strings.Contains(events[0].Message, "已删除") {
+		t.Fatalf("expected deletion event, got %+v", events)
+	}
+}
+
+func TestDiffSummaryIgnoresComments(t *testing.T) {
+	got := diffSummary(EventSSHDConfigChanged, []string{"Port 22"}, []string{"# comment", "", "Port 22"})
+	if got != "（仅注释或空白变化）" {
+		t.Fatalf("unexpected summary %q", got)
+	}
+}
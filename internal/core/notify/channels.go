@@ -0,0 +1,292 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	// 内置渠道的 HTTP 请求超时，与 curl 渠道一致
+	channelHTTPTimeout = 10 * time.Second
+	// 遇到限流（HTTP 429）时的最大重试次数与单次最长等待时间，超过时放弃本次发送
+	rateLimitMaxRetries = 3
+	rateLimitMaxWait    = 10 * time.Second
+)
+
+// channelSleep 限流退避时的等待函数，测试中替换
+var channelSleep = time.Sleep
+
+func newChannelHTTPClient() *http.Client {
+	return &http.Client{Timeout: channelHTTPTimeout}
+}
+
+// httpStatusError 非 2xx 响应
+type httpStatusError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // 服务端要求的重试等待时间（429/503），未给出时为 0
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("请求失败，状态码 %d: %s", e.StatusCode, e.Body)
+}
+
+// postJSON 以 JSON 发送 POST 请求并返回响应内容，非 2xx 响应返回 *httpStatusError
+func postJSON(client *http.Client, endpoint string, payload any, header http.Header) ([]byte, error) {
+	return sendJSON(client, http.MethodPost, endpoint, payload, header)
+}
+
+// sendJSON 与 postJSON 相同，可指定请求方法；payload 为 json.RawMessage 时原样发送
+func sendJSON(client *http.Client, method, endpoint string, payload any, header http.Header) ([]byte, error) {
+	body, ok := payload.(json.RawMessage)
+	if !ok {
+		var err error
+		if body, err = json.Marshal(payload); err != nil {
+			return nil, fmt.Errorf("编码请求失败: %w", err)
+		}
+	}
+	req, err := http.NewRequest(method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return data, &httpStatusError{
+			StatusCode: resp.StatusCode,
+			Body:       strings.TrimSpace(string(data)),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), data),
+		}
+	}
+	return data, nil
+}
+
+// postJSONWithBackoff 与 postJSON 相同，遇到限流时按 Retry-After 等待后重试
+func postJSONWithBackoff(client *http.Client, endpoint string, payload any, header http.Header) ([]byte, error) {
+	return sendJSONWithBackoff(client, http.MethodPost, endpoint, payload, header)
+}
+
+// sendJSONWithBackoff 与 sendJSON 相同，遇到限流时按 Retry-After 等待后重试
+func sendJSONWithBackoff(client *http.Client, method, endpoint string, payload any, header http.Header) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		data, err := sendJSON(client, method, endpoint, payload, header)
+		var statusErr *httpStatusError
+		if err == nil || !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusTooManyRequests || attempt >= rateLimitMaxRetries {
+			return data, err
+		}
+		wait := statusErr.RetryAfter
+		if wait <= 0 {
+			wait = time.Second << attempt
+		}
+		if wait > rateLimitMaxWait {
+			return data, fmt.Errorf("%w（限流等待 %v 超过上限）", err, wait)
+		}
+		// 只记录主机名：Telegram 等渠道的 URL 中包含 token
+		debugf("notify: %s 限流，%v 后重试", requestHost(endpoint), wait)
+		channelSleep(wait)
+	}
+}
+
+func requestHost(endpoint string) string {
+	if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return "渠道"
+}
+
+// parseRetryAfter 解析 Retry-After 头（秒数或 HTTP 日期），Discord 还会在响应中给出 retry_after（秒，可为小数），
+// Telegram 给出 parameters.retry_after（秒），Matrix 给出 retry_after_ms（毫秒）
+func parseRetryAfter(header string, body []byte) time.Duration {
+	if header = strings.TrimSpace(header); header != "" {
+		if secs, err := strconv.ParseFloat(header, 64); err == nil && secs >= 0 {
+			return time.Duration(secs * float64(time.Second))
+		}
+		if t, err := http.ParseTime(header); err == nil {
+			if d := time.Until(t); d > 0 {
+				return d
+			}
+			return 0
+		}
+	}
+	var resp struct {
+		RetryAfter   float64 `json:"retry_after"`
+		RetryAfterMs int64   `json:"retry_after_ms"`
+		Parameters   struct {
+			RetryAfter float64 `json:"retry_after"`
+		} `json:"parameters"`
+	}
+	if json.Unmarshal(body, &resp) != nil {
+		return 0
+	}
+	if resp.RetryAfter > 0 {
+		return time.Duration(resp.RetryAfter * float64(time.Second))
+	}
+	if resp.Parameters.RetryAfter > 0 {
+		return time.Duration(resp.Parameters.RetryAfter * float64(time.Second))
+	}
+	return time.Duration(resp.RetryAfterMs) * time.Millisecond
+}
+
+// validateHTTPURL 检查渠道配置中的 URL
+func validateHTTPURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme must be http or https")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+	return nil
+}
+
+// eventField 内置渠道消息中的一行（标签与值）
+type eventField struct {
+	Label string
+	Value string
+}
+
+// eventFields 返回内置渠道展示的事件字段，顺序与 curl 默认消息一致，可选字段为空时省略
+func eventFields(event LoginEvent) []eventField {
+	port := "-"
+	if event.Port > 0 {
+		port = strconv.Itoa(event.Port)
+	}
+
+	fields := []eventField{
+		{"事件类型", event.displayType()},
+		{"服务器", orDash(event.Hostname)},
+		{"用户", orDash(event.User)},
+		{"来源IP", orDash(event.IP)},
+		{"来源端口", port},
+		{"认证方式", orDash(event.Method)},
+		{"位置", orDash(event.Location)},
+		{"时间", formatShanghaiRFC3339(event.Timestamp)},
+		{"日志路径", orDash(event.LogPath)},
+	}
+	return append(fields, extraEventFields(event)...)
+}
+
+// extraEventFields 返回提权目标、会话时长、自动处置、级别等可选字段，与 formatExtraLines 一致
+func extraEventFields(event LoginEvent) []eventField {
+	var fields []eventField
+	if event.TargetUser != "" {
+		fields = append(fields, eventField{"目标用户", event.TargetUser})
+	}
+	if event.Command != "" {
+		fields = append(fields, eventField{"命令", event.Command})
+	}
+	if event.Duration > 0 {
+		fields = append(fields, eventField{"时长", formatSessionDuration(event.Duration)})
+	}
+	if event.Action != "" {
+		fields = append(fields, eventField{"处置", event.Action})
+	}
+	if event.Severity != "" {
+		fields = append(fields, eventField{"级别", event.Severity})
+	}
+	return fields
+}
+
+// channelTestEvent 配置渠道时发送的测试事件
+func channelTestEvent() LoginEvent {
+	return LoginEvent{
+		Type:      "test",
+		User:      "test_user",
+		IP:        "127.0.0.1",
+		Timestamp: time.Now(),
+		Hostname:  "test_host",
+		Location:  "Test Location",
+		LogPath:   "-",
+	}
+}
+
+// renderJSONTemplate 渲染自定义消息模板，模板中可用 {{json .User}} 输出转义后的 JSON 字符串，结果必须是合法 JSON
+func renderJSONTemplate(text string, event LoginEvent) (json.RawMessage, error) {
+	tmpl, err := template.New("").Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("解析消息模板失败: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, eventTemplateData(event)); err != nil {
+		return nil, fmt.Errorf("渲染消息模板失败: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("消息模板渲染结果不是合法 JSON: %s", buf.String())
+	}
+	return json.RawMessage(buf.Bytes()), nil
+}
+
+// eventRecord 事件的 JSON 表示，用于文件等机器可读的渠道，字段名与 SIEM 常用的 snake_case 一致，时间为 UTC
+type eventRecord struct {
+	Time            string  `json:"time"`
+	Type            string  `json:"type"`
+	Subtype         string  `json:"subtype,omitempty"`
+	Severity        string  `json:"severity,omitempty"`
+	Hostname        string  `json:"hostname,omitempty"`
+	HostIP          string  `json:"host_ip,omitempty"`
+	User            string  `json:"user,omitempty"`
+	IP              string  `json:"ip,omitempty"`
+	Port            int     `json:"port,omitempty"`
+	Method          string  `json:"method,omitempty"`
+	Location        string  `json:"location,omitempty"`
+	TargetUser      string  `json:"target_user,omitempty"`
+	Command         string  `json:"command,omitempty"`
+	PID             int     `json:"pid,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds,omitempty"`
+	Action          string  `json:"action,omitempty"`
+	LogPath         string  `json:"log_path,omitempty"`
+	Message         string  `json:"message,omitempty"`
+}
+
+func newEventRecord(event LoginEvent) eventRecord {
+	return eventRecord{
+		Time:            event.Timestamp.UTC().Format(time.RFC3339Nano),
+		Type:            event.Type,
+		Subtype:         event.Subtype,
+		Severity:        event.Severity,
+		Hostname:        event.Hostname,
+		HostIP:          event.HostIP,
+		User:            event.User,
+		IP:              event.IP,
+		Port:            event.Port,
+		Method:          event.Method,
+		Location:        event.Location,
+		TargetUser:      event.TargetUser,
+		Command:         event.Command,
+		PID:             event.PID,
+		DurationSeconds: event.Duration.Seconds(),
+		Action:          event.Action,
+		LogPath:         event.LogPath,
+		Message:         event.Message,
+	}
+}
@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// recordedRequest 测试服务器收到的一次请求
+type recordedRequest struct {
+	method string
+	path   string // 转义后的路径
+	query  url.Values
+	header http.Header
+	body   map[string]any // 按 JSON 解码的请求体
+}
+
+// newRecordingServer 启动记录全部请求的测试服务器，handler 按第几次请求（从 1 开始）写入响应
+func newRecordingServer(t *testing.T, handler func(w http.ResponseWriter, attempt int)) (*httptest.Server, *[]recordedRequest) {
+	t.Helper()
+	var requests []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := recordedRequest{
+			method: r.Method,
+			path:   r.URL.EscapedPath(),
+			query:  r.URL.Query(),
+			header: r.Header,
+		}
+		data, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &req.body); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		requests = append(requests, req)
+		handler(w, len(requests))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// respondWith 每次请求都返回相同的状态码与响应体
+func respondWith(status int, body string) func(http.ResponseWriter, int) {
+	return func(w http.ResponseWriter, _ int) {
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}
+}
+
+// stubChannelSleep 替换重试等待，返回记录的等待时长
+func stubChannelSleep(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var waits []time.Duration
+	orig := channelSleep
+	channelSleep = func(d time.Duration) { waits = append(waits, d) }
+	t.Cleanup(func() { channelSleep = orig })
+	return &waits
+}
+
+// testLoginEvent 渠道测试共用的登录成功事件
+func testLoginEvent() LoginEvent {
+	return LoginEvent{
+		Type:      EventLoginSuccess,
+		Severity:  SeverityMedium,
+		User:      "root",
+		IP:        "203.0.113.9",
+		Method:    "publickey",
+		Timestamp: time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC),
+		Hostname:  "web-1",
+		HostIP:    "10.0.0.1",
+		Message:   "Accepted publickey for root",
+		LogPath:   "/var/log/auth.log",
+	}
+}
@@ -0,0 +1,237 @@
+package notify
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 聊天平台 incoming webhook 渠道类型
+const (
+	chatSlack   = "slack"
+	chatDiscord = "discord"
+	chatTeams   = "teams"
+)
+
+// ChatNotifier 通过 Slack、Discord、Microsoft Teams 的 incoming webhook 发送通知
+type ChatNotifier struct {
+	kind       string
+	webhook    string
+	template   string
+	httpClient *http.Client
+}
+
+// NewChatNotifier 创建聊天平台通知器，kind 为 slack/discord/teams
+func NewChatNotifier(kind string, cfg *ChatWebhookConfig) *ChatNotifier {
+	return &ChatNotifier{
+		kind:       kind,
+		webhook:    cfg.Webhook,
+		template:   cfg.Template,
+		httpClient: newChannelHTTPClient(),
+	}
+}
+
+// Send 使用默认布局或自定义模板生成消息，遇到限流时退避重试
+func (c *ChatNotifier) Send(event LoginEvent) error {
+	var payload any
+	if c.template != "" {
+		raw, err := renderJSONTemplate(c.template, event)
+		if err != nil {
+			return err
+		}
+		payload = raw
+	} else {
+		switch c.kind {
+		case chatSlack:
+			payload = slackPayload(event)
+		case chatDiscord:
+			payload = discordPayload(event)
+		case chatTeams:
+			payload = teamsPayload(event)
+		default:
+			return fmt.Errorf("未知聊天平台类型: %s", c.kind)
+		}
+	}
+
+	if _, err := postJSONWithBackoff(c.httpClient, c.webhook, payload, nil); err != nil {
+		return fmt.Errorf("发送 %s 消息失败: %w", c.kind, err)
+	}
+	return nil
+}
+
+// Test 测试 webhook 配置
+func (c *ChatNotifier) Test() error {
+	if err := c.Send(channelTestEvent()); err != nil {
+		return fmt.Errorf("%s 测试失败: %w", c.kind, err)
+	}
+	return nil
+}
+
+// chatFields 默认布局展示的字段：用户、IP、位置、认证方式、主机等
+func chatFields(event LoginEvent) []eventField {
+	f := robotFields(event)
+	fields := []eventField{
+		{"用户", f.user},
+		{"来源IP", f.ip},
+		{"位置", f.location},
+		{"认证方式", f.method},
+		{"服务器", f.host},
+		{"时间", f.time},
+	}
+	return append(fields, extraEventFields(event)...)
+}
+
+// slackPayload Block Kit 消息，text 作为通知栏的回退内容
+func slackPayload(event LoginEvent) map[string]any {
+	title := robotTitle(event)
+	var blocks []any
+	blocks = append(blocks, map[string]any{
+		"type": "header",
+		"text": map[string]any{"type": "plain_text", "text": title, "emoji": true},
+	})
+
+	// section 最多 10 个字段
+	var fields []any
+	for _, f := range chatFields(event) {
+		fields = append(fields, map[string]string{"type": "mrkdwn", "text": "*" + f.Label + "*\n" + escapeSlack(f.Value)})
+		if len(fields) == 10 {
+			blocks = append(blocks, map[string]any{"type": "section", "fields": fields})
+			fields = nil
+		}
+	}
+	if len(fields) > 0 {
+		blocks = append(blocks, map[string]any{"type": "section", "fields": fields})
+	}
+
+	if event.Message != "" {
+		blocks = append(blocks, map[string]any{
+			"type": "section",
+			"text": map[string]string{"type": "mrkdwn", "text": "```" + escapeSlack(truncateRunes(event.Message, 2900)) + "```"},
+		})
+	}
+	blocks = append(blocks, map[string]any{
+		"type":     "context",
+		"elements": []any{map[string]string{"type": "mrkdwn", "text": "日志: " + escapeSlack(orDash(event.LogPath)) + " | Powered by SSHield"}},
+	})
+	return map[string]any{"text": title, "blocks": blocks}
+}
+
+// escapeSlack 转义 mrkdwn 中的控制字符
+func escapeSlack(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// Discord embed 颜色
+const (
+	discordColorGreen  = 0x2ECC71
+	discordColorRed    = 0xE74C3C
+	discordColorOrange = 0xE67E22
+	discordColorPurple = 0x9B59B6
+	discordColorYellow = 0xF1C40F
+	discordColorGrey   = 0x95A5A6
+	discordColorBlue   = 0x3498DB
+)
+
+// discordColor 按事件类型选择 embed 颜色
+func discordColor(event LoginEvent) int {
+	switch event.Type {
+	case EventLoginSuccess:
+		return discordColorGreen
+	case EventScanDetected, EventHoneypotAttempt:
+		return discordColorOrange
+	case EventSudoCommand, EventSuSession:
+		return discordColorPurple
+	case EventAccountChanged, EventAuthorizedKeysChanged, EventSSHDConfigChanged:
+		return discordColorYellow
+	case EventSessionClosed:
+		return discordColorGrey
+	}
+	if isFailureEvent(event.Type) {
+		return discordColorRed
+	}
+	return discordColorBlue
+}
+
+// discordPayload embed 消息，禁止解析 @everyone 等提及
+func discordPayload(event LoginEvent) map[string]any {
+	var fields []any
+	for _, f := range chatFields(event) {
+		fields = append(fields, map[string]any{"name": f.Label, "value": truncateRunes(f.Value, 1024), "inline": true})
+	}
+	embed := map[string]any{
+		"title":     robotTitle(event),
+		"color":     discordColor(event),
+		"fields":    fields,
+		"footer":    map[string]string{"text": "日志: " + orDash(event.LogPath) + " | Powered by SSHield"},
+		"timestamp": event.Timestamp.UTC().Format(time.RFC3339),
+	}
+	if event.Message != "" {
+		embed["description"] = "```\n" + strings.ReplaceAll(truncateRunes(event.Message, 4000), "```", "'''") + "\n```"
+	}
+	return map[string]any{
+		"username":         "SSHield",
+		"embeds":           []any{embed},
+		"allowed_mentions": map[string]any{"parse": []string{}},
+	}
+}
+
+// teamsPayload Adaptive Card 消息，兼容 Teams incoming webhook 与 Workflows
+func teamsPayload(event LoginEvent) map[string]any {
+	color := "Attention"
+	switch {
+	case event.Type == EventLoginSuccess:
+		color = "Warning"
+	case event.Severity == SeverityInfo || event.Severity == SeverityLow:
+		color = "Default"
+	}
+
+	var facts []any
+	for _, f := range chatFields(event) {
+		facts = append(facts, map[string]string{"title": f.Label, "value": f.Value})
+	}
+	body := []any{
+		map[string]any{"type": "TextBlock", "text": robotTitle(event), "weight": "Bolder", "size": "Medium", "color": color, "wrap": true},
+		map[string]any{"type": "FactSet", "facts": facts},
+	}
+	if event.Message != "" {
+		body = append(body, map[string]any{"type": "TextBlock", "text": event.Message, "wrap": true, "fontType": "Monospace", "isSubtle": true})
+	}
+	body = append(body, map[string]any{"type": "TextBlock", "text": "日志: " + orDash(event.LogPath) + " | Powered by SSHield", "size": "Small", "isSubtle": true, "wrap": true})
+
+	return map[string]any{
+		"type": "message",
+		"attachments": []any{map[string]any{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]any{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body":    body,
+			},
+		}},
+	}
+}
+
+// truncateRunes 按字符截断，超出时以省略号结尾
+func truncateRunes(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max-1]) + "…"
+}
+
+// ChatWebhookInput 配置聊天平台渠道的参数
+type ChatWebhookInput struct {
+	Name     string
+	Webhook  string
+	Template string
+}
+
+// configureChatWebhook 测试并保存聊天平台渠道
+func configureChatWebhook(kind string, input ChatWebhookInput) error {
+	channel := ChannelConfig{Name: input.Name, Enabled: true, Type: kind}
+	channel.setChatWebhook(kind, &ChatWebhookConfig{Webhook: input.Webhook, Template: input.Template})
+	return configureChannel(channel)
+}
@@ -0,0 +1,146 @@
+package notify
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+var okHandler = respondWith(http.StatusNoContent, "")
+
+func TestChatNotifierSlack(t *testing.T) {
+	server, reqs := newRecordingServer(t, okHandler)
+	event := testLoginEvent()
+	event.Message = "<script>"
+	if err := NewChatNotifier(chatSlack, &ChatWebhookConfig{Webhook: server.URL}).Send(event); err != nil {
+		t.Fatal(err)
+	}
+
+	body := (*reqs)[0].body
+	if !strings.Contains(body["text"].(string), "login_success Alert @10.0.0.1") {
+		t.Fatalf("unexpected fallback text: %v", body["text"])
+	}
+	blocks := body["blocks"].([]any)
+	if blocks[0].(map[string]any)["type"] != "header" {
+		t.Fatalf("expected header block, got %v", blocks[0])
+	}
+	fields := blocks[1].(map[string]any)["fields"].([]any)
+	if fields[0].(map[string]any)["text"] != "*用户*\nroot" {
+		t.Fatalf("unexpected first field: %v", fields[0])
+	}
+	message := blocks[2].(map[string]any)["text"].(map[string]any)["text"].(string)
+	if message != "```&lt;script&gt;```" {
+		t.Fatalf("message not escaped: %s", message)
+	}
+}
+
+func TestChatNotifierDiscord(t *testing.T) {
+	server, reqs := newRecordingServer(t, okHandler)
+	event := testLoginEvent()
+	event.Type = EventLoginFailed
+	if err := NewChatNotifier(chatDiscord, &ChatWebhookConfig{Webhook: server.URL}).Send(event); err != nil {
+		t.Fatal(err)
+	}
+
+	body := (*reqs)[0].body
+	embed := body["embeds"].([]any)[0].(map[string]any)
+	if int(embed["color"].(float64)) != discordColorRed {
+		t.Fatalf("unexpected color: %v", embed["color"])
+	}
+	if embed["timestamp"] != "2025-03-03T10:00:00Z" {
+		t.Fatalf("unexpected timestamp: %v", embed["timestamp"])
+	}
+	if parse := body["allowed_mentions"].(map[string]any)["parse"].([]any); len(parse) != 0 {
+		t.Fatalf("mentions should be disabled: %v", parse)
+	}
+}
+
+func TestChatNotifierTeams(t *testing.T) {
+	server, reqs := newRecordingServer(t, okHandler)
+	if err := NewChatNotifier(chatTeams, &ChatWebhookConfig{Webhook: server.URL}).Send(testLoginEvent()); err != nil {
+		t.Fatal(err)
+	}
+
+	attachment := (*reqs)[0].body["attachments"].([]any)[0].(map[string]any)
+	if attachment["contentType"] != "application/vnd.microsoft.card.adaptive" {
+		t.Fatalf("unexpected content type: %v", attachment["contentType"])
+	}
+	card := attachment["content"].(map[string]any)
+	facts := card["body"].([]any)[1].(map[string]any)["facts"].([]any)
+	if facts[1].(map[string]any)["value"] != "203.0.113.9" {
+		t.Fatalf("unexpected facts: %v", facts)
+	}
+}
+
+func TestChatNotifierTemplate(t *testing.T) {
+	server, reqs := newRecordingServer(t, okHandler)
+	cfg := &ChatWebhookConfig{Webhook: server.URL, Template: `{"content":{{json .Message}},"user":{{json .User}}}`}
+	event := testLoginEvent()
+	event.Message = `say "hi"`
+	if err := NewChatNotifier(chatDiscord, cfg).Send(event); err != nil {
+		t.Fatal(err)
+	}
+	if body := (*reqs)[0].body; body["content"] != `say "hi"` || body["user"] != "root" {
+		t.Fatalf("unexpected templated body: %v", body)
+	}
+
+	bad := &ChannelConfig{Type: "slack", Slack: &ChatWebhookConfig{Webhook: server.URL, Template: `{"text":{{.User}}}`}}
+	if err := ValidateChannelConfig(bad); err == nil {
+		t.Fatal("expected invalid JSON template to fail validation")
+	}
+}
+
+func TestChatNotifierRateLimit(t *testing.T) {
+	waits := stubChannelSleep(t)
+
+	server, reqs := newRecordingServer(t, func(w http.ResponseWriter, attempt int) {
+		switch attempt {
+		case 1:
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"message":"You are being rate limited.","retry_after":0.5}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	if err := NewChatNotifier(chatDiscord, &ChatWebhookConfig{Webhook: server.URL}).Send(testLoginEvent()); err != nil {
+		t.Fatal(err)
+	}
+	if len(*reqs) != 3 || len(*waits) != 2 || (*waits)[0] != 2*time.Second || (*waits)[1] != 500*time.Millisecond {
+		t.Fatalf("unexpected retries: %d requests, waits %v", len(*reqs), *waits)
+	}
+}
+
+func TestChatNotifierRateLimitTooLong(t *testing.T) {
+	orig := channelSleep
+	channelSleep = func(time.Duration) { t.Fatal("should not wait beyond the limit") }
+	t.Cleanup(func() { channelSleep = orig })
+
+	server, _ := newRecordingServer(t, func(w http.ResponseWriter, _ int) {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	err := NewChatNotifier(chatSlack, &ChatWebhookConfig{Webhook: server.URL}).Send(testLoginEvent())
+	if err == nil || !strings.Contains(err.Error(), "429") {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if got := parseRetryAfter("1.5", nil); got != 1500*time.Millisecond {
+		t.Fatalf("seconds: got %v", got)
+	}
+	date := time.Now().Add(30 * time.Second).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(date, nil); got < 28*time.Second || got > 30*time.Second {
+		t.Fatalf("http date: got %v", got)
+	}
+	if got := parseRetryAfter("", []byte(`{"retry_after":0.25}`)); got != 250*time.Millisecond {
+		t.Fatalf("body: got %v", got)
+	}
+	if got := parseRetryAfter("", []byte("busy")); got != 0 {
+		t.Fatalf("expected 0, got %v", got)
+	}
+}
@@ -36,6 +36,26 @@ func NewCommand() *cobra.Command {
 	cmd.AddCommand(
 		newCurlCmd(),
 		newEmailCmd(),
+		newTelegramCmd(),
+		newRobotCmd(robotLark, "配置飞书群机器人通知", "https://open.feishu.cn/open-apis/bot/v2/hook/xxx"),
+		newRobotCmd(robotDingTalk, "配置钉钉群机器人通知", "https://oapi.dingtalk.com/robot/send?access_token=xxx"),
+		newRobotCmd(robotWeCom, "配置企业微信群机器人通知", "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=xxx"),
+		newChatWebhookCmd(chatSlack, "配置 Slack incoming webhook 通知（Block Kit）", "https://hooks.slack.com/services/T000/B000/xxx"),
+		newChatWebhookCmd(chatDiscord, "配置 Discord webhook 通知（按事件类型着色的 embed）", "https://discord.com/api/webhooks/000/xxx"),
+		newChatWebhookCmd(chatTeams, "配置 Microsoft Teams webhook 通知（Adaptive Card）", "https://example.webhook.office.com/webhookb2/xxx"),
+		newNtfyCmd(),
+		newGotifyCmd(),
+		newPushoverCmd(),
+		newBarkCmd(),
+		newSyslogCmd(),
+		newFileSinkCmd(),
+		newExecCmd(),
+		newMQTTCmd(),
+		newMatrixCmd(),
+		newSignedWebhookCmd(),
+		newIPLookupCmd(),
+		newResponseCmd(),
+		newSeverityCmd(),
 		newTestCmd(),
 		newStatusCmd(),
 		newDeleteCmd(),
@@ -58,6 +78,8 @@ func newCurlCmd() *cobra.Command {
 		Long: `配置基于 curl 命令的通知，支持以下模板变量：
 
   {{.Type}}      - 事件类型（login_success/login_failed）
+  {{.Subtype}}   - 细分类型（invalid_user/auth_failed/preauth_disconnect 等）
+  {{.Severity}}  - 事件级别（info/low/medium/high/critical）
   {{.User}}      - 登录用户名
   {{.IP}}        - 来源 IP
   {{.Port}}      - 来源端口
@@ -104,43 +126,76 @@ func newCurlCmd() *cobra.Command {
 
 func newEmailCmd() *cobra.Command {
 	var (
-		to     string
-		from   string
-		server string
-		user   string
-		pass   string
-		port   int
-		name   string
-		envErr error
+		to       []string
+		cc       []string
+		bcc      []string
+		from     string
+		server   string
+		user     string
+		pass     string
+		port     int
+		subject  string
+		bodyFile string
+		htmlFile string
+		name     string
+		envErr   error
 	)
 
 	cmd := &cobra.Command{
 		Use:   "email",
 		Short: "配置 SMTP 邮件通知",
+		Long: `配置 SMTP 邮件通知。
+
+--to/--cc/--bcc 可重复指定或用逗号分隔多个地址，Bcc 只用于投递，不会出现在邮件头中。
+--subject 与 --body-file/--html-file 的内容为模板，变量与 curl 相同，例如：
+  --subject '[{{.Severity}}] {{.Hostname}} {{.Type}} {{.User}}@{{.IP}}'
+指定 --html-file 后同时发送纯文本与 HTML 两种正文（multipart/alternative），HTML 模板中的变量会自动转义。`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if envErr != nil {
 				return envErr
 			}
 			input := EmailInput{
-				Name:   name,
-				To:     to,
-				From:   from,
-				Server: server,
-				User:   user,
-				Pass:   pass,
-				Port:   port,
+				Name:    name,
+				To:      to,
+				Cc:      cc,
+				Bcc:     bcc,
+				From:    from,
+				Server:  server,
+				User:    user,
+				Pass:    pass,
+				Port:    port,
+				Subject: subject,
+			}
+			if bodyFile != "" {
+				data, err := os.ReadFile(bodyFile)
+				if err != nil {
+					return fmt.Errorf("读取正文模板失败: %w", err)
+				}
+				input.Body = string(data)
+			}
+			if htmlFile != "" {
+				data, err := os.ReadFile(htmlFile)
+				if err != nil {
+					return fmt.Errorf("读取 HTML 模板失败: %w", err)
+				}
+				input.HTML = string(data)
 			}
 			return configureEmail(input)
 		},
 	}
 
 	cmd.Flags().StringVar(&name, "name", "", "渠道名称（同名则更新，不指定则自动生成）")
-	cmd.Flags().StringVarP(&to, "to", "t", "", "收件人邮箱地址")
-	cmd.Flags().StringVarP(&from, "from", "f", "", "发件人邮箱地址")
+	cmd.Flags().StringSliceVarP(&to, "to", "t", nil, "收件人邮箱地址（可重复或逗号分隔）")
+	cmd.Flags().StringSliceVar(&cc, "cc", nil, "抄送邮箱地址（可重复或逗号分隔）")
+	cmd.Flags().StringSliceVar(&bcc, "bcc", nil, "密送邮箱地址（可重复或逗号分隔）")
+	cmd.Flags().StringVarP(&from, "from", "f", "", "发件人邮箱地址，可带显示名，如 'SSHield <ssh@example.com>'")
 	cmd.Flags().StringVar(&server, "server", "", "SMTP 服务器主机名")
 	cmd.Flags().StringVarP(&user, "user", "u", "", "SMTP 用户名")
 	cmd.Flags().StringVarP(&pass, "password", "p", "", "SMTP 密码")
 	cmd.Flags().IntVar(&port, "port", 587, "SMTP 服务器端口")
+	cmd.Flags().StringVar(&subject, "subject", "", "邮件主题模板（默认：服务器登录提醒 - <事件类型>）")
+	cmd.Flags().StringVar(&bodyFile, "body-file", "", "纯文本正文模板文件（text/template）")
+	cmd.Flags().StringVar(&htmlFile, "html-file", "", "HTML 正文模板文件（html/template）")
 
 	envErr = applyEmailEnvDefaults(cmd)
 
@@ -153,6 +208,566 @@ func newEmailCmd() *cobra.Command {
 	return cmd
 }
 
+func newTelegramCmd() *cobra.Command {
+	var input TelegramInput
+
+	cmd := &cobra.Command{
+		Use:   "telegram",
+		Short: "配置 Telegram Bot 通知",
+		Long: `配置 Telegram Bot 通知，消息使用 MarkdownV2 格式
+
+示例：
+  # 发送到私聊或群组（chat-id 可通过 getUpdates 获取）
+  sshield notify telegram --token 123456:ABC-DEF --chat-id -1001234567890
+
+  # 发送到论坛群组的指定话题
+  sshield notify telegram --token 123456:ABC-DEF --chat-id -1001234567890 --thread-id 42
+
+  # 使用自建的 Bot API 服务
+  sshield notify telegram --token 123456:ABC-DEF --chat-id @my_channel --api-base http://127.0.0.1:8081`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return configureTelegram(input)
+		},
+	}
+
+	cmd.Flags().StringVar(&input.Name, "name", "", "渠道名称（同名则更新，不指定则自动生成）")
+	cmd.Flags().StringVar(&input.Token, "token", "", "Bot token")
+	cmd.Flags().StringVar(&input.ChatID, "chat-id", "", "会话 ID 或 @频道名")
+	cmd.Flags().IntVar(&input.ThreadID, "thread-id", 0, "论坛群组的话题 ID（可选）")
+	cmd.Flags().StringVar(&input.APIBase, "api-base", "", "Bot API 地址（默认 https://api.telegram.org）")
+
+	_ = cmd.MarkFlagRequired("token")
+	_ = cmd.MarkFlagRequired("chat-id")
+
+	return cmd
+}
+
+func newRobotCmd(kind, short, example string) *cobra.Command {
+	var input RobotInput
+
+	long := fmt.Sprintf(`%s，消息布局与 README 中的飞书卡片示例一致
+
+示例：
+  sshield notify %s --webhook '%s'`, short, kind, example)
+	if kind != robotWeCom {
+		long += fmt.Sprintf(`
+
+  # 机器人开启"加签"安全设置时，传入密钥，每次发送自动计算签名
+  sshield notify %s --webhook '%s' --secret SECxxx`, kind, example)
+	}
+
+	cmd := &cobra.Command{
+		Use:   kind,
+		Short: short,
+		Long:  long,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return configureRobot(kind, input)
+		},
+	}
+
+	cmd.Flags().StringVar(&input.Name, "name", "", "渠道名称（同名则更新，不指定则自动生成）")
+	cmd.Flags().StringVar(&input.Webhook, "webhook", "", "机器人 webhook 地址")
+	if kind != robotWeCom {
+		cmd.Flags().StringVar(&input.Secret, "secret", "", "加签密钥（可选）")
+	}
+
+	_ = cmd.MarkFlagRequired("webhook")
+
+	return cmd
+}
+
+func newChatWebhookCmd(kind, short, example string) *cobra.Command {
+	var input ChatWebhookInput
+
+	cmd := &cobra.Command{
+		Use:   kind,
+		Short: short,
+		Long: fmt.Sprintf(`%s
+
+默认消息展示用户、IP、位置、认证方式与主机，遇到限流（HTTP 429）时按 Retry-After 退避重试。
+--template 可用完整的 JSON 消息模板替换默认布局，变量与 curl 渠道相同，
+使用 {{json .User}} 输出转义后的 JSON 字符串。
+
+示例：
+  sshield notify %s --webhook '%s'
+
+  # 自定义消息
+  sshield notify %s --webhook '%s' --template '{"text":{{json .Hostname}}}'`, short, kind, example, kind, example),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return configureChatWebhook(kind, input)
+		},
+	}
+
+	cmd.Flags().StringVar(&input.Name, "name", "", "渠道名称（同名则更新，不指定则自动生成）")
+	cmd.Flags().StringVar(&input.Webhook, "webhook", "", "incoming webhook 地址")
+	cmd.Flags().StringVar(&input.Template, "template", "", "自定义 JSON 消息模板（可选）")
+
+	_ = cmd.MarkFlagRequired("webhook")
+
+	return cmd
+}
+
+// pushPriorityHelp 推送渠道共用的级别说明
+const pushPriorityHelp = `推送优先级随事件级别变化：critical（如触发自动处置、authorized_keys 变更）最高，
+high（如 root 登录成功、sudo/su 失败）可突破勿扰，medium 为默认，low/info（如普通登录失败）静默推送。`
+
+func newNtfyCmd() *cobra.Command {
+	var (
+		name string
+		cfg  NtfyConfig
+	)
+
+	cmd := &cobra.Command{
+		Use:   "ntfy",
+		Short: "配置 ntfy 推送通知",
+		Long: `配置 ntfy 推送通知（支持自建服务）
+
+` + pushPriorityHelp + `
+
+示例：
+  sshield notify ntfy --topic sshield-alerts
+
+  # 自建服务并使用访问令牌
+  sshield notify ntfy --server https://ntfy.example.com --topic ssh --token tk_xxx --tags prod,web`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return configureChannel(ChannelConfig{Name: name, Enabled: true, Type: "ntfy", Ntfy: &cfg})
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "渠道名称（同名则更新，不指定则自动生成）")
+	cmd.Flags().StringVar(&cfg.Server, "server", "", "服务地址（默认 https://ntfy.sh）")
+	cmd.Flags().StringVar(&cfg.Topic, "topic", "", "主题名")
+	cmd.Flags().StringVar(&cfg.Token, "token", "", "访问令牌（可选）")
+	cmd.Flags().StringVar(&cfg.Username, "username", "", "用户名（可选）")
+	cmd.Flags().StringVar(&cfg.Password, "password", "", "密码（可选）")
+	cmd.Flags().StringSliceVar(&cfg.Tags, "tags", nil, "附加标签，逗号分隔（可选）")
+
+	_ = cmd.MarkFlagRequired("topic")
+
+	return cmd
+}
+
+func newGotifyCmd() *cobra.Command {
+	var (
+		name string
+		cfg  GotifyConfig
+	)
+
+	cmd := &cobra.Command{
+		Use:   "gotify",
+		Short: "配置 Gotify 推送通知",
+		Long: `配置 Gotify 推送通知，token 为 Gotify 中创建的应用 token
+
+` + pushPriorityHelp + `
+
+示例：
+  sshield notify gotify --server https://gotify.example.com --token AbCdEf123`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return configureChannel(ChannelConfig{Name: name, Enabled: true, Type: "gotify", Gotify: &cfg})
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "渠道名称（同名则更新，不指定则自动生成）")
+	cmd.Flags().StringVar(&cfg.Server, "server", "", "服务地址")
+	cmd.Flags().StringVar(&cfg.Token, "token", "", "应用 token")
+
+	_ = cmd.MarkFlagRequired("server")
+	_ = cmd.MarkFlagRequired("token")
+
+	return cmd
+}
+
+func newPushoverCmd() *cobra.Command {
+	var (
+		name string
+		cfg  PushoverConfig
+	)
+
+	cmd := &cobra.Command{
+		Use:   "pushover",
+		Short: "配置 Pushover 推送通知",
+		Long: `配置 Pushover 推送通知
+
+` + pushPriorityHelp + `
+critical 事件以紧急级别发送，确认前每隔 --retry 秒重发，持续 --expire 秒。
+
+示例：
+  sshield notify pushover --user-key uQiRzpo4DXghDmr9QzzfQu27cmVRsG --app-token azGDORePK8gMaC0QOYAMyEEuzJnyUi
+
+  # 紧急消息每 2 分钟重发，最多 1 小时
+  sshield notify pushover --user-key uxxx --app-token axxx --retry 120 --expire 3600`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return configureChannel(ChannelConfig{Name: name, Enabled: true, Type: "pushover", Pushover: &cfg})
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "渠道名称（同名则更新，不指定则自动生成）")
+	cmd.Flags().StringVar(&cfg.UserKey, "user-key", "", "用户或群组 key")
+	cmd.Flags().StringVar(&cfg.AppToken, "app-token", "", "应用 API token")
+	cmd.Flags().StringVar(&cfg.Device, "device", "", "只推送到指定设备（可选）")
+	cmd.Flags().IntVar(&cfg.Retry, "retry", 0, "紧急消息重发间隔秒数（>= 30，默认 60）")
+	cmd.Flags().IntVar(&cfg.Expire, "expire", 0, "紧急消息重发持续秒数（<= 10800，默认 3600）")
+
+	_ = cmd.MarkFlagRequired("user-key")
+	_ = cmd.MarkFlagRequired("app-token")
+
+	return cmd
+}
+
+func newBarkCmd() *cobra.Command {
+	var (
+		name string
+		cfg  BarkConfig
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bark",
+		Short: "配置 Bark（iOS）推送通知",
+		Long: `配置 Bark（iOS）推送通知，device key 为 Bark App 中推送地址的最后一段
+
+` + pushPriorityHelp + `
+critical 事件使用 Bark 的重要警告级别，静音模式下也会响铃。
+
+示例：
+  sshield notify bark --device-key xxxxxxxxxxxx
+
+  # 自建 bark-server
+  sshield notify bark --server https://bark.example.com --device-key xxxxxxxxxxxx --group prod`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return configureChannel(ChannelConfig{Name: name, Enabled: true, Type: "bark", Bark: &cfg})
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "渠道名称（同名则更新，不指定则自动生成）")
+	cmd.Flags().StringVar(&cfg.Server, "server", "", "服务地址（默认 https://api.day.app）")
+	cmd.Flags().StringVar(&cfg.DeviceKey, "device-key", "", "设备 key")
+	cmd.Flags().StringVar(&cfg.Group, "group", "", "通知分组（默认 SSHield）")
+	cmd.Flags().StringVar(&cfg.Sound, "sound", "", "铃声（可选）")
+
+	_ = cmd.MarkFlagRequired("device-key")
+
+	return cmd
+}
+
+func newSyslogCmd() *cobra.Command {
+	var (
+		name string
+		cfg  SyslogConfig
+	)
+
+	cmd := &cobra.Command{
+		Use:   "syslog",
+		Short: "配置 syslog 输出（RFC 5424）",
+		Long: `配置 syslog 输出，消息为 RFC 5424 格式，事件字段以结构化数据 [sshield@32473 ...] 附带，
+便于 rsyslog、syslog-ng 转发到 SIEM。TCP 使用 RFC 6587 octet counting 分帧。
+
+severity 默认按事件级别映射：critical→crit，high→err，medium→warning，low→notice，info→info。
+
+示例：
+  # 写入本机 /dev/log
+  sshield notify syslog --facility authpriv
+
+  # 发送到远程收集器
+  sshield notify syslog --network tcp --address 10.0.0.5:514 --facility local4`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return configureChannel(ChannelConfig{Name: name, Enabled: true, Type: "syslog", Syslog: &cfg})
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "渠道名称（同名则更新，不指定则自动生成）")
+	cmd.Flags().StringVar(&cfg.Network, "network", "", "unix/udp/tcp（默认 unix）")
+	cmd.Flags().StringVar(&cfg.Address, "address", "", "套接字路径或 host:port（unix 默认 /dev/log）")
+	cmd.Flags().StringVar(&cfg.Facility, "facility", "", "facility，如 auth/authpriv/local0（默认 auth）")
+	cmd.Flags().StringVar(&cfg.Severity, "severity", "", "固定 severity，如 notice/warning（默认按事件级别映射）")
+
+	return cmd
+}
+
+func newFileSinkCmd() *cobra.Command {
+	var (
+		name string
+		cfg  FileSinkConfig
+	)
+
+	cmd := &cobra.Command{
+		Use:   "file",
+		Short: "配置 JSON Lines 文件输出",
+		Long: `配置 JSON Lines 文件输出，每个事件一行 JSON，便于 filebeat 等采集。
+文件超过 --max-size 后重命名为 .1，已有的轮转文件依次后移，最多保留 --max-backups 个。
+
+示例：
+  sshield notify file --path /var/log/sshield/events.jsonl
+
+  sshield notify file --path /var/log/sshield/events.jsonl --max-size 50 --max-backups 10`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return configureChannel(ChannelConfig{Name: name, Enabled: true, Type: "file", File: &cfg})
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "渠道名称（同名则更新，不指定则自动生成）")
+	cmd.Flags().StringVar(&cfg.Path, "path", "", "输出文件的绝对路径")
+	cmd.Flags().IntVar(&cfg.MaxSizeMB, "max-size", 0, "单个文件上限（MB，默认 100）")
+	cmd.Flags().IntVar(&cfg.MaxBackups, "max-backups", 0, "保留的轮转文件数（默认 5）")
+
+	_ = cmd.MarkFlagRequired("path")
+
+	return cmd
+}
+
+func newExecCmd() *cobra.Command {
+	var (
+		name string
+		cfg  ExecConfig
+	)
+
+	cmd := &cobra.Command{
+		Use:   "exec",
+		Short: "配置外部命令通知",
+		Long: `配置外部命令通知，每个事件运行一次命令（不经过 shell）：
+  - 标准输入：一行 JSON，字段与 file 渠道相同（type、user、ip、severity 等）
+  - 环境变量：同名字段的 SSHIELD_* 变量，如 SSHIELD_TYPE、SSHIELD_USER、SSHIELD_IP、SSHIELD_SEVERITY
+  - 退出码非 0 或超时视为发送失败；stderr 在设置 SSHIELD_DEBUG 时输出
+配置时会以 type=test 的测试事件运行一次。
+
+示例：
+  sshield notify exec --command /usr/local/bin/ssh-inventory --arg update
+
+  sshield notify exec --command /usr/local/bin/alert.sh --timeout 10 --max-concurrent 2`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return configureChannel(ChannelConfig{Name: name, Enabled: true, Type: "exec", Exec: &cfg})
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "渠道名称（同名则更新，不指定则自动生成）")
+	cmd.Flags().StringVar(&cfg.Command, "command", "", "可执行文件的绝对路径")
+	cmd.Flags().StringArrayVar(&cfg.Args, "arg", nil, "命令参数（可重复）")
+	cmd.Flags().IntVar(&cfg.Timeout, "timeout", 0, "超时秒数（默认 30）")
+	cmd.Flags().IntVar(&cfg.MaxConcurrent, "max-concurrent", 0, "同时运行的最大实例数（默认 4）")
+
+	_ = cmd.MarkFlagRequired("command")
+
+	return cmd
+}
+
+func newMQTTCmd() *cobra.Command {
+	var (
+		name string
+		cfg  MQTTConfig
+	)
+
+	cmd := &cobra.Command{
+		Use:   "mqtt",
+		Short: "配置 MQTT 发布",
+		Long: `配置 MQTT 发布，每个事件以 JSON（字段与 file 渠道相同）发布到主题。
+主题为模板，变量与 curl 渠道相同，默认 sshield/{{.Hostname}}/{{.Type}}。
+开启 --retain 后 broker 保留每个主题的最后一条消息，仪表盘订阅时即可看到最近一次登录。
+
+示例：
+  sshield notify mqtt --broker tcp://192.168.1.10:1883
+
+  # TLS、认证、QoS 1 与保留消息
+  sshield notify mqtt --broker ssl://mqtt.example.com:8883 --username sshield --password secret \
+    --topic 'home/ssh/{{.Hostname}}/{{.Type}}' --qos 1 --retain`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return configureChannel(ChannelConfig{Name: name, Enabled: true, Type: "mqtt", MQTT: &cfg})
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "渠道名称（同名则更新，不指定则自动生成）")
+	cmd.Flags().StringVar(&cfg.Broker, "broker", "", "broker 地址（tcp://host:1883 或 ssl://host:8883）")
+	cmd.Flags().StringVar(&cfg.Topic, "topic", "", "主题模板（默认 sshield/{{.Hostname}}/{{.Type}}）")
+	cmd.Flags().IntVar(&cfg.QoS, "qos", 0, "QoS 级别（0 或 1）")
+	cmd.Flags().BoolVar(&cfg.Retain, "retain", false, "发布保留消息")
+	cmd.Flags().StringVar(&cfg.Username, "username", "", "用户名（可选）")
+	cmd.Flags().StringVar(&cfg.Password, "password", "", "密码（可选）")
+	cmd.Flags().StringVar(&cfg.ClientID, "client-id", "", "客户端 ID 前缀，每次连接追加随机后缀（默认 sshield-<主机名>）")
+	cmd.Flags().StringVar(&cfg.CAFile, "ca-file", "", "TLS 自定义 CA 证书（可选）")
+	cmd.Flags().BoolVar(&cfg.InsecureSkipVerify, "insecure", false, "跳过 TLS 证书校验")
+
+	_ = cmd.MarkFlagRequired("broker")
+
+	return cmd
+}
+
+func newMatrixCmd() *cobra.Command {
+	var (
+		name string
+		cfg  MatrixConfig
+	)
+
+	cmd := &cobra.Command{
+		Use:   "matrix",
+		Short: "配置 Matrix 房间通知",
+		Long: `配置 Matrix 房间通知，发送同时包含纯文本 body 与 HTML formatted_body 的 m.room.message。
+每条消息使用独立的事务 ID，失败重试时复用，服务端据此去重。
+
+机器人账号需先加入房间；房间 ID 可在 Element 的"房间设置 → 高级"中查看。
+
+示例：
+  sshield notify matrix --homeserver https://matrix.example.com \
+    --access-token syt_xxx --room-id '!AbCdEf:example.com'`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return configureChannel(ChannelConfig{Name: name, Enabled: true, Type: "matrix", Matrix: &cfg})
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "渠道名称（同名则更新，不指定则自动生成）")
+	cmd.Flags().StringVar(&cfg.Homeserver, "homeserver", "", "homeserver 地址")
+	cmd.Flags().StringVar(&cfg.AccessToken, "access-token", "", "机器人账号的 access token")
+	cmd.Flags().StringVar(&cfg.RoomID, "room-id", "", "房间 ID（!xxx:example.com）")
+
+	_ = cmd.MarkFlagRequired("homeserver")
+	_ = cmd.MarkFlagRequired("access-token")
+	_ = cmd.MarkFlagRequired("room-id")
+
+	return cmd
+}
+
+func newSignedWebhookCmd() *cobra.Command {
+	var (
+		name string
+		cfg  SignedWebhookConfig
+	)
+
+	cmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "配置签名 JSON webhook",
+		Long: `配置签名 JSON webhook，以固定格式（version 1）POST 事件，无需自行设计消息模板。
+
+请求头：
+  X-SSHield-Timestamp  发送时的 Unix 秒
+  X-SSHield-Signature  sha256=<hex(HMAC-SHA256(secret, 时间戳 + "." + 请求体))>
+  X-SSHield-Event-ID   事件 ID，与消息中的 id 相同，可用于去重
+
+接收方应使用原始请求体校验签名，并拒绝时间戳与当前时间相差超过 5 分钟的请求。
+消息格式见 README。
+
+示例：
+  sshield notify webhook --url https://hooks.example.com/sshield --secret "$(openssl rand -hex 32)"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return configureChannel(ChannelConfig{Name: name, Enabled: true, Type: "webhook", Webhook: &cfg})
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "渠道名称（同名则更新，不指定则自动生成）")
+	cmd.Flags().StringVar(&cfg.URL, "url", "", "接收地址")
+	cmd.Flags().StringVar(&cfg.Secret, "secret", "", "签名密钥（至少 16 个字符）")
+
+	_ = cmd.MarkFlagRequired("url")
+	_ = cmd.MarkFlagRequired("secret")
+
+	return cmd
+}
+
+func newIPLookupCmd() *cobra.Command {
+	var (
+		mode   string
+		cityDB string
+		asnDB  string
+		reload bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "iplookup",
+		Short: "配置 IP 地理位置查询方式",
+		Long: `配置 IP 地理位置/ASN 查询方式，支持读取本地 MaxMind GeoLite2 或 DB-IP 的 mmdb 文件，
+避免将攻击者 IP 发送给第三方，也可在离线服务器上使用。
+
+查询模式：
+  local          仅查询本地 mmdb
+  local-first    先查本地 mmdb，未命中再查询在线接口（指定 mmdb 时默认）
+  remote         仅查询在线接口 ipinfo.io / ip-api.com（未配置时默认）
+
+示例：
+  sshield notify iplookup --city-db /usr/share/GeoIP/GeoLite2-City.mmdb --asn-db /usr/share/GeoIP/GeoLite2-ASN.mmdb
+  sshield notify iplookup --mode local --city-db /var/lib/dbip/dbip-city-lite.mmdb
+  sshield notify iplookup --mode remote`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return configureIPLookup(IPLookupConfig{
+				Mode:   mode,
+				CityDB: cityDB,
+				ASNDB:  asnDB,
+				Reload: reload,
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&mode, "mode", "", "查询模式：local｜local-first｜remote")
+	cmd.Flags().StringVar(&cityDB, "city-db", "", "City/Country mmdb 文件路径")
+	cmd.Flags().StringVar(&asnDB, "asn-db", "", "ASN mmdb 文件路径")
+	cmd.Flags().BoolVar(&reload, "reload", true, "mmdb 文件变更后自动重新加载")
+
+	return cmd
+}
+
+func newResponseCmd() *cobra.Command {
+	var (
+		disable       bool
+		denyCountries []string
+		allowedKeys   []string
+		lockAccount   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "response",
+		Short: "配置可疑登录的自动处置",
+		Long: `配置可疑登录的自动处置：登录成功事件命中策略时，根据来源 IP/端口找到对应的 sshd 会话并终止，
+可选同时锁定账户（usermod -L -e 1，root 账户不会被锁定）。处置结果会附在通知中。
+
+命中条件（任一即可）：
+  --deny-country   来源 IP 所属国家在列表中（依赖 IP 查询返回国家代码）
+  --allow-key      公钥登录的指纹不在允许列表中
+
+建议先通过 watch --dry-run 演练策略。自动处置只在 watch 中对启动后的登录执行，sweep 不会处置历史事件。
+只处置 journald 中由 sshd 进程（_COMM）以 root（_UID=0）写入的登录日志：日志文件与 SYSLOG_IDENTIFIER
+可被本地用户伪造（如 logger -t sshd），不会触发处置。找不到对应的 sshd 会话时不锁定账户。
+
+示例：
+  sshield notify response --deny-country CN,RU
+  sshield notify response --allow-key SHA256:abc... --allow-key SHA256:def... --lock-account
+  sshield ssh watch --dry-run
+  sshield notify response --disable`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return configureResponse(ResponseConfig{
+				Enabled:       !disable,
+				DenyCountries: denyCountries,
+				AllowedKeys:   allowedKeys,
+				LockAccount:   lockAccount,
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&disable, "disable", false, "关闭自动处置")
+	cmd.Flags().StringSliceVar(&denyCountries, "deny-country", nil, "禁止登录的国家代码（可重复或逗号分隔，如 CN,RU）")
+	cmd.Flags().StringSliceVar(&allowedKeys, "allow-key", nil, "允许登录的公钥指纹（可重复，SHA256:...）")
+	cmd.Flags().BoolVar(&lockAccount, "lock-account", false, "终止会话后同时锁定账户")
+
+	return cmd
+}
+
+func newSeverityCmd() *cobra.Command {
+	var homeCountries []string
+
+	cmd := &cobra.Command{
+		Use:   "severity",
+		Short: "配置事件级别的常用国家",
+		Long: `配置常用登录国家：root 登录成功且来源 IP 所属国家不在列表中时，事件级别由 high 升级为 critical
+（Pushover 紧急优先级并重复提醒，ntfy 最高优先级）。依赖 IP 查询返回国家代码，查询不到国家时保持 high。
+
+示例：
+  sshield notify severity --home-country CN
+  sshield notify severity --home-country CN,JP
+  sshield notify severity            # 清除常用国家`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return configureSeverity(SeverityConfig{HomeCountries: homeCountries})
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&homeCountries, "home-country", nil, "常用登录国家代码（可重复或逗号分隔，如 CN,JP）")
+
+	return cmd
+}
+
 type envBinding struct {
 	flag string
 	env  string
@@ -197,11 +812,19 @@ func NewWatchCommand() *cobra.Command {
 		poll          time.Duration
 		source        string
 		units         []string
+		identifiers   []string
+		comms         []string
+		journalReader string
 		logs          []string
 		timezone      string
+		logTimezone   string
 		notifyOnStr   string
 		failLimit     int
 		failWindowStr string
+		scanWindowStr string
+		scanLimit     int
+		dryRun        bool
+		auditFiles    bool
 	)
 
 	cmd := &cobra.Command{
@@ -213,10 +836,22 @@ func NewWatchCommand() *cobra.Command {
   --notify-on success    只通知登录成功
   --notify-on failed     只通知登录失败
   --notify-on all        通知所有事件（默认）
+  --notify-on privilege  只通知 sudo/su 提权事件
+  --notify-on success,sudo_command,su_session   逗号分隔组合类别与事件类型
+  --notify-on success,invalid_user              login_failed 细分类型：invalid_user｜auth_failed｜
+                                                preauth_disconnect｜max_auth_exceeded｜kex_failed｜too_many_connections
 
 失败限流选项（减少攻击造成的打扰）：
   --fail-limit 5 --fail-window 1h   每个 IP 每小时最多 5 条失败通知
 
+文件审计（默认开启，--audit-files=false 关闭）：
+  监听各用户 ~/.ssh/authorized_keys、/etc/sudoers(.d)、/etc/ssh/sshd_config(.d)，
+  与状态目录中的哈希基线对比，变更时发送 authorized_keys_changed/account_changed/sshd_config_changed
+
+扫描检测选项（同一来源的探测合并为一条 scan_detected）：
+  --scan-window 10m --scan-limit 5  10 分钟内未认证断开、协议异常或尝试用户名达到 5 个即判定为扫描
+  --scan-window 0                   关闭扫描检测
+
 时间窗口格式：
   30s, 5m, 1h, 1d (天), 1w (周), 1M (月)`,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -243,6 +878,11 @@ func NewWatchCommand() *cobra.Command {
 				return err
 			}
 
+			logLoc, err := resolveLogLocation(logTimezone)
+			if err != nil {
+				return err
+			}
+
 			notifyOn, err := parseNotifyOn(notifyOnStr)
 			if err != nil {
 				return err
@@ -253,30 +893,51 @@ func NewWatchCommand() *cobra.Command {
 				return err
 			}
 
+			scanWindow, err := parseDurationExtended(scanWindowStr)
+			if err != nil {
+				return err
+			}
+
 			opts := WatchOptions{
-				CursorPath:   stateFile,
-				PollTimeout:  poll,
-				Source:       source,
-				JournalUnits: units,
-				LogPaths:     logs,
-				DisplayLoc:   loc,
-				NotifyOn:     notifyOn,
-				FailLimit:    failLimit,
-				FailWindow:   failWindow,
+				CursorPath:    stateFile,
+				PollTimeout:   poll,
+				Source:        source,
+				JournalUnits:  units,
+				Identifiers:   identifiers,
+				Comms:         comms,
+				JournalReader: journalReader,
+				LogPaths:      logs,
+				DisplayLoc:    loc,
+				LogLoc:        logLoc,
+				NotifyOn:      notifyOn,
+				FailLimit:     failLimit,
+				FailWindow:    failWindow,
+				ScanWindow:    scanWindow,
+				ScanLimit:     scanLimit,
+				DryRun:        dryRun,
+				AuditFiles:    auditFiles,
 			}
 			return RunWatch(ctx, opts)
 		},
 	}
 
 	cmd.Flags().StringVar(&stateFile, "state-file", "", "保存日志游标的路径（默认自动选择）")
-	cmd.Flags().DurationVar(&poll, "poll", 5*time.Second, "等待新日志事件的超时时间（默认 5s）")
-	cmd.Flags().StringVar(&source, "source", "auto", "事件来源：auto｜journal｜file（默认 auto）")
-	cmd.Flags().StringSliceVar(&units, "journal-unit", nil, "需要监听的 Journal 单元名（可重复，默认 sshd.service｜ssh.service）")
-	cmd.Flags().StringSliceVar(&logs, "log-path", nil, "需要跟踪的认证日志路径（可重复，默认 /var/log/auth.log、/var/log/secure）")
+	cmd.Flags().DurationVar(&poll, "poll", 5*time.Second, "轮询新日志事件的间隔（日志文件优先使用 inotify 即时读取，不可用时按此间隔轮询，默认 5s）")
+	cmd.Flags().StringVar(&source, "source", "auto", "事件来源：auto｜journal｜file｜all（file 读取全部存在的日志文件，all 同时读取 journald，默认 auto）")
+	cmd.Flags().StringSliceVar(&units, "journal-unit", nil, "需要监听的 Journal 单元名（可重复，支持通配符，默认 sshd.service｜ssh.service｜sshd@*.service｜ssh@*.service）")
+	cmd.Flags().StringSliceVar(&identifiers, "syslog-identifier", nil, "sshd 的 syslog 标识（可重复，支持通配符，默认 sshd｜sshd-session｜sshd-auth）")
+	cmd.Flags().StringSliceVar(&comms, "comm", nil, "sshd 的进程名（可重复，支持通配符，默认同 --syslog-identifier）")
+	cmd.Flags().StringVar(&journalReader, "journal-reader", "auto", "journald 读取方式：auto｜native｜journalctl（auto 优先直接读取 journal 文件，格式不支持时改用 journalctl）")
+	cmd.Flags().StringSliceVar(&logs, "log-path", nil, "需要跟踪的认证日志路径（可重复，支持通配符，默认 /var/log/auth.log、/var/log/secure）")
 	cmd.Flags().StringVar(&timezone, "timezone", "Asia/Shanghai", "显示使用的时区（示例：'Asia/Shanghai'｜'Local'，默认 Asia/Shanghai）")
-	cmd.Flags().StringVar(&notifyOnStr, "notify-on", "all", "通知类型：all｜success｜failed（默认 all）")
+	cmd.Flags().StringVar(&logTimezone, "log-timezone", "", "日志时间戳不含时区时（传统 syslog 格式）使用的时区（默认本机时区）")
+	cmd.Flags().StringVar(&notifyOnStr, "notify-on", "all", "通知类型：all｜success｜failed｜privilege、事件类型或细分类型，可逗号分隔（默认 all）")
 	cmd.Flags().IntVar(&failLimit, "fail-limit", 0, "每个 IP 失败通知限制数量（0 表示不限制）")
 	cmd.Flags().StringVar(&failWindowStr, "fail-window", "1h", "失败限制时间窗口（支持 s/m/h/d/w/M）")
+	cmd.Flags().StringVar(&scanWindowStr, "scan-window", "10m", "扫描检测时间窗口（支持 s/m/h/d/w/M，0 表示关闭）")
+	cmd.Flags().IntVar(&scanLimit, "scan-limit", defaultScanLimit, "窗口内判定为扫描的阈值")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "自动处置仅演练：记录将执行的操作，不终止会话")
+	cmd.Flags().BoolVar(&auditFiles, "audit-files", true, "审计 authorized_keys、sudoers 与 sshd 配置的变更")
 
 	return cmd
 }
@@ -285,15 +946,21 @@ func NewWatchCommand() *cobra.Command {
 func NewSweepCommand() *cobra.Command {
 	var (
 		stateFile     string
-		since         time.Duration
+		sinceStr      string
 		source        string
 		units         []string
+		identifiers   []string
+		comms         []string
+		journalReader string
 		logs          []string
 		timezone      string
+		logTimezone   string
 		notify        bool
 		notifyOnStr   string
 		failLimit     int
 		failWindowStr string
+		scanWindowStr string
+		scanLimit     int
 	)
 
 	cmd := &cobra.Command{
@@ -305,9 +972,16 @@ func NewSweepCommand() *cobra.Command {
   --notify-on success    只通知登录成功
   --notify-on failed     只通知登录失败
   --notify-on all        通知所有事件（默认）
+  --notify-on privilege  只通知 sudo/su 提权事件
+  --notify-on success,sudo_command,su_session   逗号分隔组合类别与事件类型
+  --notify-on success,invalid_user              login_failed 细分类型：invalid_user｜auth_failed｜
+                                                preauth_disconnect｜max_auth_exceeded｜kex_failed｜too_many_connections
 
 失败限流选项：
-  --fail-limit 5 --fail-window 1h   每个 IP 每小时最多 5 条失败通知`,
+  --fail-limit 5 --fail-window 1h   每个 IP 每小时最多 5 条失败通知
+
+扫描检测选项：
+  --scan-window 10m --scan-limit 5  同一来源的探测合并为一条 scan_detected（0 表示关闭）`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if stateFile == "" {
 				var err error
@@ -325,6 +999,11 @@ func NewSweepCommand() *cobra.Command {
 				return err
 			}
 
+			logLoc, err := resolveLogLocation(logTimezone)
+			if err != nil {
+				return err
+			}
+
 			notifyOn, err := parseNotifyOn(notifyOnStr)
 			if err != nil {
 				return err
@@ -335,32 +1014,54 @@ func NewSweepCommand() *cobra.Command {
 				return err
 			}
 
+			scanWindow, err := parseDurationExtended(scanWindowStr)
+			if err != nil {
+				return err
+			}
+
+			since, err := parseDurationExtended(sinceStr)
+			if err != nil {
+				return err
+			}
+
 			opts := SweepOptions{
-				CursorPath:   stateFile,
-				Since:        since,
-				Source:       source,
-				JournalUnits: units,
-				LogPaths:     logs,
-				Notify:       notify,
-				DisplayLoc:   loc,
-				NotifyOn:     notifyOn,
-				FailLimit:    failLimit,
-				FailWindow:   failWindow,
+				CursorPath:    stateFile,
+				Since:         since,
+				Source:        source,
+				JournalUnits:  units,
+				Identifiers:   identifiers,
+				Comms:         comms,
+				JournalReader: journalReader,
+				LogPaths:      logs,
+				Notify:        notify,
+				DisplayLoc:    loc,
+				LogLoc:        logLoc,
+				NotifyOn:      notifyOn,
+				FailLimit:     failLimit,
+				FailWindow:    failWindow,
+				ScanWindow:    scanWindow,
+				ScanLimit:     scanLimit,
 			}
 			return runSweepFunc(ctx, opts)
 		},
 	}
 
 	cmd.Flags().StringVar(&stateFile, "state-file", "", "保存日志游标的路径（默认自动选择）")
-	cmd.Flags().DurationVar(&since, "since", 1*time.Hour, "检查时间范围（默认 1h）")
-	cmd.Flags().StringVar(&source, "source", "auto", "事件来源：auto｜journal｜file（默认 auto）")
-	cmd.Flags().StringSliceVar(&units, "journal-unit", nil, "需要扫描的 Journal 单元名（可重复，默认 sshd.service｜ssh.service）")
-	cmd.Flags().StringSliceVar(&logs, "log-path", nil, "需要扫描的 SSH 认证日志路径（可重复，默认 /var/log/auth.log、/var/log/secure）")
+	cmd.Flags().StringVar(&sinceStr, "since", "1h", "检查时间范围（支持 s/m/h/d/w/M，文件日志会一并读取轮转出的历史文件，默认 1h）")
+	cmd.Flags().StringVar(&source, "source", "auto", "事件来源：auto｜journal｜file｜all（file 读取全部存在的日志文件，all 同时读取 journald，默认 auto）")
+	cmd.Flags().StringSliceVar(&units, "journal-unit", nil, "需要扫描的 Journal 单元名（可重复，支持通配符，默认 sshd.service｜ssh.service｜sshd@*.service｜ssh@*.service）")
+	cmd.Flags().StringSliceVar(&identifiers, "syslog-identifier", nil, "sshd 的 syslog 标识（可重复，支持通配符，默认 sshd｜sshd-session｜sshd-auth）")
+	cmd.Flags().StringSliceVar(&comms, "comm", nil, "sshd 的进程名（可重复，支持通配符，默认同 --syslog-identifier）")
+	cmd.Flags().StringVar(&journalReader, "journal-reader", "auto", "journald 读取方式：auto｜native｜journalctl（auto 优先直接读取 journal 文件，格式不支持时改用 journalctl）")
+	cmd.Flags().StringSliceVar(&logs, "log-path", nil, "需要扫描的 SSH 认证日志路径（可重复，支持通配符，默认 /var/log/auth.log、/var/log/secure）")
 	cmd.Flags().StringVar(&timezone, "timezone", "Asia/Shanghai", "显示使用的时区（示例：'Asia/Shanghai'｜'Local'，默认 Asia/Shanghai）")
+	cmd.Flags().StringVar(&logTimezone, "log-timezone", "", "日志时间戳不含时区时（传统 syslog 格式）使用的时区（默认本机时区）")
 	cmd.Flags().BoolVar(&notify, "notify", false, "是否发送通知（默认仅输出到控制台）")
-	cmd.Flags().StringVar(&notifyOnStr, "notify-on", "all", "通知类型：all｜success｜failed（默认 all）")
+	cmd.Flags().StringVar(&notifyOnStr, "notify-on", "all", "通知类型：all｜success｜failed｜privilege、事件类型或细分类型，可逗号分隔（默认 all）")
 	cmd.Flags().IntVar(&failLimit, "fail-limit", 0, "每个 IP 失败通知限制数量（0 表示不限制）")
 	cmd.Flags().StringVar(&failWindowStr, "fail-window", "1h", "失败限制时间窗口（支持 s/m/h/d/w/M）")
+	cmd.Flags().StringVar(&scanWindowStr, "scan-window", "10m", "扫描检测时间窗口（支持 s/m/h/d/w/M，0 表示关闭）")
+	cmd.Flags().IntVar(&scanLimit, "scan-limit", defaultScanLimit, "窗口内判定为扫描的阈值")
 
 	return cmd
 }
@@ -484,7 +1185,7 @@ func newDeleteCmd() *cobra.Command {
 	}
 
 	cmd.Flags().BoolVar(&deleteAll, "all", false, "删除所有通知配置")
-	cmd.Flags().StringVar(&channelType, "type", "", "按类型删除（curl/email）")
+	cmd.Flags().StringVar(&channelType, "type", "", "按类型删除（curl/email/telegram/lark/dingtalk/wecom/slack/discord/teams/ntfy/gotify/pushover/bark/syslog/file/exec/mqtt/matrix/webhook）")
 	cmd.Flags().IntVar(&index, "index", 0, "按序号删除（从 1 开始，可通过 status 查看）")
 
 	return cmd
@@ -642,16 +1343,42 @@ func parseDurationExtended(s string) (time.Duration, error) {
 	}
 }
 
+// resolveLogLocation 解析日志所用时区，为空时使用本机时区
+func resolveLogLocation(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("无法识别的时区 %q: %w", name, err)
+	}
+	return loc, nil
+}
+
 // parseNotifyOn 解析通知类型参数
+// 支持逗号分隔的多个值，可混合类别与具体事件类型
 func parseNotifyOn(s string) (NotifyOn, error) {
-	switch strings.ToLower(strings.TrimSpace(s)) {
-	case "all", "":
+	var tokens []string
+	for _, token := range strings.Split(s, ",") {
+		token = strings.ToLower(strings.TrimSpace(token))
+		switch token {
+		case "":
+			continue
+		case "all":
+			return NotifyOnAll, nil
+		case "fail":
+			token = string(NotifyOnFailed)
+		case string(NotifyOnSuccess), string(NotifyOnFailed), notifyOnPrivilege:
+		default:
+			if !isNotifiableEventType(token) {
+				return "", fmt.Errorf("无效的通知类型: %s（支持 all/success/failed/privilege、事件类型或细分类型，如 sudo_command、invalid_user）", token)
+			}
+		}
+		tokens = append(tokens, token)
+	}
+	if len(tokens) == 0 {
 		return NotifyOnAll, nil
-	case "success":
-		return NotifyOnSuccess, nil
-	case "failed", "fail":
-		return NotifyOnFailed, nil
-	default:
-		return "", fmt.Errorf("无效的通知类型: %s（支持 all/success/failed）", s)
 	}
+	return NotifyOn(strings.Join(tokens, ",")), nil
 }
@@ -3,6 +3,7 @@ package notify
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -138,6 +139,19 @@ func loadConfig() (*Config, error) {
 }
 
 func printConfigSummary(cfg *Config) {
+	if cfg != nil && cfg.IPLookup != nil {
+		printIPLookupSummary(cfg.IPLookup)
+		fmt.Println()
+	}
+	if cfg != nil && cfg.Response != nil {
+		printResponseSummary(cfg.Response)
+		fmt.Println()
+	}
+	if cfg != nil && cfg.Severity != nil {
+		printSeveritySummary(cfg.Severity)
+		fmt.Println()
+	}
+
 	if cfg == nil || len(cfg.Channels) == 0 {
 		fmt.Println("未配置通知渠道。")
 		return
@@ -168,10 +182,174 @@ func printConfigSummary(cfg *Config) {
 			}
 		case "email":
 			if ch.Email != nil {
-				fmt.Printf("      收件人：%s\n", ch.Email.To)
+				fmt.Printf("      收件人：%s\n", strings.Join(ch.Email.To, ", "))
+				if len(ch.Email.Cc) > 0 {
+					fmt.Printf("      抄送：%s\n", strings.Join(ch.Email.Cc, ", "))
+				}
+				if len(ch.Email.Bcc) > 0 {
+					fmt.Printf("      密送：%d 个地址\n", len(ch.Email.Bcc))
+				}
 				fmt.Printf("      发件人：%s\n", ch.Email.From)
 				fmt.Printf("      SMTP：%s:%d\n", ch.Email.Server, ch.Email.Port)
 			}
+		case "telegram":
+			if ch.Telegram != nil {
+				fmt.Printf("      会话：%s\n", ch.Telegram.ChatID)
+				if ch.Telegram.ThreadID > 0 {
+					fmt.Printf("      话题：%d\n", ch.Telegram.ThreadID)
+				}
+				if ch.Telegram.APIBase != "" {
+					fmt.Printf("      API：%s\n", ch.Telegram.APIBase)
+				}
+			}
+		case robotLark, robotDingTalk, robotWeCom:
+			if r := ch.robot(strings.ToLower(ch.Type)); r != nil {
+				fmt.Printf("      Webhook：%s\n", maskWebhook(r.Webhook))
+				if r.Secret != "" {
+					fmt.Println("      签名：已启用")
+				}
+			}
+		case chatSlack, chatDiscord, chatTeams:
+			if c := ch.chatWebhook(strings.ToLower(ch.Type)); c != nil {
+				fmt.Printf("      Webhook：%s\n", maskWebhook(c.Webhook))
+				if c.Template != "" {
+					fmt.Println("      模板：自定义")
+				}
+			}
+		case "ntfy":
+			if ch.Ntfy != nil {
+				server := ch.Ntfy.Server
+				if server == "" {
+					server = defaultNtfyServer
+				}
+				fmt.Printf("      主题：%s/%s\n", strings.TrimRight(server, "/"), ch.Ntfy.Topic)
+			}
+		case "gotify":
+			if ch.Gotify != nil {
+				fmt.Printf("      服务：%s\n", ch.Gotify.Server)
+			}
+		case "pushover":
+			if ch.Pushover != nil && ch.Pushover.Device != "" {
+				fmt.Printf("      设备：%s\n", ch.Pushover.Device)
+			}
+		case "bark":
+			if ch.Bark != nil && ch.Bark.Server != "" {
+				fmt.Printf("      服务：%s\n", ch.Bark.Server)
+			}
+		case "syslog":
+			if s := ch.Syslog; s != nil {
+				network, address, facility := s.Network, s.Address, s.Facility
+				if network == "" {
+					network = "unix"
+				}
+				if address == "" {
+					address = "/dev/log"
+				}
+				if facility == "" {
+					facility = "auth"
+				}
+				fmt.Printf("      地址：%s://%s\n", network, address)
+				fmt.Printf("      Facility：%s\n", facility)
+				if s.Severity != "" {
+					fmt.Printf("      Severity：%s\n", s.Severity)
+				}
+			}
+		case "file":
+			if ch.File != nil {
+				n := NewFileSinkNotifier(ch.File)
+				fmt.Printf("      路径：%s\n", ch.File.Path)
+				fmt.Printf("      轮转：%d MB，保留 %d 个\n", n.maxSize>>20, n.maxBackups)
+			}
+		case "exec":
+			if ch.Exec != nil {
+				n := NewExecNotifier(ch.Exec)
+				fmt.Printf("      命令：%s\n", strings.Join(append([]string{ch.Exec.Command}, ch.Exec.Args...), " "))
+				fmt.Printf("      超时：%v，并发上限：%d\n", n.timeout, n.maxConcurrent)
+			}
+		case "mqtt":
+			if m := ch.MQTT; m != nil {
+				topic := m.Topic
+				if topic == "" {
+					topic = defaultMQTTTopic
+				}
+				fmt.Printf("      Broker：%s\n", m.Broker)
+				fmt.Printf("      主题：%s（QoS %d）\n", topic, m.QoS)
+				if m.Retain {
+					fmt.Println("      保留消息：是")
+				}
+			}
+		case "matrix":
+			if ch.Matrix != nil {
+				fmt.Printf("      Homeserver：%s\n", ch.Matrix.Homeserver)
+				fmt.Printf("      房间：%s\n", ch.Matrix.RoomID)
+			}
+		case "webhook":
+			if ch.Webhook != nil {
+				fmt.Printf("      地址：%s\n", maskWebhook(ch.Webhook.URL))
+				fmt.Printf("      格式：version %d，HMAC-SHA256 签名\n", webhookSchemaVersion)
+			}
 		}
 	}
 }
+
+func printIPLookupSummary(c *IPLookupConfig) {
+	fmt.Printf("IP 查询：%s\n", c.effectiveMode())
+	if c.CityDB != "" {
+		fmt.Printf("      City 库：%s\n", c.CityDB)
+	}
+	if c.ASNDB != "" {
+		fmt.Printf("      ASN 库：%s\n", c.ASNDB)
+	}
+	if c.effectiveMode() != ipLookupModeRemote {
+		reload := "否"
+		if c.Reload {
+			reload = "是"
+		}
+		fmt.Printf("      变更自动重载：%s\n", reload)
+	}
+}
+
+func printResponseSummary(c *ResponseConfig) {
+	status := "禁用"
+	if c.Enabled {
+		status = "启用"
+	}
+	fmt.Printf("自动处置：%s\n", status)
+	if len(c.DenyCountries) > 0 {
+		fmt.Printf("      禁止国家：%s\n", strings.Join(c.DenyCountries, ", "))
+	}
+	if len(c.AllowedKeys) > 0 {
+		fmt.Printf("      允许公钥：%d 个\n", len(c.AllowedKeys))
+	}
+	lock := "否"
+	if c.LockAccount {
+		lock = "是"
+	}
+	fmt.Printf("      锁定账户：%s\n", lock)
+}
+
+func printSeveritySummary(c *SeverityConfig) {
+	if len(c.HomeCountries) == 0 {
+		fmt.Println("常用国家：未配置")
+		return
+	}
+	fmt.Printf("常用国家：%s\n", strings.Join(c.HomeCountries, ", "))
+	fmt.Println("      root 从其他国家登录成功时级别升级为 critical")
+}
+
+// maskWebhook 隐藏 webhook 地址中的访问令牌（路径末段与查询参数）
+func maskWebhook(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "***"
+	}
+	path := u.Path
+	if i := strings.LastIndex(path, "/"); i >= 0 && i < len(path)-1 {
+		path = path[:i+1] + "***"
+	}
+	masked := u.Scheme + "://" + u.Host + path
+	if u.RawQuery != "" {
+		masked += "?***"
+	}
+	return masked
+}
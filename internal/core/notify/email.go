@@ -1,48 +1,213 @@
 package notify
 
 import (
+	"bytes"
+	"crypto/rand"
 	"crypto/tls"
+	"encoding/hex"
 	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
 	"net"
+	"net/mail"
 	"net/smtp"
+	"net/textproto"
 	"strings"
+	texttemplate "text/template"
 	"time"
 )
 
 // EmailInput 命令行输入的邮件配置
 type EmailInput struct {
-	Name   string
-	To     string
-	From   string
-	Server string
-	User   string
-	Pass   string
-	Port   int
+	Name    string
+	To      []string
+	Cc      []string
+	Bcc     []string
+	From    string
+	Server  string
+	User    string
+	Pass    string
+	Port    int
+	Subject string
+	Body    string
+	HTML    string
 }
 
 type EmailNotifier struct {
-	To       string
+	To       []string
+	Cc       []string
+	Bcc      []string
 	From     string
 	Server   string
 	Port     int
 	Username string
 	Password string
+	Subject  string // 主题模板，为空时使用内置主题
+	Body     string // 纯文本正文模板，为空时使用内置正文
+	HTML     string // HTML 正文模板，为空时只发送纯文本
 }
 
 // NewEmailNotifierFromChannel 从渠道配置创建邮件通知器
 func NewEmailNotifierFromChannel(cfg *EmailConfig) *EmailNotifier {
 	return &EmailNotifier{
 		To:       cfg.To,
+		Cc:       cfg.Cc,
+		Bcc:      cfg.Bcc,
 		From:     cfg.From,
 		Server:   cfg.Server,
 		Port:     cfg.Port,
 		Username: cfg.User,
 		Password: cfg.Pass,
+		Subject:  cfg.Subject,
+		Body:     cfg.Body,
+		HTML:     cfg.HTML,
 	}
 }
 
 func (e *EmailNotifier) Send(event LoginEvent) error {
-	subject := fmt.Sprintf("服务器登录提醒 - %s", event.Type)
+	msg, err := e.buildMessage(event, time.Now())
+	if err != nil {
+		return err
+	}
+	recipients, err := e.recipients()
+	if err != nil {
+		return err
+	}
+
+	auth := smtp.PlainAuth("", e.Username, e.Password, e.Server)
+	addr := fmt.Sprintf("%s:%d", e.Server, e.Port)
+
+	// 旧实现直接使用 smtp.SendMail，无法控制超时且不支持 465 端口的隐式 TLS，会导致命令卡住。
+	// return smtp.SendMail(addr, auth, e.From, []string{e.To}, []byte(msg))
+
+	debugf("notify: 准备连接 SMTP %s，收件人 %d 个", addr, len(recipients))
+	if err := e.sendMailWithTimeout(addr, auth, recipients, msg); err != nil {
+		return err
+	}
+	debugf("notify: SMTP 发送完成")
+	return nil
+}
+
+func (e *EmailNotifier) Test() error {
+	return e.Send(channelTestEvent())
+}
+
+// buildMessage 生成完整的邮件内容：非 ASCII 的邮件头按 RFC 2047 编码，正文使用 quoted-printable；
+// 设置了 HTML 模板时正文为 multipart/alternative（纯文本在前、HTML 在后）。Bcc 不写入邮件头
+func (e *EmailNotifier) buildMessage(event LoginEvent, now time.Time) ([]byte, error) {
+	from, err := mail.ParseAddress(e.From)
+	if err != nil {
+		return nil, fmt.Errorf("发件人地址无效: %w", err)
+	}
+	to, err := parseAddressList(e.To)
+	if err != nil {
+		return nil, fmt.Errorf("收件人地址无效: %w", err)
+	}
+	cc, err := parseAddressList(e.Cc)
+	if err != nil {
+		return nil, fmt.Errorf("抄送地址无效: %w", err)
+	}
+
+	subject := fmt.Sprintf("服务器登录提醒 - %s", event.displayType())
+	if e.Subject != "" {
+		if subject, err = renderEmailText(e.Subject, event); err != nil {
+			return nil, err
+		}
+	}
+	// 主题必须是单行，模板渲染出的换行会被用来注入邮件头
+	subject = strings.Join(strings.Fields(subject), " ")
+
+	text := defaultEmailBody(event)
+	if e.Body != "" {
+		if text, err = renderEmailText(e.Body, event); err != nil {
+			return nil, err
+		}
+	}
+
+	var msg bytes.Buffer
+	writeHeader := func(key, value string) {
+		msg.WriteString(key + ": " + value + "\r\n")
+	}
+	writeHeader("From", from.String())
+	writeHeader("To", joinAddresses(to))
+	if len(cc) > 0 {
+		writeHeader("Cc", joinAddresses(cc))
+	}
+	writeHeader("Subject", mime.BEncoding.Encode("UTF-8", subject))
+	writeHeader("Date", now.Format(time.RFC1123Z))
+	writeHeader("Message-ID", newMessageID(from.Address, now))
+	writeHeader("MIME-Version", "1.0")
+	// RFC 3834：告知对方服务器这是自动发送的邮件，避免触发自动回复
+	writeHeader("Auto-Submitted", "auto-generated")
+
+	if e.HTML == "" {
+		writeHeader("Content-Type", "text/plain; charset=UTF-8")
+		writeHeader("Content-Transfer-Encoding", "quoted-printable")
+		msg.WriteString("\r\n")
+		if err := writeQuotedPrintable(&msg, text); err != nil {
+			return nil, err
+		}
+		return msg.Bytes(), nil
+	}
+
+	htmlBody, err := renderEmailHTML(e.HTML, event)
+	if err != nil {
+		return nil, err
+	}
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", text},
+		{"text/html; charset=UTF-8", htmlBody},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(w, part.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	writeHeader("Content-Type", "multipart/alternative; boundary="+mw.Boundary())
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+// recipients 返回 To、Cc、Bcc 的全部地址（去重），用于 RCPT TO
+func (e *EmailNotifier) recipients() ([]string, error) {
+	seen := make(map[string]bool)
+	var result []string
+	for _, list := range [][]string{e.To, e.Cc, e.Bcc} {
+		addrs, err := parseAddressList(list)
+		if err != nil {
+			return nil, fmt.Errorf("收件人地址无效: %w", err)
+		}
+		for _, a := range addrs {
+			key := strings.ToLower(a.Address)
+			if !seen[key] {
+				seen[key] = true
+				result = append(result, a.Address)
+			}
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("未配置收件人")
+	}
+	return result, nil
+}
+
+// defaultEmailBody 未配置正文模板时使用的纯文本正文
+func defaultEmailBody(event LoginEvent) string {
 	location := event.Location
 	if location == "" {
 		location = "-"
@@ -66,7 +231,7 @@ func (e *EmailNotifier) Send(event LoginEvent) error {
 	}
 	timestamp := formatShanghaiRFC3339(event.Timestamp)
 
-	body := fmt.Sprintf(`
+	return fmt.Sprintf(`
 服务器登录提醒
 -------------------
 事件类型: %s
@@ -79,8 +244,8 @@ func (e *EmailNotifier) Send(event LoginEvent) error {
 时间: %s
 日志路径: %s
 日志: %s
-`,
-		event.Type,
+%s`,
+		event.displayType(),
 		event.Hostname,
 		event.User,
 		event.IP,
@@ -89,40 +254,74 @@ func (e *EmailNotifier) Send(event LoginEvent) error {
 		location,
 		timestamp,
 		logPath,
-		message)
+		message,
+		formatExtraLines(event))
+}
 
-	msg := fmt.Sprintf("To: %s\r\n"+
-		"From: %s\r\n"+
-		"Subject: %s\r\n"+
-		"Content-Type: text/plain; charset=UTF-8\r\n"+
-		"\r\n"+
-		"%s", e.To, e.From, subject, body)
+// renderEmailText 渲染主题或纯文本正文模板，变量与 curl 相同
+func renderEmailText(text string, event LoginEvent) (string, error) {
+	tmpl, err := texttemplate.New("email").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("解析邮件模板失败: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, eventTemplateData(event)); err != nil {
+		return "", fmt.Errorf("渲染邮件模板失败: %w", err)
+	}
+	return buf.String(), nil
+}
 
-	auth := smtp.PlainAuth("", e.Username, e.Password, e.Server)
-	addr := fmt.Sprintf("%s:%d", e.Server, e.Port)
+// renderEmailHTML 渲染 HTML 正文模板，变量会按上下文自动转义，日志内容不会破坏页面结构
+func renderEmailHTML(text string, event LoginEvent) (string, error) {
+	tmpl, err := htmltemplate.New("email").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("解析 HTML 模板失败: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, eventTemplateData(event)); err != nil {
+		return "", fmt.Errorf("渲染 HTML 模板失败: %w", err)
+	}
+	return buf.String(), nil
+}
 
-	// 旧实现直接使用 smtp.SendMail，无法控制超时且不支持 465 端口的隐式 TLS，会导致命令卡住。
-	// return smtp.SendMail(addr, auth, e.From, []string{e.To}, []byte(msg))
+func parseAddressList(list []string) ([]*mail.Address, error) {
+	addrs := make([]*mail.Address, 0, len(list))
+	for _, raw := range list {
+		addr, err := mail.ParseAddress(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", raw, err)
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
 
-	debugf("notify: 准备连接 SMTP %s", addr)
-	if err := e.sendMailWithTimeout(addr, auth, []byte(msg)); err != nil {
-		return err
+// joinAddresses mail.Address.String 会对非 ASCII 的显示名做 RFC 2047 编码
+func joinAddresses(addrs []*mail.Address) string {
+	parts := make([]string, len(addrs))
+	for i, a := range addrs {
+		parts[i] = a.String()
 	}
-	debugf("notify: SMTP 发送完成")
-	return nil
+	return strings.Join(parts, ", ")
 }
 
-func (e *EmailNotifier) Test() error {
-	testEvent := LoginEvent{
-		Type:      "test",
-		User:      "test_user",
-		IP:        "127.0.0.1",
-		Timestamp: time.Now(),
-		Hostname:  "test_host",
-		Location:  "Test Location",
-		LogPath:   "-",
-	}
-	return e.Send(testEvent)
+// newMessageID 生成 <时间.随机数@发件域名>，缺少 Message-ID 的邮件容易被判为垃圾邮件
+func newMessageID(from string, now time.Time) string {
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 && i < len(from)-1 {
+		domain = from[i+1:]
+	}
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("<%d.%s@%s>", now.UnixNano(), hex.EncodeToString(b), domain)
+}
+
+func writeQuotedPrintable(w io.Writer, text string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(text)); err != nil {
+		return err
+	}
+	return qp.Close()
 }
 
 const (
@@ -134,13 +333,19 @@ func needsImplicitTLS(port int) bool {
 	return port == 465
 }
 
-func (e *EmailNotifier) sendMailWithTimeout(addr string, auth smtp.Auth, msg []byte) error {
-	if err := validateSMTPLine(e.From); err != nil {
-		return err
+func (e *EmailNotifier) sendMailWithTimeout(addr string, auth smtp.Auth, recipients []string, msg []byte) error {
+	from, err := mail.ParseAddress(e.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address: %w", err)
 	}
-	if err := validateSMTPLine(e.To); err != nil {
+	if err := validateSMTPLine(from.Address); err != nil {
 		return err
 	}
+	for _, rcpt := range recipients {
+		if err := validateSMTPLine(rcpt); err != nil {
+			return err
+		}
+	}
 
 	host, _, err := net.SplitHostPort(addr)
 	if err != nil {
@@ -197,12 +402,14 @@ func (e *EmailNotifier) sendMailWithTimeout(addr string, auth smtp.Auth, msg []b
 		}
 	}
 
-	if err := client.Mail(e.From); err != nil {
+	if err := client.Mail(from.Address); err != nil {
 		return fmt.Errorf("smtp mail from failed: %w", err)
 	}
 
-	if err := client.Rcpt(e.To); err != nil {
-		return fmt.Errorf("smtp rcpt to failed: %w", err)
+	for _, rcpt := range recipients {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("smtp rcpt to %s failed: %w", rcpt, err)
+		}
 	}
 
 	w, err := client.Data()
@@ -234,36 +441,43 @@ func validateSMTPLine(line string) error {
 }
 
 func configureEmail(input EmailInput) error {
-	// 测试邮件配置
-	fmt.Println("正在测试邮件配置...")
-	emailCfg := &EmailConfig{
-		To:     input.To,
-		From:   input.From,
-		Server: input.Server,
-		Port:   input.Port,
-		User:   input.User,
-		Pass:   input.Pass,
-	}
+	return configureChannel(ChannelConfig{
+		Name:    input.Name,
+		Enabled: true,
+		Type:    "email",
+		Email: &EmailConfig{
+			To:      input.To,
+			Cc:      input.Cc,
+			Bcc:     input.Bcc,
+			From:    input.From,
+			Server:  input.Server,
+			Port:    input.Port,
+			User:    input.User,
+			Pass:    input.Pass,
+			Subject: input.Subject,
+			Body:    input.Body,
+			HTML:    input.HTML,
+		},
+	})
+}
 
-	notifier := NewEmailNotifierFromChannel(emailCfg)
-	if err := notifier.Test(); err != nil {
-		return fmt.Errorf("邮件测试失败: %v", err)
+// formatExtraLines 返回提权目标、会话时长、自动处置、级别等可选信息行，均为空时返回空字符串
+func formatExtraLines(event LoginEvent) string {
+	var lines string
+	if event.TargetUser != "" {
+		lines += "目标用户: " + event.TargetUser + "\n"
 	}
-	fmt.Println("✓ 测试成功")
-
-	// 如果未指定 name，生成默认名称
-	name := input.Name
-	if name == "" {
-		name = generateChannelName("email")
+	if event.Command != "" {
+		lines += "命令: " + event.Command + "\n"
 	}
-
-	// 创建渠道配置
-	channel := ChannelConfig{
-		Name:    name,
-		Enabled: true,
-		Type:    "email",
-		Email:   emailCfg,
+	if event.Duration > 0 {
+		lines += "时长: " + formatSessionDuration(event.Duration) + "\n"
 	}
-
-	return addOrUpdateChannel(channel)
+	if event.Action != "" {
+		lines += "处置: " + event.Action + "\n"
+	}
+	if event.Severity != "" {
+		lines += "级别: " + event.Severity + "\n"
+	}
+	return lines
 }
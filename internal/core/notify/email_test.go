@@ -1,6 +1,19 @@
 package notify
 
-import "testing"
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestNeedsImplicitTLS(t *testing.T) {
 	tests := []struct {
@@ -29,3 +42,188 @@ func TestValidateSMTPLine(t *testing.T) {
 		t.Fatalf("expected error for newline")
 	}
 }
+
+func TestAddressListUnmarshal(t *testing.T) {
+	var cfg EmailConfig
+	if err := json.Unmarshal([]byte(`{"to":"a@example.com, b@example.com","cc":["c@example.com"]}`), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if want := (AddressList{"a@example.com", "b@example.com"}); !reflect.DeepEqual(cfg.To, want) {
+		t.Fatalf("to = %v want %v", cfg.To, want)
+	}
+	if want := (AddressList{"c@example.com"}); !reflect.DeepEqual(cfg.Cc, want) {
+		t.Fatalf("cc = %v want %v", cfg.Cc, want)
+	}
+	if err := json.Unmarshal([]byte(`{"to":1}`), &cfg); err == nil {
+		t.Fatal("expected error for numeric address list")
+	}
+}
+
+func parseTestMessage(t *testing.T, raw []byte) *mail.Message {
+	t.Helper()
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("read message: %v\n%s", err, raw)
+	}
+	return msg
+}
+
+func TestEmailBuildMessageDefault(t *testing.T) {
+	e := &EmailNotifier{
+		To:   []string{"运维 <ops@example.com>"},
+		Bcc:  []string{"audit@example.com"},
+		From: "SSHield <ssh@example.com>",
+	}
+	now := time.Date(2025, 3, 3, 10, 0, 1, 0, time.UTC)
+	raw, err := e.buildMessage(testLoginEvent(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := parseTestMessage(t, raw)
+
+	for _, line := range strings.Split(string(raw[:bytes.Index(raw, []byte("\r\n\r\n"))]), "\r\n") {
+		for _, r := range line {
+			if r > 127 {
+				t.Fatalf("header contains non-ASCII: %q", line)
+			}
+		}
+	}
+	if strings.Contains(string(raw), "audit@example.com") {
+		t.Fatal("bcc must not appear in the message")
+	}
+
+	dec := new(mime.WordDecoder)
+	subject, err := dec.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject != "服务器登录提醒 - login_success" {
+		t.Fatalf("subject = %q", subject)
+	}
+	to, err := msg.Header.AddressList("To")
+	if err != nil || len(to) != 1 || to[0].Name != "运维" || to[0].Address != "ops@example.com" {
+		t.Fatalf("to = %v, %v", to, err)
+	}
+	if got := msg.Header.Get("Date"); got != "Mon, 03 Mar 2025 10:00:01 +0000" {
+		t.Fatalf("date = %q", got)
+	}
+	if id := msg.Header.Get("Message-ID"); !strings.HasPrefix(id, "<") || !strings.HasSuffix(id, "@example.com>") {
+		t.Fatalf("message-id = %q", id)
+	}
+	if got := msg.Header.Get("Content-Transfer-Encoding"); got != "quoted-printable" {
+		t.Fatalf("content-transfer-encoding = %q", got)
+	}
+	body, _ := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	if !strings.Contains(string(body), "来源IP: 203.0.113.9") {
+		t.Fatalf("body = %s", body)
+	}
+}
+
+func TestEmailBuildMessageTemplates(t *testing.T) {
+	e := &EmailNotifier{
+		To:      []string{"a@example.com", "b@example.com"},
+		Cc:      []string{"c@example.com"},
+		From:    "ssh@example.com",
+		Subject: "[{{.Severity}}] {{.Hostname}}\n{{.User}}@{{.IP}}",
+		Body:    "用户 {{.User}} 从 {{.IP}} 登录",
+		HTML:    "<p>用户 <b>{{.User}}</b></p><pre>{{.Message}}</pre>",
+	}
+	event := testLoginEvent()
+	event.Message = "<script>alert(1)</script>"
+	raw, err := e.buildMessage(event, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := parseTestMessage(t, raw)
+
+	if got := msg.Header.Get("Subject"); got != "[medium] web-1 root@203.0.113.9" {
+		t.Fatalf("subject = %q", got)
+	}
+	if got := msg.Header.Get("To"); got != "<a@example.com>, <b@example.com>" {
+		t.Fatalf("to = %q", got)
+	}
+	if got := msg.Header.Get("Cc"); got != "<c@example.com>" {
+		t.Fatalf("cc = %q", got)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("content-type = %q, %v", msg.Header.Get("Content-Type"), err)
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	var parts []string
+	var contents []string
+	for {
+		p, err := mr.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(quotedprintable.NewReader(p))
+		parts = append(parts, p.Header.Get("Content-Type"))
+		contents = append(contents, string(data))
+	}
+	if want := []string{"text/plain; charset=UTF-8", "text/html; charset=UTF-8"}; !reflect.DeepEqual(parts, want) {
+		t.Fatalf("parts = %v want %v", parts, want)
+	}
+	if contents[0] != "用户 root 从 203.0.113.9 登录" {
+		t.Fatalf("text = %q", contents[0])
+	}
+	if want := "<p>用户 <b>root</b></p><pre>&lt;script&gt;alert(1)&lt;/script&gt;</pre>"; contents[1] != want {
+		t.Fatalf("html = %q want %q", contents[1], want)
+	}
+}
+
+func TestEmailRecipients(t *testing.T) {
+	e := &EmailNotifier{
+		To:  []string{"Ops <ops@example.com>", "b@example.com"},
+		Cc:  []string{"OPS@example.com"},
+		Bcc: []string{"audit@example.com"},
+	}
+	got, err := e.recipients()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ops@example.com", "b@example.com", "audit@example.com"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("recipients = %v want %v", got, want)
+	}
+
+	e.To = []string{"not an address"}
+	if _, err := e.recipients(); err == nil {
+		t.Fatal("expected error for invalid address")
+	}
+}
+
+func TestValidateEmailChannelTemplates(t *testing.T) {
+	ch := &ChannelConfig{Name: "mail", Type: "email", Email: &EmailConfig{
+		To:      AddressList{"ops@example.com"},
+		Cc:      AddressList{"bad"},
+		From:    "ssh@example.com",
+		Server:  "smtp.example.com",
+		Port:    587,
+		User:    "u",
+		Pass:    "p",
+		Subject: "{{.User",
+		HTML:    "<p>{{if .User}}</p>",
+	}}
+	var verr *ValidationError
+	if err := ValidateChannelConfig(ch); !errors.As(err, &verr) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	var fields []string
+	for _, e := range verr.Errors {
+		fields = append(fields, e.Field)
+	}
+	if want := []string{"email.cc", "email.subject", "email.html"}; !reflect.DeepEqual(fields, want) {
+		t.Fatalf("fields = %v want %v", fields, want)
+	}
+
+	ch.Email.Cc = nil
+	ch.Email.Subject = "{{.Hostname}}"
+	ch.Email.HTML = "<p>{{.User}}</p>"
+	if err := ValidateChannelConfig(ch); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
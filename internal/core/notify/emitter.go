@@ -0,0 +1,65 @@
+package notify
+
+import (
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// EmitterOptions 控制外部事件的通知行为
+type EmitterOptions struct {
+	Notify     bool           // 是否发送通知（否则仅输出）
+	DisplayLoc *time.Location // 控制台显示时区
+	FailLimit  int            // 每 IP 失败通知限制数量，0 表示不限制
+	FailWindow time.Duration  // 失败限制时间窗口
+}
+
+// Emitter 供其他子系统（如蜜罐）将事件送入与 watch 相同的去重、限流、发送与输出流程
+type Emitter struct {
+	mu       sync.Mutex
+	notify   bool
+	loc      *time.Location
+	filter   *notifyFilter
+	severity severityPolicy
+}
+
+// NewEmitter 创建事件发射器
+func NewEmitter(opts EmitterOptions) *Emitter {
+	return &Emitter{
+		notify:   opts.Notify,
+		loc:      normalizeLocation(opts.DisplayLoc),
+		filter:   newNotifyFilter(NotifyOnAll, opts.FailLimit, opts.FailWindow),
+		severity: defaultSeverityPolicy(),
+	}
+}
+
+// Emit 补全主机信息后处理事件，可并发调用
+func (e *Emitter) Emit(event *LoginEvent) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if event.Hostname == "" {
+		event.Hostname, _ = os.Hostname()
+	}
+	if event.HostIP == "" {
+		event.HostIP = getHostIP()
+	}
+	if event.Location == "" && event.IP != "" {
+		event.Location = LookupIPLocation(event.IP)
+	}
+	if event.Severity == "" {
+		event.Severity = eventSeverity(event, e.severity)
+	}
+
+	e.mu.Lock()
+	shouldSend := e.notify && e.filter.shouldNotify(event)
+	e.mu.Unlock()
+
+	if shouldSend {
+		if err := dispatchEvent(event); err != nil {
+			log.Printf("发送通知失败: %v", err)
+		}
+	}
+	printEventSummary(*event, e.loc)
+}
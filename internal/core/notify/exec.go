@@ -0,0 +1,158 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultExecTimeout       = 30 * time.Second
+	defaultExecMaxConcurrent = 4
+	// 保留的 stderr 上限，超出部分丢弃
+	execStderrLimit = 64 << 10
+	// 超时终止后等待子进程关闭输出的时间
+	execWaitDelay = time.Second
+)
+
+// execSlots 每个命令的并发槽位，通知器每次发送时重新创建，需要在包级别共享
+var (
+	execSlotsMu sync.Mutex
+	execSlots   = make(map[string]chan struct{})
+)
+
+func execSlot(key string, size int) chan struct{} {
+	execSlotsMu.Lock()
+	defer execSlotsMu.Unlock()
+	slot, ok := execSlots[key]
+	if !ok || cap(slot) != size {
+		slot = make(chan struct{}, size)
+		execSlots[key] = slot
+	}
+	return slot
+}
+
+// ExecNotifier 运行外部命令，事件以 JSON 写入标准输入，同时以 SSHIELD_* 环境变量传递
+type ExecNotifier struct {
+	command       string
+	args          []string
+	timeout       time.Duration
+	maxConcurrent int
+}
+
+// NewExecNotifier 创建命令通知器
+func NewExecNotifier(cfg *ExecConfig) *ExecNotifier {
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultExecTimeout
+	}
+	maxConcurrent := cfg.MaxConcurrent
+	if maxConcurrent <= 0 {
+		maxConcurrent = defaultExecMaxConcurrent
+	}
+	return &ExecNotifier{
+		command:       cfg.Command,
+		args:          cfg.Args,
+		timeout:       timeout,
+		maxConcurrent: maxConcurrent,
+	}
+}
+
+// Send 运行命令并等待退出，超时或非零退出码返回错误；等待并发槽位的时间计入超时
+func (e *ExecNotifier) Send(event LoginEvent) error {
+	input, err := json.Marshal(newEventRecord(event))
+	if err != nil {
+		return fmt.Errorf("编码事件失败: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+
+	slot := execSlot(e.command+"\x00"+strings.Join(e.args, "\x00"), e.maxConcurrent)
+	select {
+	case slot <- struct{}{}:
+		defer func() { <-slot }()
+	case <-ctx.Done():
+		return fmt.Errorf("等待执行 %s 超时：已有 %d 个实例在运行", e.command, e.maxConcurrent)
+	}
+
+	cmd := exec.CommandContext(ctx, e.command, e.args...)
+	cmd.Stdin = bytes.NewReader(append(input, '\n'))
+	cmd.Env = append(os.Environ(), execEnv(event)...)
+	cmd.WaitDelay = execWaitDelay
+	stderr := &limitedBuffer{limit: execStderrLimit}
+	cmd.Stderr = stderr
+
+	err = cmd.Run()
+	if stderr.Len() > 0 {
+		debugf("notify: exec %s stderr: %s", e.command, strings.TrimSpace(stderr.String()))
+	}
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("执行 %s 超时（%v）", e.command, e.timeout)
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("执行 %s 失败，退出码 %d", e.command, exitErr.ExitCode())
+		}
+		return fmt.Errorf("执行 %s 失败: %w", e.command, err)
+	}
+	return nil
+}
+
+// Test 以测试事件运行一次命令
+func (e *ExecNotifier) Test() error {
+	if err := e.Send(channelTestEvent()); err != nil {
+		return fmt.Errorf("exec 测试失败: %w", err)
+	}
+	return nil
+}
+
+// execEnv 将事件字段转换为 SSHIELD_<字段名大写> 环境变量，字段与标准输入中的 JSON 一致，空字段不设置
+func execEnv(event LoginEvent) []string {
+	data, _ := json.Marshal(newEventRecord(event))
+	var fields map[string]any
+	_ = json.Unmarshal(data, &fields)
+
+	env := make([]string, 0, len(fields))
+	for k, v := range fields {
+		var value string
+		switch v := v.(type) {
+		case string:
+			value = v
+		case float64:
+			value = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			continue
+		}
+		env = append(env, "SSHIELD_"+strings.ToUpper(k)+"="+value)
+	}
+	sort.Strings(env)
+	return env
+}
+
+// limitedBuffer 只保留前 limit 字节的写入缓冲，超出部分丢弃但不报错，避免子进程因管道写满而阻塞
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); room > 0 {
+		if len(p) > room {
+			b.Buffer.Write(p[:room])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}
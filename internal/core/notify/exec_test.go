@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func writeExecScript(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "hook.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExecNotifierStdinAndEnv(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	script := writeExecScript(t, `cat > "$1"; echo "$SSHIELD_USER $SSHIELD_IP $SSHIELD_PORT $SSHIELD_TYPE" >> "$1"`)
+
+	event := testLoginEvent()
+	event.Port = 52214
+	if err := NewExecNotifier(&ExecConfig{Command: script, Args: []string{out}}).Send(event); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdin, env, _ := strings.Cut(string(data), "\n")
+	var record eventRecord
+	if err := json.Unmarshal([]byte(stdin), &record); err != nil {
+		t.Fatalf("stdin is not JSON: %v", err)
+	}
+	if record.User != "root" || record.Port != 52214 {
+		t.Fatalf("unexpected stdin record: %+v", record)
+	}
+	if strings.TrimSpace(env) != "root 203.0.113.9 52214 login_success" {
+		t.Fatalf("unexpected env: %q", env)
+	}
+}
+
+func TestExecNotifierExitCode(t *testing.T) {
+	script := writeExecScript(t, "echo boom >&2; exit 3")
+	err := NewExecNotifier(&ExecConfig{Command: script}).Send(testLoginEvent())
+	if err == nil || !strings.Contains(err.Error(), "退出码 3") {
+		t.Fatalf("expected exit code error, got %v", err)
+	}
+}
+
+func TestExecNotifierTimeout(t *testing.T) {
+	script := writeExecScript(t, "exec sleep 10")
+	n := NewExecNotifier(&ExecConfig{Command: script})
+	n.timeout = 200 * time.Millisecond
+
+	start := time.Now()
+	err := n.Send(testLoginEvent())
+	if err == nil || !strings.Contains(err.Error(), "超时") {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("timeout not enforced, took %v", elapsed)
+	}
+}
+
+func TestExecNotifierConcurrencyLimit(t *testing.T) {
+	dir := t.TempDir()
+	// 通过 mkdir 的原子性检测是否有两个实例同时运行
+	script := writeExecScript(t, `mkdir "$1/lock" 2>/dev/null || { touch "$1/overlap"; exit 0; }; sleep 0.1; rmdir "$1/lock"`)
+	cfg := &ExecConfig{Command: script, Args: []string{dir}, MaxConcurrent: 1}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := NewExecNotifier(cfg).Send(testLoginEvent()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if _, err := os.Stat(filepath.Join(dir, "overlap")); err == nil {
+		t.Fatal("commands ran concurrently despite max_concurrent=1")
+	}
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	b := &limitedBuffer{limit: 4}
+	if n, err := b.Write([]byte("abcdef")); n != 6 || err != nil {
+		t.Fatalf("Write = %d, %v", n, err)
+	}
+	b.Write([]byte("gh"))
+	if b.String() != "abcd" {
+		t.Fatalf("unexpected buffer: %q", b.String())
+	}
+}
+
+func TestValidateExecChannel(t *testing.T) {
+	if err := ValidateChannelConfig(&ChannelConfig{Type: "exec", Exec: &ExecConfig{Command: "/usr/local/bin/hook"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, cfg := range []*ExecConfig{nil, {Command: "hook.sh"}, {Command: "/bin/true", Timeout: -1}} {
+		if err := ValidateChannelConfig(&ChannelConfig{Type: "exec", Exec: cfg}); err == nil {
+			t.Fatalf("expected validation error for %+v", cfg)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	defaultFileSinkMaxSizeMB  = 100
+	defaultFileSinkMaxBackups = 5
+)
+
+// fileSinkMu 串行化文件渠道的写入与轮转，通知器每次发送时重新创建，锁需要在包级别
+var fileSinkMu sync.Mutex
+
+// FileSinkNotifier 将事件以 JSON Lines 追加写入本地文件，超过大小后轮转
+type FileSinkNotifier struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+}
+
+// NewFileSinkNotifier 创建文件渠道通知器
+func NewFileSinkNotifier(cfg *FileSinkConfig) *FileSinkNotifier {
+	maxSizeMB := cfg.MaxSizeMB
+	if maxSizeMB <= 0 {
+		maxSizeMB = defaultFileSinkMaxSizeMB
+	}
+	maxBackups := cfg.MaxBackups
+	if maxBackups <= 0 {
+		maxBackups = defaultFileSinkMaxBackups
+	}
+	return &FileSinkNotifier{
+		path:       cfg.Path,
+		maxSize:    int64(maxSizeMB) << 20,
+		maxBackups: maxBackups,
+	}
+}
+
+// Send 追加一行 JSON，写入前检查是否需要轮转
+func (f *FileSinkNotifier) Send(event LoginEvent) error {
+	line, err := json.Marshal(newEventRecord(event))
+	if err != nil {
+		return fmt.Errorf("编码事件失败: %w", err)
+	}
+	line = append(line, '\n')
+
+	fileSinkMu.Lock()
+	defer fileSinkMu.Unlock()
+
+	if info, err := os.Stat(f.path); err == nil && info.Size() > 0 && info.Size()+int64(len(line)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return fmt.Errorf("轮转文件失败: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	// 事件中包含用户名与来源 IP，仅允许属主读写
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("打开文件失败: %w", err)
+	}
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	return file.Close()
+}
+
+// rotate 将 path 重命名为 path.1，已有的 path.N 依次后移，超出 maxBackups 的删除
+func (f *FileSinkNotifier) rotate() error {
+	oldest := fmt.Sprintf("%s.%d", f.path, f.maxBackups)
+	if err := os.Remove(oldest); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := f.maxBackups - 1; i >= 1; i-- {
+		src := fmt.Sprintf("%s.%d", f.path, i)
+		if err := os.Rename(src, fmt.Sprintf("%s.%d", f.path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(f.path, f.path+".1")
+}
+
+// Test 写入一条测试事件
+func (f *FileSinkNotifier) Test() error {
+	if err := f.Send(channelTestEvent()); err != nil {
+		return fmt.Errorf("file 测试失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileSinkRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	n := NewFileSinkNotifier(&FileSinkConfig{Path: path, MaxBackups: 2})
+	n.maxSize = 600
+
+	for i := 0; i < 6; i++ {
+		if err := n.Send(testLoginEvent()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var record eventRecord
+	line, _, _ := strings.Cut(string(data), "\n")
+	if err := json.Unmarshal([]byte(line), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.Type != EventLoginSuccess || record.User != "root" || record.Time != "2025-03-03T10:00:00Z" {
+		t.Fatalf("unexpected record: %+v", record)
+	}
+
+	if info, _ := os.Stat(path); info.Size() > n.maxSize || info.Mode().Perm() != 0o600 {
+		t.Fatalf("unexpected file: size %d mode %v", info.Size(), info.Mode())
+	}
+	for _, backup := range []string{path + ".1", path + ".2"} {
+		if _, err := os.Stat(backup); err != nil {
+			t.Fatalf("expected backup %s: %v", backup, err)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("backups beyond max should be removed, got %v", err)
+	}
+}
+
+func TestValidateFileSinkChannel(t *testing.T) {
+	if err := ValidateChannelConfig(&ChannelConfig{Type: "file", File: &FileSinkConfig{Path: "/var/log/sshield/events.jsonl"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, cfg := range []*FileSinkConfig{nil, {Path: "events.jsonl"}, {Path: "/tmp/e.jsonl", MaxSizeMB: -1}} {
+		if err := ValidateChannelConfig(&ChannelConfig{Type: "file", File: cfg}); err == nil {
+			t.Fatalf("expected validation error for %+v", cfg)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package notify
+
+// fileWatcher 监听文件或目录的变化（Linux 使用 inotify，其他平台不可用时由调用方轮询）
+type fileWatcher interface {
+	// Add 监听文件或目录（目录监听其中条目的变化）
+	Add(path string) error
+	// Events 输出发生变化的路径，监听器关闭后通道关闭
+	Events() <-chan string
+	Close() error
+}
@@ -0,0 +1,143 @@
+//go:build linux
+
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// 监听目录内条目的创建、写入、删除与重命名（编辑器常以重命名方式保存）
+const inotifyMask = unix.IN_CLOSE_WRITE | unix.IN_MODIFY | unix.IN_CREATE | unix.IN_DELETE |
+	unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_ATTRIB | unix.IN_DELETE_SELF | unix.IN_MOVE_SELF
+
+// inotifyWatcher 基于 inotify 的文件监听
+type inotifyWatcher struct {
+	fd     int
+	mu     sync.Mutex
+	paths  map[int]string // watch descriptor -> 路径
+	events chan string
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// newFileWatcher 创建文件监听器，返回的通道输出发生变化的完整路径
+func newFileWatcher() (fileWatcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_NONBLOCK | unix.IN_CLOEXEC)
+	if err != nil {
+		return nil, fmt.Errorf("初始化 inotify 失败: %w", err)
+	}
+	w := &inotifyWatcher{
+		fd:     fd,
+		paths:  make(map[int]string),
+		events: make(chan string, 64),
+		done:   make(chan struct{}),
+	}
+	w.wg.Add(1)
+	go w.readLoop()
+	return w, nil
+}
+
+// Add 监听文件或目录，重复添加同一路径无副作用
+func (w *inotifyWatcher) Add(path string) error {
+	wd, err := unix.InotifyAddWatch(w.fd, path, inotifyMask)
+	if err != nil {
+		return fmt.Errorf("监听 %s 失败: %w", path, err)
+	}
+	w.mu.Lock()
+	w.paths[wd] = path
+	w.mu.Unlock()
+	return nil
+}
+
+func (w *inotifyWatcher) Events() <-chan string {
+	return w.events
+}
+
+func (w *inotifyWatcher) Close() error {
+	close(w.done)
+	w.wg.Wait()
+	return unix.Close(w.fd)
+}
+
+func (w *inotifyWatcher) readLoop() {
+	defer w.wg.Done()
+	defer close(w.events)
+
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	fds := []unix.PollFd{{Fd: int32(w.fd), Events: unix.POLLIN}}
+	for {
+		select {
+		case <-w.done:
+			return
+		default:
+		}
+
+		// 使用带超时的 poll，保证 Close 后能及时退出
+		n, err := unix.Poll(fds, 500)
+		if err != nil {
+			if errors.Is(err, unix.EINTR) {
+				continue
+			}
+			debugf("notify: inotify poll 失败: %v", err)
+			return
+		}
+		if n == 0 {
+			continue
+		}
+
+		n, err = unix.Read(w.fd, buf)
+		if err != nil {
+			if errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EINTR) {
+				continue
+			}
+			debugf("notify: inotify 读取失败: %v", err)
+			return
+		}
+		w.dispatch(buf[:n])
+	}
+}
+
+func (w *inotifyWatcher) dispatch(buf []byte) {
+	for offset := 0; offset+unix.SizeofInotifyEvent <= len(buf); {
+		raw := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+		nameBytes := buf[offset+unix.SizeofInotifyEvent : offset+unix.SizeofInotifyEvent+int(raw.Len)]
+		offset += unix.SizeofInotifyEvent + int(raw.Len)
+
+		w.mu.Lock()
+		path, ok := w.paths[int(raw.Wd)]
+		if raw.Mask&unix.IN_IGNORED != 0 {
+			// 被监听的路径已删除，内核自动移除了监听
+			delete(w.paths, int(raw.Wd))
+		}
+		w.mu.Unlock()
+		if !ok {
+			continue
+		}
+
+		if name := trimNull(nameBytes); name != "" {
+			path = filepath.Join(path, name)
+		}
+		select {
+		case w.events <- path:
+		case <-w.done:
+			return
+		default:
+			// 通道已满时丢弃，调用方会在处理时重新检查全部文件
+		}
+	}
+}
+
+func trimNull(b []byte) string {
+	for i, c := range b {
+		if c == 0 {
+			return string(b[:i])
+		}
+	}
+	return string(b)
+}
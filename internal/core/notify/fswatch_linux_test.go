@@ -0,0 +1,36 @@
+//go:build linux
+
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestInotifyWatcher(t *testing.T) {
+	dir := t.TempDir()
+	w, err := newFileWatcher()
+	if err != nil {
+		t.Skipf("inotify unavailable: %v", err)
+	}
+	defer w.Close()
+
+	if err := w.Add(dir); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(dir, "authorized_keys")
+	if err := os.WriteFile(target, []byte("x"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case path := <-w.Events():
+		if path != target {
+			t.Fatalf("event path = %s, want %s", path, target)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("timed out waiting for inotify event")
+	}
+}
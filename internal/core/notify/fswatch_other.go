@@ -0,0 +1,10 @@
+//go:build !linux
+
+package notify
+
+import "errors"
+
+// newFileWatcher 非 Linux 平台不支持 inotify，调用方退化为定时轮询
+func newFileWatcher() (fileWatcher, error) {
+	return nil, errors.New("当前平台不支持 inotify")
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -14,9 +15,12 @@ import (
 
 // IPLookupResult IP 查询结果
 type IPLookupResult struct {
-	Country string
-	Region  string
-	City    string
+	Country     string
+	CountryCode string // ISO 3166-1 国家代码
+	Region      string
+	City        string
+	ASN         uint   // 自治系统号
+	Org         string // 自治系统所属组织
 }
 
 func (r *IPLookupResult) String() string {
@@ -30,10 +34,24 @@ func (r *IPLookupResult) String() string {
 	if r.City != "" {
 		parts = append(parts, r.City)
 	}
-	if len(parts) == 0 {
-		return ""
+	location := strings.Join(parts, ", ")
+
+	var network string
+	switch {
+	case r.ASN > 0 && r.Org != "":
+		network = fmt.Sprintf("AS%d %s", r.ASN, r.Org)
+	case r.ASN > 0:
+		network = fmt.Sprintf("AS%d", r.ASN)
+	case r.Org != "":
+		network = r.Org
 	}
-	return strings.Join(parts, ", ")
+	if network == "" {
+		return location
+	}
+	if location == "" {
+		return network
+	}
+	return fmt.Sprintf("%s (%s)", location, network)
 }
 
 // IPLookupProvider IP 查询提供者
@@ -77,15 +95,20 @@ func (p *ipinfoProvider) Lookup(ctx context.Context, ip string) (*IPLookupResult
 		Country string `json:"country"`
 		Region  string `json:"region"`
 		City    string `json:"city"`
+		Org     string `json:"org"`
 	}
 	if err := json.Unmarshal(body, &data); err != nil {
 		return nil, err
 	}
 
+	asn, org := parseASNOrg(data.Org)
 	return &IPLookupResult{
-		Country: data.Country,
-		Region:  data.Region,
-		City:    data.City,
+		Country:     data.Country,
+		CountryCode: data.Country,
+		Region:      data.Region,
+		City:        data.City,
+		ASN:         asn,
+		Org:         org,
 	}, nil
 }
 
@@ -99,7 +122,7 @@ func (p *ipApiProvider) Name() string {
 }
 
 func (p *ipApiProvider) Lookup(ctx context.Context, ip string) (*IPLookupResult, error) {
-	url := fmt.Sprintf("http://ip-api.com/json/%s?fields=status,country,regionName,city", ip)
+	url := fmt.Sprintf("http://ip-api.com/json/%s?fields=status,country,countryCode,regionName,city,as", ip)
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
@@ -121,10 +144,12 @@ func (p *ipApiProvider) Lookup(ctx context.Context, ip string) (*IPLookupResult,
 	}
 
 	var data struct {
-		Status     string `json:"status"`
-		Country    string `json:"country"`
-		RegionName string `json:"regionName"`
-		City       string `json:"city"`
+		Status      string `json:"status"`
+		Country     string `json:"country"`
+		CountryCode string `json:"countryCode"`
+		RegionName  string `json:"regionName"`
+		City        string `json:"city"`
+		AS          string `json:"as"`
 	}
 	if err := json.Unmarshal(body, &data); err != nil {
 		return nil, err
@@ -134,13 +159,31 @@ func (p *ipApiProvider) Lookup(ctx context.Context, ip string) (*IPLookupResult,
 		return nil, fmt.Errorf("lookup failed")
 	}
 
+	asn, org := parseASNOrg(data.AS)
 	return &IPLookupResult{
-		Country: data.Country,
-		Region:  data.RegionName,
-		City:    data.City,
+		Country:     data.Country,
+		CountryCode: data.CountryCode,
+		Region:      data.RegionName,
+		City:        data.City,
+		ASN:         asn,
+		Org:         org,
 	}, nil
 }
 
+// parseASNOrg 解析 "AS15169 Google LLC" 形式的自治系统描述
+func parseASNOrg(s string) (uint, string) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "AS") {
+		return 0, s
+	}
+	numStr, org, _ := strings.Cut(s[2:], " ")
+	num, err := strconv.ParseUint(numStr, 10, 32)
+	if err != nil {
+		return 0, s
+	}
+	return uint(num), strings.TrimSpace(org)
+}
+
 // IPLookup IP 地理位置查询器
 type IPLookup struct {
 	providers []IPLookupProvider
@@ -151,31 +194,75 @@ type IPLookup struct {
 var defaultIPLookup *IPLookup
 var ipLookupOnce sync.Once
 
-// GetIPLookup 获取全局 IP 查询器
+// GetIPLookup 获取全局 IP 查询器，提供者链由配置文件中的 ip_lookup 决定
 func GetIPLookup() *IPLookup {
 	ipLookupOnce.Do(func() {
-		client := &http.Client{Timeout: 5 * time.Second}
-		defaultIPLookup = &IPLookup{
-			providers: []IPLookupProvider{
-				&ipinfoProvider{client: client},
-				&ipApiProvider{client: client},
-			},
-			timeout: 5 * time.Second,
+		var lookupCfg *IPLookupConfig
+		if cfg, err := loadConfig(); err == nil && cfg != nil {
+			lookupCfg = cfg.IPLookup
 		}
+		defaultIPLookup = newIPLookup(lookupCfg)
 	})
 	return defaultIPLookup
 }
 
+// newIPLookup 根据配置构建提供者链
+func newIPLookup(cfg *IPLookupConfig) *IPLookup {
+	l := &IPLookup{timeout: 5 * time.Second}
+
+	mode := ipLookupModeRemote
+	if cfg != nil {
+		mode = cfg.effectiveMode()
+	}
+
+	var local IPLookupProvider
+	if mode != ipLookupModeRemote {
+		local = newMMDBProvider(cfg.CityDB, cfg.ASNDB, cfg.Reload, l.resetCache)
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	remote := []IPLookupProvider{
+		&ipinfoProvider{client: client},
+		&ipApiProvider{client: client},
+	}
+
+	switch mode {
+	case ipLookupModeLocal:
+		l.providers = []IPLookupProvider{local}
+	case ipLookupModeLocalFirst:
+		l.providers = append([]IPLookupProvider{local}, remote...)
+	default:
+		l.providers = remote
+	}
+	return l
+}
+
+// resetCache 清空查询缓存（本地数据库重新加载后调用）
+func (l *IPLookup) resetCache() {
+	l.cache.Range(func(key, _ any) bool {
+		l.cache.Delete(key)
+		return true
+	})
+}
+
 // Lookup 查询 IP 地理位置，带缓存和多提供者回退
 func (l *IPLookup) Lookup(ip string) string {
 	// 跳过内网 IP
 	if isPrivateIP(ip) {
 		return "内网"
 	}
+	return l.LookupResult(ip).String()
+}
+
+// LookupResult 查询 IP 的结构化信息，内网 IP 或查询失败时返回空结果
+func (l *IPLookup) LookupResult(ip string) *IPLookupResult {
+	if isPrivateIP(ip) {
+		return &IPLookupResult{}
+	}
 
 	// 检查缓存
 	if cached, ok := l.cache.Load(ip); ok {
-		return cached.(*IPLookupResult).String()
+		return cached.(*IPLookupResult)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), l.timeout)
@@ -191,13 +278,14 @@ func (l *IPLookup) Lookup(ip string) string {
 
 		if result != nil && result.String() != "" {
 			l.cache.Store(ip, result)
-			return result.String()
+			return result
 		}
 	}
 
 	// 所有提供者都失败，缓存空结果避免重复查询
-	l.cache.Store(ip, &IPLookupResult{})
-	return ""
+	empty := &IPLookupResult{}
+	l.cache.Store(ip, empty)
+	return empty
 }
 
 // isPrivateIP 判断是否为内网 IP
@@ -238,3 +326,42 @@ func isPrivateIP(ipStr string) bool {
 func LookupIPLocation(ip string) string {
 	return GetIPLookup().Lookup(ip)
 }
+
+// configureIPLookup 保存 IP 查询配置
+func configureIPLookup(lookupCfg IPLookupConfig) error {
+	if err := ValidateIPLookupConfig(&lookupCfg); err != nil {
+		return err
+	}
+
+	for _, path := range []string{lookupCfg.CityDB, lookupCfg.ASNDB} {
+		if path == "" {
+			continue
+		}
+		f := &mmdbFile{path: path}
+		if _, err := f.open(); err != nil {
+			return err
+		}
+		_ = f.reader.Close()
+	}
+
+	cm := NewConfigManager()
+	cfg, err := cm.LoadConfig()
+	if err != nil {
+		if err != ErrConfigNotFound {
+			return fmt.Errorf("加载配置失败: %w", err)
+		}
+		cfg = &Config{}
+	}
+
+	cfg.IPLookup = &lookupCfg
+	if lookupCfg.effectiveMode() == ipLookupModeRemote && lookupCfg.CityDB == "" && lookupCfg.ASNDB == "" {
+		// 默认行为无需写入配置
+		cfg.IPLookup = nil
+	}
+
+	if err := saveConfigWithBackup(cm, cfg); err != nil {
+		return err
+	}
+	printIPLookupSummary(&lookupCfg)
+	return nil
+}
@@ -0,0 +1,193 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+const (
+	ipLookupModeLocal      = "local"
+	ipLookupModeLocalFirst = "local-first"
+	ipLookupModeRemote     = "remote"
+)
+
+// mmdb 文件变更检查间隔，避免每次查询都 stat
+const mmdbReloadCheckInterval = 30 * time.Second
+
+// mmdb 名称优先使用的语言
+const mmdbLanguage = "en"
+
+// mmdbCityRecord GeoLite2-City / DB-IP City Lite 记录（Country 库同样适用）
+type mmdbCityRecord struct {
+	Country struct {
+		IsoCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+// mmdbASNRecord GeoLite2-ASN / DB-IP ASN Lite 记录
+type mmdbASNRecord struct {
+	Number uint   `maxminddb:"autonomous_system_number"`
+	Org    string `maxminddb:"autonomous_system_organization"`
+}
+
+// mmdbFile 单个 mmdb 文件，支持文件变更后重新加载
+type mmdbFile struct {
+	path    string
+	reader  *maxminddb.Reader
+	modTime time.Time
+	size    int64
+}
+
+// open 打开（或在文件变更后重新打开）数据库，返回是否发生了重新加载
+func (f *mmdbFile) open() (bool, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return false, err
+	}
+	if f.reader != nil && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return false, nil
+	}
+
+	reader, err := maxminddb.Open(f.path)
+	if err != nil {
+		return false, fmt.Errorf("打开 mmdb 失败 %s: %w", f.path, err)
+	}
+	reloaded := f.reader != nil
+	if f.reader != nil {
+		_ = f.reader.Close()
+	}
+	f.reader = reader
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+	return reloaded, nil
+}
+
+// mmdbProvider 读取本地 MaxMind/DB-IP mmdb 文件的提供者
+type mmdbProvider struct {
+	city      *mmdbFile
+	asn       *mmdbFile
+	reload    bool
+	onReload  func()
+	mu        sync.RWMutex
+	lastCheck time.Time
+}
+
+func newMMDBProvider(cityPath, asnPath string, reload bool, onReload func()) *mmdbProvider {
+	p := &mmdbProvider{reload: reload, onReload: onReload}
+	if cityPath != "" {
+		p.city = &mmdbFile{path: cityPath}
+	}
+	if asnPath != "" {
+		p.asn = &mmdbFile{path: asnPath}
+	}
+	return p
+}
+
+func (p *mmdbProvider) Name() string {
+	return "mmdb"
+}
+
+// ensureOpen 首次使用时打开数据库，并按间隔检查文件是否被替换
+func (p *mmdbProvider) ensureOpen() {
+	p.mu.RLock()
+	opened := !p.lastCheck.IsZero()
+	due := p.reload && time.Since(p.lastCheck) >= mmdbReloadCheckInterval
+	p.mu.RUnlock()
+	if opened && !due {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.lastCheck.IsZero() && !(p.reload && time.Since(p.lastCheck) >= mmdbReloadCheckInterval) {
+		return
+	}
+	p.lastCheck = time.Now()
+
+	reloaded := false
+	for _, f := range []*mmdbFile{p.city, p.asn} {
+		if f == nil {
+			continue
+		}
+		changed, err := f.open()
+		if err != nil {
+			debugf("notify: %v", err)
+			continue
+		}
+		if changed {
+			debugf("notify: mmdb 已重新加载 %s", f.path)
+			reloaded = true
+		}
+	}
+	if reloaded && p.onReload != nil {
+		p.onReload()
+	}
+}
+
+func (p *mmdbProvider) Lookup(ctx context.Context, ip string) (*IPLookupResult, error) {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return nil, fmt.Errorf("invalid ip %q", ip)
+	}
+
+	p.ensureOpen()
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	result := &IPLookupResult{}
+	found := false
+
+	if p.city != nil && p.city.reader != nil {
+		var record mmdbCityRecord
+		if err := p.city.reader.Lookup(addr, &record); err != nil {
+			return nil, err
+		}
+		result.Country = mmdbName(record.Country.Names)
+		result.CountryCode = record.Country.IsoCode
+		if len(record.Subdivisions) > 0 {
+			result.Region = mmdbName(record.Subdivisions[0].Names)
+		}
+		result.City = mmdbName(record.City.Names)
+		found = found || result.Country != "" || result.City != ""
+	}
+
+	if p.asn != nil && p.asn.reader != nil {
+		var record mmdbASNRecord
+		if err := p.asn.reader.Lookup(addr, &record); err != nil {
+			return nil, err
+		}
+		result.ASN = record.Number
+		result.Org = record.Org
+		found = found || record.Number > 0
+	}
+
+	if !found {
+		return nil, errors.New("not found in local database")
+	}
+	return result, nil
+}
+
+func mmdbName(names map[string]string) string {
+	if name := names[mmdbLanguage]; name != "" {
+		return name
+	}
+	for _, name := range names {
+		return name
+	}
+	return ""
+}
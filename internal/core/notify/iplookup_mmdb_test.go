@@ -0,0 +1,179 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// writeTestMMDB 生成只包含一个 IPv4 网段的 mmdb 文件（MaxMind DB 格式 2.0，记录长度 24 位）
+func writeTestMMDB(t *testing.T, dbType string, network string, record map[string]any) string {
+	t.Helper()
+	_, ipNet, err := net.ParseCIDR(network)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefix, _ := ipNet.Mask.Size()
+	ip := ipNet.IP.To4()
+
+	// 每个前缀位一个节点：匹配的分支指向下一节点（最后一位指向数据），另一分支为空（node_count）
+	nodeCount := uint32(prefix)
+	dataPointer := nodeCount + 16 // 数据区偏移 0
+	var tree bytes.Buffer
+	for i := 0; i < prefix; i++ {
+		next := uint32(i + 1)
+		if i == prefix-1 {
+			next = dataPointer
+		}
+		left, right := next, nodeCount
+		if ip[i/8]&(0x80>>(i%8)) != 0 {
+			left, right = nodeCount, next
+		}
+		for _, v := range []uint32{left, right} {
+			tree.Write([]byte{byte(v >> 16), byte(v >> 8), byte(v)})
+		}
+	}
+
+	var out bytes.Buffer
+	out.Write(tree.Bytes())
+	out.Write(make([]byte, 16))
+	mmdbEncode(&out, record)
+	out.WriteString("\xab\xcd\xefMaxMind.com")
+	mmdbEncode(&out, map[string]any{
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"build_epoch":                 uint64(1700000000),
+		"database_type":               dbType,
+		"description":                 map[string]any{"en": "sshield test"},
+		"ip_version":                  uint16(4),
+		"languages":                   []any{"en"},
+		"node_count":                  nodeCount,
+		"record_size":                 uint16(24),
+	})
+
+	path := filepath.Join(t.TempDir(), dbType+".mmdb")
+	if err := os.WriteFile(path, out.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// mmdbEncode 按 MaxMind DB 数据区格式编码测试所需的几种类型
+func mmdbEncode(buf *bytes.Buffer, v any) {
+	switch v := v.(type) {
+	case string:
+		mmdbControl(buf, 2, len(v))
+		buf.WriteString(v)
+	case uint16:
+		mmdbUint(buf, 5, uint64(v))
+	case uint32:
+		mmdbUint(buf, 6, uint64(v))
+	case uint64:
+		mmdbUint(buf, 9, v)
+	case map[string]any:
+		mmdbControl(buf, 7, len(v))
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			mmdbEncode(buf, k)
+			mmdbEncode(buf, v[k])
+		}
+	case []any:
+		mmdbControl(buf, 11, len(v))
+		for _, item := range v {
+			mmdbEncode(buf, item)
+		}
+	default:
+		panic("unsupported mmdb type")
+	}
+}
+
+func mmdbUint(buf *bytes.Buffer, typ int, v uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	data := bytes.TrimLeft(b[:], "\x00")
+	mmdbControl(buf, typ, len(data))
+	buf.Write(data)
+}
+
+// mmdbControl 写入控制字节：高 3 位为类型（扩展类型为 0 并在下一字节给出 type-7），低 5 位为长度
+func mmdbControl(buf *bytes.Buffer, typ, size int) {
+	var extra []byte
+	switch {
+	case size < 29:
+	case size < 29+256:
+		extra = []byte{byte(size - 29)}
+		size = 29
+	default:
+		n := size - 285
+		extra = []byte{byte(n >> 8), byte(n)}
+		size = 30
+	}
+	if typ > 7 {
+		buf.WriteByte(byte(size))
+		buf.WriteByte(byte(typ - 7))
+	} else {
+		buf.WriteByte(byte(typ<<5 | size))
+	}
+	buf.Write(extra)
+}
+
+func TestMMDBProviderLookup(t *testing.T) {
+	city := writeTestMMDB(t, "GeoLite2-City", "203.0.113.0/24", map[string]any{
+		"country": map[string]any{
+			"iso_code": "JP",
+			"names":    map[string]any{"en": "Japan", "zh-CN": "日本"},
+		},
+		"subdivisions": []any{
+			map[string]any{"names": map[string]any{"en": "Osaka"}},
+		},
+		"city": map[string]any{"names": map[string]any{"en": "Sakai"}},
+	})
+	asn := writeTestMMDB(t, "GeoLite2-ASN", "203.0.113.0/24", map[string]any{
+		"autonomous_system_number":       uint32(64500),
+		"autonomous_system_organization": "Example Net",
+	})
+
+	for _, path := range []string{city, asn} {
+		reader, err := maxminddb.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := reader.Verify(); err != nil {
+			t.Fatalf("verify %s: %v", path, err)
+		}
+		reader.Close()
+	}
+
+	p := newMMDBProvider(city, asn, false, nil)
+	got, err := p.Lookup(context.Background(), "203.0.113.9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := IPLookupResult{Country: "Japan", CountryCode: "JP", Region: "Osaka", City: "Sakai", ASN: 64500, Org: "Example Net"}
+	if *got != want {
+		t.Fatalf("Lookup = %+v, want %+v", *got, want)
+	}
+
+	if _, err := p.Lookup(context.Background(), "198.51.100.1"); err == nil {
+		t.Fatalf("expected not found outside the database network")
+	}
+
+	l := newIPLookup(&IPLookupConfig{Mode: "local", CityDB: city, ASNDB: asn})
+	if got := l.Lookup("203.0.113.9"); got != "Japan, Osaka, Sakai (AS64500 Example Net)" {
+		t.Fatalf("Lookup = %q", got)
+	}
+	if got := l.LookupResult("203.0.113.10").CountryCode; got != "JP" {
+		t.Fatalf("CountryCode = %q, want JP", got)
+	}
+}
@@ -0,0 +1,74 @@
+package notify
+
+import "testing"
+
+func TestNewIPLookupProviderChain(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *IPLookupConfig
+		want []string
+	}{
+		{name: "default", cfg: nil, want: []string{"ipinfo.io", "ip-api.com"}},
+		{name: "remote", cfg: &IPLookupConfig{Mode: "remote"}, want: []string{"ipinfo.io", "ip-api.com"}},
+		{name: "local", cfg: &IPLookupConfig{Mode: "local", CityDB: "/tmp/city.mmdb"}, want: []string{"mmdb"}},
+		{name: "implicit local-first", cfg: &IPLookupConfig{ASNDB: "/tmp/asn.mmdb"}, want: []string{"mmdb", "ipinfo.io", "ip-api.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newIPLookup(tt.cfg)
+			if len(l.providers) != len(tt.want) {
+				t.Fatalf("providers = %d, want %d", len(l.providers), len(tt.want))
+			}
+			for i, p := range l.providers {
+				if p.Name() != tt.want[i] {
+					t.Fatalf("provider[%d] = %s, want %s", i, p.Name(), tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLocalLookupMissingDatabase(t *testing.T) {
+	l := newIPLookup(&IPLookupConfig{Mode: "local", CityDB: "/nonexistent/city.mmdb"})
+	if got := l.Lookup("8.8.8.8"); got != "" {
+		t.Fatalf("expected empty location without database, got %q", got)
+	}
+}
+
+func TestIPLookupResultString(t *testing.T) {
+	r := &IPLookupResult{Country: "United States", City: "Mountain View", ASN: 15169, Org: "Google LLC"}
+	want := "United States, Mountain View (AS15169 Google LLC)"
+	if got := r.String(); got != want {
+		t.Fatalf("String() = %q, want %q", got, want)
+	}
+
+	asnOnly := &IPLookupResult{ASN: 13335}
+	if got := asnOnly.String(); got != "AS13335" {
+		t.Fatalf("String() = %q, want AS13335", got)
+	}
+}
+
+func TestParseASNOrg(t *testing.T) {
+	asn, org := parseASNOrg("AS15169 Google LLC")
+	if asn != 15169 || org != "Google LLC" {
+		t.Fatalf("parseASNOrg = %d %q", asn, org)
+	}
+
+	asn, org = parseASNOrg("Some Org")
+	if asn != 0 || org != "Some Org" {
+		t.Fatalf("parseASNOrg without prefix = %d %q", asn, org)
+	}
+}
+
+func TestValidateIPLookupConfig(t *testing.T) {
+	if err := ValidateIPLookupConfig(&IPLookupConfig{Mode: "local"}); err == nil {
+		t.Fatalf("expected error for local mode without mmdb")
+	}
+	if err := ValidateIPLookupConfig(&IPLookupConfig{Mode: "bogus"}); err == nil {
+		t.Fatalf("expected error for unknown mode")
+	}
+	if err := ValidateIPLookupConfig(&IPLookupConfig{Mode: "remote"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,283 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Journal 读取方式
+const (
+	journalReaderAuto       = "auto"
+	journalReaderNative     = "native"
+	journalReaderJournalctl = "journalctl"
+)
+
+// journald 持久化与易失存储目录
+var journalDirs = []string{"/var/log/journal", "/run/log/journal"}
+
+// 用于匹配 sshd 与审计进程的字段
+var journalMatchFields = []string{"_SYSTEMD_UNIT", "SYSLOG_IDENTIFIER", "_COMM"}
+
+// 构造 journalRecord 需要的字段
+var journalRecordFields = map[string]bool{
+	"MESSAGE":           true,
+	"_HOSTNAME":         true,
+	"_PID":              true,
+	"_SYSTEMD_UNIT":     true,
+	"SYSLOG_IDENTIFIER": true,
+	"_COMM":             true,
+	"_UID":              true,
+}
+
+// nativeJournal 直接读取 journald 文件，不依赖 journalctl
+type nativeJournal struct {
+	dirs  []string
+	match *sourceMatcher
+	// 各文件（按 file_id 区分，归档改名后不变）已处理的条目数，用于 follow
+	seen map[[16]byte]uint64
+}
+
+// openNativeJournal 检查 journal 文件是否存在且格式均受支持
+func openNativeJournal(match *sourceMatcher) (*nativeJournal, error) {
+	n := &nativeJournal{dirs: journalDirs, match: match, seen: make(map[[16]byte]uint64)}
+	paths := n.files()
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("未找到 journal 文件（%v）", n.dirs)
+	}
+	for _, path := range paths {
+		j, err := openJournalFile(path)
+		if err != nil {
+			return nil, err
+		}
+		j.Close()
+	}
+	return n, nil
+}
+
+// files 返回系统 journal 文件（当前文件与已归档文件）
+func (n *nativeJournal) files() []string {
+	var paths []string
+	for _, dir := range n.dirs {
+		for _, pattern := range []string{"system.journal", "system@*.journal"} {
+			matches, _ := filepath.Glob(filepath.Join(dir, "*", pattern))
+			paths = append(paths, matches...)
+		}
+	}
+	return paths
+}
+
+// nativeRecord 带排序信息的 journald 记录
+type nativeRecord struct {
+	record   journalRecord
+	realtime uint64
+	seqnum   uint64
+}
+
+// collect 通过字段索引读取匹配的记录，include 为 nil 时返回全部，结果按时间排序
+// 同时记录各文件当前的条目数，之后的 poll 从这里继续
+func (n *nativeJournal) collect(include func(j *journalFile, e *journalEntry) bool) ([]journalRecord, error) {
+	var records []nativeRecord
+	for _, path := range n.files() {
+		j, err := openJournalFile(path)
+		if err != nil {
+			if errors.Is(err, errJournalUnsupported) {
+				return nil, err
+			}
+			debugf("notify: 跳过 journal 文件 %s: %v", path, err)
+			continue
+		}
+		n.seen[j.fileID] = j.nEntries
+
+		offsets, err := j.matchEntries(journalMatchFields, n.match.matchField)
+		if err != nil {
+			j.Close()
+			if errors.Is(err, errJournalUnsupported) {
+				return nil, err
+			}
+			// 正在写入的文件可能读到不完整的对象，保留已得到的部分
+			debugf("notify: 读取 journal 文件 %s 索引失败: %v", path, err)
+			continue
+		}
+		for _, offset := range offsets {
+			if offset > j.tailObjectOffset {
+				// 读取文件头之后写入的条目，留给 poll 处理
+				break
+			}
+			e, items, err := j.readEntryHeader(offset)
+			if err != nil {
+				debugf("notify: 读取 journal 条目失败: %v", err)
+				continue
+			}
+			// 先按时间/游标过滤，再解析字段
+			if include != nil && !include(j, e) {
+				continue
+			}
+			if err := j.readEntryFields(e, items, journalRecordFields); err != nil {
+				if errors.Is(err, errJournalUnsupported) {
+					j.Close()
+					return nil, err
+				}
+				debugf("notify: 读取 journal 条目失败: %v", err)
+				continue
+			}
+			records = append(records, nativeRecord{record: toJournalRecord(j, e), realtime: e.realtime, seqnum: e.seqnum})
+		}
+		j.Close()
+	}
+	return sortNativeRecords(records), nil
+}
+
+// snapshot 记录各文件当前的条目数，之后的 poll 只读取新增条目
+func (n *nativeJournal) snapshot() {
+	for _, path := range n.files() {
+		j, err := openJournalFile(path)
+		if err != nil {
+			continue
+		}
+		n.seen[j.fileID] = j.nEntries
+		j.Close()
+	}
+}
+
+// poll 读取各文件自上次以来新增的条目，新出现的文件（轮转后）从头读取
+func (n *nativeJournal) poll() []journalRecord {
+	var records []nativeRecord
+	for _, path := range n.files() {
+		j, err := openJournalFile(path)
+		if err != nil {
+			debugf("notify: 跳过 journal 文件 %s: %v", path, err)
+			continue
+		}
+		skip := n.seen[j.fileID]
+		if j.nEntries <= skip {
+			j.Close()
+			continue
+		}
+
+		offsets, err := j.entries(skip)
+		if err != nil {
+			debugf("notify: 读取 journal 文件 %s 失败: %v", path, err)
+		}
+		for _, offset := range offsets {
+			skip++
+			e, err := j.readEntry(offset, journalRecordFields)
+			if err != nil {
+				log.Printf("读取 journal 条目失败: %v", err)
+				continue
+			}
+			if !n.match.matchJournal(e.fields["_SYSTEMD_UNIT"], e.fields["SYSLOG_IDENTIFIER"], e.fields["_COMM"]) {
+				continue
+			}
+			records = append(records, nativeRecord{record: toJournalRecord(j, e), realtime: e.realtime, seqnum: e.seqnum})
+		}
+		n.seen[j.fileID] = skip
+		j.Close()
+	}
+	return sortNativeRecords(records)
+}
+
+func toJournalRecord(j *journalFile, e *journalEntry) journalRecord {
+	return journalRecord{
+		Cursor:     j.cursor(e),
+		Message:    e.fields["MESSAGE"],
+		Hostname:   e.fields["_HOSTNAME"],
+		RealtimeTS: strconv.FormatUint(e.realtime, 10),
+		Unit:       e.fields["_SYSTEMD_UNIT"],
+		Identifier: e.fields["SYSLOG_IDENTIFIER"],
+		Comm:       e.fields["_COMM"],
+		PID:        e.fields["_PID"],
+		UID:        e.fields["_UID"],
+	}
+}
+
+func sortNativeRecords(records []nativeRecord) []journalRecord {
+	sort.SliceStable(records, func(a, b int) bool {
+		if records[a].realtime != records[b].realtime {
+			return records[a].realtime < records[b].realtime
+		}
+		return records[a].seqnum < records[b].seqnum
+	})
+	out := make([]journalRecord, len(records))
+	for i, r := range records {
+		out[i] = r.record
+	}
+	return out
+}
+
+// probe 统计时间窗口（或游标之后）内可解析的事件数，最多统计到 1 条
+func (n *nativeJournal) probe(state *SourceState, since time.Duration) (int, error) {
+	records, err := n.collect(n.startFilter(state, since, 30*time.Minute))
+	if err != nil {
+		return 0, err
+	}
+	for i := len(records) - 1; i >= 0 && i >= len(records)-200; i-- {
+		ts := parseRealtime(records[i].RealtimeTS)
+		if _, ok := parseJournalMessage(records[i].Message, records[i].Hostname, ts); ok {
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// startFilter 返回读取起点的过滤条件：since 优先，其次为游标，都没有时使用 defaultWindow（0 表示全部）
+func (n *nativeJournal) startFilter(state *SourceState, since, defaultWindow time.Duration) func(*journalFile, *journalEntry) bool {
+	if since <= 0 && state != nil && state.JournalCursor != "" {
+		if cursor, ok := parseJournalCursor(state.JournalCursor); ok {
+			return cursor.after
+		}
+	}
+	window := since
+	if window <= 0 {
+		window = defaultWindow
+	}
+	if window <= 0 {
+		return nil
+	}
+	cutoff := uint64(time.Now().Add(-window).UnixMicro())
+	return func(_ *journalFile, e *journalEntry) bool {
+		return e.realtime >= cutoff
+	}
+}
+
+// runNativeJournal 使用原生读取处理 journald 事件，语义与 journalctl 方式一致
+func runNativeJournal(ctx context.Context, n *nativeJournal, state *SourceState, poll time.Duration, follow bool, since time.Duration, handle func(journalRecord)) error {
+	var records []journalRecord
+	var err error
+	if follow && state.JournalCursor == "" {
+		// 没有游标时从当前位置开始监听
+		n.snapshot()
+	} else {
+		records, err = n.collect(n.startFilter(state, since, 0))
+	}
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		handle(record)
+	}
+	if !follow {
+		return nil
+	}
+
+	if poll <= 0 {
+		poll = time.Second
+	}
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		for _, record := range n.poll() {
+			handle(record)
+		}
+	}
+}
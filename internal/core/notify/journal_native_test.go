@@ -0,0 +1,316 @@
+package notify
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// testJournalBuilder 构造最小可用的 journal 文件：字段哈希表、字段/数据/条目对象与条目数组
+type testJournalBuilder struct {
+	buf     []byte
+	compact bool
+	zstd    map[string]bool // 需要压缩的数据
+}
+
+func (b *testJournalBuilder) object(typ, flags uint8, body []byte) uint64 {
+	for len(b.buf)%8 != 0 {
+		b.buf = append(b.buf, 0)
+	}
+	offset := uint64(len(b.buf))
+	head := make([]byte, 16)
+	head[0] = typ
+	head[1] = flags
+	binary.LittleEndian.PutUint64(head[8:], uint64(16+len(body)))
+	b.buf = append(b.buf, head...)
+	b.buf = append(b.buf, body...)
+	return offset
+}
+
+func (b *testJournalBuilder) put64(offset uint64, v uint64) {
+	binary.LittleEndian.PutUint64(b.buf[offset:], v)
+}
+
+func (b *testJournalBuilder) offsets(values []uint64) []byte {
+	size := 8
+	if b.compact {
+		size = 4
+	}
+	out := make([]byte, len(values)*size)
+	for i, v := range values {
+		if b.compact {
+			binary.LittleEndian.PutUint32(out[i*size:], uint32(v))
+		} else {
+			binary.LittleEndian.PutUint64(out[i*size:], v)
+		}
+	}
+	return out
+}
+
+// entryArrays 将偏移拆分为每组 2 个的条目数组链表，返回链表头
+func (b *testJournalBuilder) entryArrays(values []uint64) uint64 {
+	var first, prev uint64
+	for i := 0; i < len(values); i += 2 {
+		end := i + 2
+		if end > len(values) {
+			end = len(values)
+		}
+		items := append([]uint64{}, values[i:end]...)
+		if end == len(values) {
+			// 最后一个数组预留空位，模拟 journald 预分配
+			items = append(items, 0)
+		}
+		offset := b.object(journalObjectEntryArray, 0, append(make([]byte, 8), b.offsets(items)...))
+		if prev != 0 {
+			b.put64(prev+16, offset)
+		} else {
+			first = offset
+		}
+		prev = offset
+	}
+	return first
+}
+
+func (b *testJournalBuilder) build(entries []map[string]string) []byte {
+	var incompat uint32
+	if b.compact {
+		incompat |= journalIncompatCompact
+	}
+	if len(b.zstd) > 0 {
+		incompat |= journalIncompatZSTD
+	}
+
+	b.buf = make([]byte, 256)
+	copy(b.buf, journalSignature)
+	binary.LittleEndian.PutUint32(b.buf[12:], incompat)
+	copy(b.buf[24:40], "file-id-01234567")
+	copy(b.buf[72:88], "seqnum-id-012345")
+	binary.LittleEndian.PutUint64(b.buf[88:], 256)
+
+	const buckets = 4
+	table := b.object(5, 0, make([]byte, buckets*16))
+
+	// 字段与数据对象
+	fieldOffsets := make(map[string]uint64)
+	dataOffsets := make(map[string]uint64)
+	dataEntries := make(map[string][]uint64)
+	var fieldNames, dataKeys []string
+	for _, e := range entries {
+		for k, v := range e {
+			if _, ok := fieldOffsets[k]; !ok {
+				fieldOffsets[k] = 0
+				fieldNames = append(fieldNames, k)
+			}
+			if _, ok := dataOffsets[k+"="+v]; !ok {
+				dataOffsets[k+"="+v] = 0
+				dataKeys = append(dataKeys, k+"="+v)
+			}
+		}
+	}
+	sort.Strings(fieldNames)
+	sort.Strings(dataKeys)
+	for i, name := range fieldNames {
+		offset := b.object(journalObjectField, 0, append(make([]byte, 24), name...))
+		fieldOffsets[name] = offset
+		bucket := table + 16 + uint64(i%buckets)*16
+		if head := binary.LittleEndian.Uint64(b.buf[bucket:]); head != 0 {
+			// 挂到链表末尾
+			for next := head; ; {
+				n := binary.LittleEndian.Uint64(b.buf[next+24:])
+				if n == 0 {
+					b.put64(next+24, offset)
+					break
+				}
+				next = n
+			}
+		} else {
+			b.put64(bucket, offset)
+		}
+	}
+	for _, key := range dataKeys {
+		payload := []byte(key)
+		var flags uint8
+		if b.zstd[key] {
+			enc, _ := zstd.NewWriter(nil)
+			payload = enc.EncodeAll(payload, nil)
+			enc.Close()
+			flags = journalObjectCompressedZSTD
+		}
+		head := 48
+		if b.compact {
+			head = 56
+		}
+		offset := b.object(journalObjectData, flags, append(make([]byte, head), payload...))
+		dataOffsets[key] = offset
+
+		name, _, _ := strings.Cut(key, "=")
+		field := fieldOffsets[name]
+		if binary.LittleEndian.Uint64(b.buf[field+32:]) == 0 {
+			b.put64(field+32, offset)
+		} else {
+			for next := binary.LittleEndian.Uint64(b.buf[field+32:]); ; {
+				n := binary.LittleEndian.Uint64(b.buf[next+32:])
+				if n == 0 {
+					b.put64(next+32, offset)
+					break
+				}
+				next = n
+			}
+		}
+	}
+
+	// 条目对象
+	var entryOffsets []uint64
+	for i, e := range entries {
+		body := make([]byte, 48)
+		binary.LittleEndian.PutUint64(body[0:], uint64(i+1))                // seqnum
+		binary.LittleEndian.PutUint64(body[8:], uint64(1700000000000000+i)) // realtime
+		binary.LittleEndian.PutUint64(body[16:], uint64(1000+i))            // monotonic
+		copy(body[24:40], "boot-id-01234567")
+		var keys []string
+		for k, v := range e {
+			keys = append(keys, k+"="+v)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if b.compact {
+				body = append(body, b.offsets([]uint64{dataOffsets[key]})...)
+			} else {
+				item := make([]byte, 16)
+				binary.LittleEndian.PutUint64(item, dataOffsets[key])
+				body = append(body, item...)
+			}
+			dataEntries[key] = append(dataEntries[key], 0)
+		}
+		offset := b.object(journalObjectEntry, 0, body)
+		entryOffsets = append(entryOffsets, offset)
+		for _, key := range keys {
+			list := dataEntries[key]
+			list[len(list)-1] = offset
+		}
+	}
+
+	// 数据对象的条目列表：第一个条目直接记录，其余放入条目数组
+	for key, list := range dataEntries {
+		data := dataOffsets[key]
+		b.put64(data+40, list[0])
+		b.put64(data+56, uint64(len(list)))
+		if len(list) > 1 {
+			b.put64(data+48, b.entryArrays(list[1:]))
+		}
+	}
+
+	global := b.entryArrays(entryOffsets)
+	b.put64(120, table+16)
+	b.put64(128, buckets*16)
+	b.put64(136, entryOffsets[len(entryOffsets)-1])
+	b.put64(152, uint64(len(entries)))
+	b.put64(176, global)
+	return b.buf
+}
+
+func testJournalEntries() []map[string]string {
+	return []map[string]string{
+		{"MESSAGE": "Accepted password for alice from 203.0.113.9 port 40022 ssh2", "_HOSTNAME": "web", "_PID": "100", "_SYSTEMD_UNIT": "ssh.service", "SYSLOG_IDENTIFIER": "sshd", "_COMM": "sshd"},
+		{"MESSAGE": "(root) CMD (run-parts /etc/cron.hourly)", "_HOSTNAME": "web", "_PID": "200", "_SYSTEMD_UNIT": "cron.service", "SYSLOG_IDENTIFIER": "CRON", "_COMM": "cron"},
+		{"MESSAGE": "alice : TTY=pts/0 ; PWD=/home/alice ; USER=root ; COMMAND=/usr/bin/id", "_HOSTNAME": "web", "_PID": "300", "SYSLOG_IDENTIFIER": "sudo", "_COMM": "sudo"},
+		{"MESSAGE": "Failed password for root from 203.0.113.9 port 40023 ssh2", "_HOSTNAME": "web", "_PID": "400", "_SYSTEMD_UNIT": "sshd@3-10.0.0.1:22-203.0.113.9:40023.service", "SYSLOG_IDENTIFIER": "sshd-session", "_COMM": "sshd-session"},
+		{"MESSAGE": "Failed password for root from 203.0.113.9 port 40024 ssh2", "_HOSTNAME": "web", "_PID": "500", "_SYSTEMD_UNIT": "ssh.service", "SYSLOG_IDENTIFIER": "sshd", "_COMM": "sshd"},
+	}
+}
+
+func writeTestJournal(t *testing.T, b *testJournalBuilder) *nativeJournal {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "machine-id", "system.journal")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, b.build(testJournalEntries()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return &nativeJournal{dirs: []string{dir}, match: newSourceMatcher(nil, nil, nil), seen: make(map[[16]byte]uint64)}
+}
+
+func TestNativeJournalCollect(t *testing.T) {
+	for _, compact := range []bool{false, true} {
+		b := &testJournalBuilder{
+			compact: compact,
+			zstd:    map[string]bool{"MESSAGE=Failed password for root from 203.0.113.9 port 40024 ssh2": true},
+		}
+		n := writeTestJournal(t, b)
+
+		records, err := n.collect(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var pids []string
+		for _, r := range records {
+			pids = append(pids, r.PID)
+		}
+		if strings.Join(pids, ",") != "100,300,400,500" {
+			t.Fatalf("compact=%v: unexpected records %v", compact, pids)
+		}
+		if records[3].Message != "Failed password for root from 203.0.113.9 port 40024 ssh2" {
+			t.Fatalf("compressed message not decoded: %q", records[3].Message)
+		}
+		if records[0].Hostname != "web" || records[0].Unit != "ssh.service" || records[0].RealtimeTS != "1700000000000000" {
+			t.Fatalf("unexpected record: %+v", records[0])
+		}
+
+		// 从游标继续
+		state := &SourceState{JournalCursor: records[1].Cursor}
+		after, err := n.collect(n.startFilter(state, 0, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(after) != 2 || after[0].PID != "400" {
+			t.Fatalf("compact=%v: unexpected records after cursor: %+v", compact, after)
+		}
+	}
+}
+
+func TestNativeJournalPoll(t *testing.T) {
+	n := writeTestJournal(t, &testJournalBuilder{})
+	j, err := openJournalFile(n.files()[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.seen[j.fileID] = 3
+	j.Close()
+
+	records := n.poll()
+	if len(records) != 2 || records[0].PID != "400" || records[1].PID != "500" {
+		t.Fatalf("unexpected poll records: %+v", records)
+	}
+	if records := n.poll(); len(records) != 0 {
+		t.Fatalf("expected no new records, got %+v", records)
+	}
+}
+
+func TestParseJournalCursor(t *testing.T) {
+	c, ok := parseJournalCursor("s=abc;i=1f;b=def;m=10;t=5f5e100;x=99")
+	if !ok || c.seqnumID != "abc" || c.seqnum != 0x1f || c.realtime != 0x5f5e100 {
+		t.Fatalf("unexpected cursor: %+v ok=%v", c, ok)
+	}
+	if _, ok := parseJournalCursor("bogus"); ok {
+		t.Fatalf("expected invalid cursor")
+	}
+}
+
+func TestOpenJournalFileUnsupported(t *testing.T) {
+	data := (&testJournalBuilder{}).build(testJournalEntries())
+	binary.LittleEndian.PutUint32(data[12:], journalIncompatLZ4)
+	path := filepath.Join(t.TempDir(), "system.journal")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := openJournalFile(path); err == nil || !strings.Contains(err.Error(), errJournalUnsupported.Error()) {
+		t.Fatalf("expected unsupported error, got %v", err)
+	}
+}
@@ -0,0 +1,460 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// journald 日志文件的只读解析（格式说明：https://systemd.io/JOURNAL_FILE_FORMAT/）
+// 仅实现读取条目所需的部分：全局条目数组、字段 → 数据对象链表、数据对象的条目列表
+
+const journalSignature = "LPKSHHRH"
+
+// 文件头中不兼容特性标志
+const (
+	journalIncompatXZ        = 1 << 0
+	journalIncompatLZ4       = 1 << 1
+	journalIncompatKeyedHash = 1 << 2
+	journalIncompatZSTD      = 1 << 3
+	journalIncompatCompact   = 1 << 4
+
+	journalSupportedIncompat = journalIncompatXZ | journalIncompatKeyedHash | journalIncompatZSTD | journalIncompatCompact
+)
+
+// 对象类型
+const (
+	journalObjectData       = 1
+	journalObjectField      = 2
+	journalObjectEntry      = 3
+	journalObjectEntryArray = 6
+)
+
+// 数据对象压缩标志
+const (
+	journalObjectCompressedXZ   = 1 << 0
+	journalObjectCompressedLZ4  = 1 << 1
+	journalObjectCompressedZSTD = 1 << 2
+)
+
+const (
+	journalHeaderMinSize  = 208
+	journalObjectHeadSize = 16
+	// 单个对象的大小上限，超过视为文件损坏（journald 默认单字段上限 64MB，这里只关心日志行）
+	journalMaxObjectSize = 64 << 20
+)
+
+// errJournalUnsupported 文件使用了无法解析的特性（如 LZ4 压缩），调用方应改用 journalctl
+var errJournalUnsupported = errors.New("不支持的 journal 文件格式")
+
+// journalFile 打开的 journal 文件
+type journalFile struct {
+	r        io.ReaderAt
+	closer   io.Closer
+	path     string
+	fileID   [16]byte
+	seqnumID [16]byte
+	compact  bool
+	size     uint64
+
+	tailObjectOffset uint64
+	nEntries         uint64
+	entryArrayOffset uint64
+	fieldHashOffset  uint64
+	fieldHashSize    uint64
+}
+
+// journalEntry 条目中解析出的字段
+type journalEntry struct {
+	offset    uint64
+	seqnum    uint64
+	realtime  uint64 // 微秒
+	monotonic uint64
+	bootID    [16]byte
+	xorHash   uint64
+	fields    map[string]string
+}
+
+func openJournalFile(path string) (*journalFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	j, err := newJournalFile(f, uint64(info.Size()), path)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	j.closer = f
+	return j, nil
+}
+
+func newJournalFile(r io.ReaderAt, size uint64, path string) (*journalFile, error) {
+	header := make([]byte, journalHeaderMinSize)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, fmt.Errorf("读取 %s 文件头失败: %w", path, err)
+	}
+	if string(header[:8]) != journalSignature {
+		return nil, fmt.Errorf("%s 不是 journal 文件", path)
+	}
+
+	incompat := binary.LittleEndian.Uint32(header[12:])
+	if incompat&^journalSupportedIncompat != 0 {
+		return nil, fmt.Errorf("%w: %s 不兼容标志 0x%x", errJournalUnsupported, path, incompat)
+	}
+	if headerSize := binary.LittleEndian.Uint64(header[88:]); headerSize < journalHeaderMinSize {
+		return nil, fmt.Errorf("%w: %s 文件头过小", errJournalUnsupported, path)
+	}
+
+	j := &journalFile{
+		r:                r,
+		path:             path,
+		compact:          incompat&journalIncompatCompact != 0,
+		size:             size,
+		fieldHashOffset:  binary.LittleEndian.Uint64(header[120:]),
+		fieldHashSize:    binary.LittleEndian.Uint64(header[128:]),
+		tailObjectOffset: binary.LittleEndian.Uint64(header[136:]),
+		nEntries:         binary.LittleEndian.Uint64(header[152:]),
+		entryArrayOffset: binary.LittleEndian.Uint64(header[176:]),
+	}
+	copy(j.fileID[:], header[24:40])
+	copy(j.seqnumID[:], header[72:88])
+	return j, nil
+}
+
+func (j *journalFile) Close() error {
+	if j.closer == nil {
+		return nil
+	}
+	return j.closer.Close()
+}
+
+// readObject 读取指定偏移处的对象，want 为 0 时不检查类型
+func (j *journalFile) readObject(offset uint64, want uint8) ([]byte, error) {
+	if offset == 0 || offset%8 != 0 || offset+journalObjectHeadSize > j.size {
+		return nil, fmt.Errorf("%s: 无效的对象偏移 %d", j.path, offset)
+	}
+	head := make([]byte, journalObjectHeadSize)
+	if _, err := j.r.ReadAt(head, int64(offset)); err != nil {
+		return nil, err
+	}
+	typ := head[0]
+	size := binary.LittleEndian.Uint64(head[8:])
+	if want != 0 && typ != want {
+		return nil, fmt.Errorf("%s: 偏移 %d 处对象类型为 %d，期望 %d", j.path, offset, typ, want)
+	}
+	if size < journalObjectHeadSize || size > journalMaxObjectSize || offset+size > j.size {
+		return nil, fmt.Errorf("%s: 偏移 %d 处对象大小无效", j.path, offset)
+	}
+	obj := make([]byte, size)
+	if _, err := j.r.ReadAt(obj, int64(offset)); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// offsetSize 条目数组与条目项中偏移的字节数
+func (j *journalFile) offsetSize() int {
+	if j.compact {
+		return 4
+	}
+	return 8
+}
+
+func (j *journalFile) readOffset(b []byte) uint64 {
+	if j.compact {
+		return uint64(binary.LittleEndian.Uint32(b))
+	}
+	return binary.LittleEndian.Uint64(b)
+}
+
+// walkEntryArray 从 first 开始沿条目数组链表依次返回最多 n 个条目偏移，skip 为跳过的条目数
+// fn 返回 false 时停止
+func (j *journalFile) walkEntryArray(first, skip, n uint64, fn func(offset uint64) bool) error {
+	size := uint64(j.offsetSize())
+	var index uint64
+	for offset := first; offset != 0 && index < n; {
+		obj, err := j.readObject(offset, journalObjectEntryArray)
+		if err != nil {
+			return err
+		}
+		items := (uint64(len(obj)) - 24) / size
+		if index+items <= skip {
+			// 整个数组都在跳过范围内
+			index += items
+			offset = binary.LittleEndian.Uint64(obj[16:])
+			continue
+		}
+		for i := uint64(0); i < items && index < n; i++ {
+			entry := j.readOffset(obj[24+i*size:])
+			if entry == 0 {
+				// 预分配但尚未写入的位置
+				return nil
+			}
+			if index >= skip && !fn(entry) {
+				return nil
+			}
+			index++
+		}
+		offset = binary.LittleEndian.Uint64(obj[16:])
+	}
+	return nil
+}
+
+// entries 返回全局条目数组中第 skip 个之后的条目偏移
+func (j *journalFile) entries(skip uint64) ([]uint64, error) {
+	var offsets []uint64
+	err := j.walkEntryArray(j.entryArrayOffset, skip, j.nEntries, func(offset uint64) bool {
+		offsets = append(offsets, offset)
+		return true
+	})
+	return offsets, err
+}
+
+// findField 在字段哈希表中查找字段对象，字段不存在时返回 0
+// 字段数量很少，这里遍历整个哈希表，避免实现 journald 的（带密钥的）哈希函数
+func (j *journalFile) findField(name string) (uint64, error) {
+	if j.fieldHashOffset == 0 || j.fieldHashSize == 0 {
+		return 0, nil
+	}
+	table := make([]byte, j.fieldHashSize)
+	if _, err := j.r.ReadAt(table, int64(j.fieldHashOffset)); err != nil {
+		return 0, err
+	}
+	for i := 0; i+16 <= len(table); i += 16 {
+		for offset := binary.LittleEndian.Uint64(table[i:]); offset != 0; {
+			obj, err := j.readObject(offset, journalObjectField)
+			if err != nil {
+				return 0, err
+			}
+			if len(obj) >= 40 && string(obj[40:]) == name {
+				return offset, nil
+			}
+			offset = binary.LittleEndian.Uint64(obj[24:])
+		}
+	}
+	return 0, nil
+}
+
+// dataPayload 返回数据对象的 "FIELD=value" 内容
+func (j *journalFile) dataPayload(obj []byte) ([]byte, error) {
+	start := 64
+	if j.compact {
+		start = 72
+	}
+	if len(obj) < start {
+		return nil, fmt.Errorf("%s: 数据对象过小", j.path)
+	}
+	payload := obj[start:]
+
+	switch flags := obj[1]; {
+	case flags&journalObjectCompressedXZ != 0:
+		r, err := xz.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(io.LimitReader(r, journalMaxObjectSize))
+	case flags&journalObjectCompressedZSTD != 0:
+		return zstdDecoder().DecodeAll(payload, nil)
+	case flags&journalObjectCompressedLZ4 != 0:
+		return nil, fmt.Errorf("%w: %s 使用 LZ4 压缩", errJournalUnsupported, j.path)
+	default:
+		return payload, nil
+	}
+}
+
+var (
+	zstdOnce sync.Once
+	zstdDec  *zstd.Decoder
+)
+
+// zstdDecoder 返回共享的 zstd 解码器（DecodeAll 可并发调用）
+func zstdDecoder() *zstd.Decoder {
+	zstdOnce.Do(func() {
+		zstdDec, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+	})
+	return zstdDec
+}
+
+// matchEntries 返回字段值满足 match 的所有条目偏移（按文件中的顺序，即时间顺序）
+// 通过字段对象的数据链表与数据对象的条目列表定位，无需逐条解析条目
+func (j *journalFile) matchEntries(fields []string, match func(field, value string) bool) ([]uint64, error) {
+	seen := make(map[uint64]struct{})
+	for _, field := range fields {
+		fieldOffset, err := j.findField(field)
+		if err != nil {
+			return nil, err
+		}
+		if fieldOffset == 0 {
+			continue
+		}
+		fieldObj, err := j.readObject(fieldOffset, journalObjectField)
+		if err != nil {
+			return nil, err
+		}
+
+		for offset := binary.LittleEndian.Uint64(fieldObj[32:]); offset != 0; {
+			obj, err := j.readObject(offset, journalObjectData)
+			if err != nil {
+				return nil, err
+			}
+			if len(obj) < 64 {
+				return nil, fmt.Errorf("%s: 数据对象过小", j.path)
+			}
+			next := binary.LittleEndian.Uint64(obj[32:])
+
+			payload, err := j.dataPayload(obj)
+			if err != nil {
+				return nil, err
+			}
+			value := strings.TrimPrefix(string(payload), field+"=")
+			if match(field, value) {
+				nEntries := binary.LittleEndian.Uint64(obj[56:])
+				if first := binary.LittleEndian.Uint64(obj[40:]); first != 0 {
+					seen[first] = struct{}{}
+				}
+				if nEntries > 1 {
+					err := j.walkEntryArray(binary.LittleEndian.Uint64(obj[48:]), 0, nEntries-1, func(entry uint64) bool {
+						seen[entry] = struct{}{}
+						return true
+					})
+					if err != nil {
+						return nil, err
+					}
+				}
+			}
+			offset = next
+		}
+	}
+
+	offsets := make([]uint64, 0, len(seen))
+	for offset := range seen {
+		offsets = append(offsets, offset)
+	}
+	sort.Slice(offsets, func(a, b int) bool { return offsets[a] < offsets[b] })
+	return offsets, nil
+}
+
+// readEntry 读取条目，仅保留 want 中的字段
+func (j *journalFile) readEntry(offset uint64, want map[string]bool) (*journalEntry, error) {
+	e, items, err := j.readEntryHeader(offset)
+	if err != nil {
+		return nil, err
+	}
+	if err := j.readEntryFields(e, items, want); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// readEntryHeader 读取条目的序列号与时间，返回未解析的条目项
+func (j *journalFile) readEntryHeader(offset uint64) (*journalEntry, []byte, error) {
+	obj, err := j.readObject(offset, journalObjectEntry)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(obj) < 64 {
+		return nil, nil, fmt.Errorf("%s: 条目对象过小", j.path)
+	}
+
+	e := &journalEntry{
+		offset:    offset,
+		seqnum:    binary.LittleEndian.Uint64(obj[16:]),
+		realtime:  binary.LittleEndian.Uint64(obj[24:]),
+		monotonic: binary.LittleEndian.Uint64(obj[32:]),
+		xorHash:   binary.LittleEndian.Uint64(obj[56:]),
+		fields:    make(map[string]string),
+	}
+	copy(e.bootID[:], obj[40:56])
+	return e, obj[64:], nil
+}
+
+// readEntryFields 解析条目项指向的数据对象，仅保留 want 中的字段
+func (j *journalFile) readEntryFields(e *journalEntry, items []byte, want map[string]bool) error {
+	itemSize := 16
+	if j.compact {
+		itemSize = 4
+	}
+	for i := 0; i+itemSize <= len(items); i += itemSize {
+		data, err := j.readObject(j.readOffset(items[i:]), journalObjectData)
+		if err != nil {
+			return err
+		}
+		payload, err := j.dataPayload(data)
+		if err != nil {
+			return err
+		}
+		name, value, ok := strings.Cut(string(payload), "=")
+		if ok && want[name] {
+			e.fields[name] = value
+		}
+	}
+	return nil
+}
+
+// cursor 返回与 journalctl 相同格式的游标，两种读取方式可以互相续读
+func (j *journalFile) cursor(e *journalEntry) string {
+	return fmt.Sprintf("s=%s;i=%x;b=%s;m=%x;t=%x;x=%x",
+		hex.EncodeToString(j.seqnumID[:]), e.seqnum,
+		hex.EncodeToString(e.bootID[:]), e.monotonic, e.realtime, e.xorHash)
+}
+
+// journalCursor journalctl 游标中用于定位的部分
+type journalCursor struct {
+	seqnumID string
+	seqnum   uint64
+	realtime uint64
+}
+
+// parseJournalCursor 解析 "s=...;i=...;b=...;m=...;t=...;x=..." 格式的游标
+func parseJournalCursor(s string) (journalCursor, bool) {
+	var c journalCursor
+	var hasSeqnum, hasRealtime bool
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "s":
+			c.seqnumID = value
+		case "i":
+			n, err := strconv.ParseUint(value, 16, 64)
+			if err != nil {
+				return c, false
+			}
+			c.seqnum, hasSeqnum = n, true
+		case "t":
+			n, err := strconv.ParseUint(value, 16, 64)
+			if err != nil {
+				return c, false
+			}
+			c.realtime, hasRealtime = n, true
+		}
+	}
+	return c, hasRealtime || (c.seqnumID != "" && hasSeqnum)
+}
+
+// after 判断条目是否位于游标之后：同一序列号空间按序列号比较，否则按时间比较
+func (c journalCursor) after(j *journalFile, e *journalEntry) bool {
+	if c.seqnumID != "" && c.seqnumID == hex.EncodeToString(j.seqnumID[:]) {
+		return e.seqnum > c.seqnum
+	}
+	return e.realtime > c.realtime
+}
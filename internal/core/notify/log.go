@@ -1,13 +1,7 @@
 package notify
 
-import (
-	"fmt"
-	"os"
-)
+import "github.com/Hootrix/sshield/internal/debug"
 
 func debugf(format string, args ...interface{}) {
-	if os.Getenv("SSHIELD_DEBUG") == "" {
-		return
-	}
-	fmt.Printf("[sshield-debug] "+format+"\n", args...)
+	debug.Printf(format, args...)
 }
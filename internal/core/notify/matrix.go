@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// 网络错误或服务端 5xx 时的重试次数，限流由 sendJSONWithBackoff 处理
+const matrixMaxRetries = 2
+
+// MatrixNotifier 通过 Matrix Client-Server API 向房间发送消息
+type MatrixNotifier struct {
+	homeserver  string
+	accessToken string
+	roomID      string
+	httpClient  *http.Client
+}
+
+// NewMatrixNotifier 创建 Matrix 通知器
+func NewMatrixNotifier(cfg *MatrixConfig) *MatrixNotifier {
+	return &MatrixNotifier{
+		homeserver:  strings.TrimRight(cfg.Homeserver, "/"),
+		accessToken: cfg.AccessToken,
+		roomID:      cfg.RoomID,
+		httpClient:  newChannelHTTPClient(),
+	}
+}
+
+// Send 以 PUT /rooms/{roomId}/send/m.room.message/{txnId} 发送消息。
+// 同一次发送的所有重试使用同一个事务 ID，服务端据此去重，不会产生重复消息
+func (m *MatrixNotifier) Send(event LoginEvent) error {
+	txnID, err := newMatrixTxnID()
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		m.homeserver, url.PathEscape(m.roomID), url.PathEscape(txnID))
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+m.accessToken)
+
+	payload := map[string]any{
+		// m.notice 默认不触发提醒，告警使用 m.text
+		"msgtype":        "m.text",
+		"body":           robotTitle(event) + "\n" + pushMessage(event),
+		"format":         "org.matrix.custom.html",
+		"formatted_body": matrixHTML(event),
+	}
+
+	for attempt := 0; ; attempt++ {
+		_, err = sendJSONWithBackoff(m.httpClient, http.MethodPut, endpoint, payload, header)
+		if err == nil {
+			return nil
+		}
+		if attempt >= matrixMaxRetries || !matrixRetryable(err) {
+			break
+		}
+		debugf("notify: matrix 发送失败（%v），使用事务 %s 重试", err, txnID)
+		channelSleep(time.Second << attempt)
+	}
+
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		var resp struct {
+			ErrCode string `json:"errcode"`
+			Error   string `json:"error"`
+		}
+		if json.Unmarshal([]byte(statusErr.Body), &resp) == nil && resp.ErrCode != "" {
+			return fmt.Errorf("matrix 返回错误（状态码 %d）: %s %s", statusErr.StatusCode, resp.ErrCode, resp.Error)
+		}
+	}
+	return fmt.Errorf("发送 matrix 消息失败: %w", err)
+}
+
+// Test 测试 Matrix 配置
+func (m *MatrixNotifier) Test() error {
+	if err := m.Send(channelTestEvent()); err != nil {
+		return fmt.Errorf("matrix 测试失败: %w", err)
+	}
+	return nil
+}
+
+// matrixRetryable 网络错误与 5xx 可以重试，其余状态码（如 403 未加入房间）重试无意义
+func matrixRetryable(err error) bool {
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+	return true
+}
+
+func newMatrixTxnID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("生成事务 ID 失败: %w", err)
+	}
+	return "sshield-" + hex.EncodeToString(b), nil
+}
+
+// matrixHTML formatted_body：标题加粗，字段逐行，原始日志放入代码块
+func matrixHTML(event LoginEvent) string {
+	var b strings.Builder
+	b.WriteString("<p><strong>" + html.EscapeString(robotTitle(event)) + "</strong></p><p>")
+	for i, f := range eventFields(event) {
+		if i > 0 {
+			b.WriteString("<br>")
+		}
+		fmt.Fprintf(&b, "<b>%s:</b> %s", html.EscapeString(f.Label), html.EscapeString(f.Value))
+	}
+	b.WriteString("</p>")
+	if event.Message != "" {
+		b.WriteString("<pre><code>" + html.EscapeString(event.Message) + "</code></pre>")
+	}
+	return b.String()
+}
@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatrixNotifierSend(t *testing.T) {
+	server, requests := newRecordingServer(t, respondWith(http.StatusOK, `{"event_id":"$abc"}`))
+	event := testLoginEvent()
+	event.Message = "<b>Accepted</b>"
+	n := NewMatrixNotifier(&MatrixConfig{Homeserver: server.URL + "/", AccessToken: "syt_x", RoomID: "!room:example.com"})
+	if err := n.Send(event); err != nil {
+		t.Fatal(err)
+	}
+
+	req := (*requests)[0]
+	if req.method != http.MethodPut || req.header.Get("Authorization") != "Bearer syt_x" {
+		t.Fatalf("unexpected request: %s %s", req.method, req.header.Get("Authorization"))
+	}
+	if !strings.HasPrefix(req.path, "/_matrix/client/v3/rooms/%21room:example.com/send/m.room.message/sshield-") {
+		t.Fatalf("unexpected path: %s", req.path)
+	}
+	if req.body["msgtype"] != "m.text" || req.body["format"] != "org.matrix.custom.html" {
+		t.Fatalf("unexpected payload: %v", req.body)
+	}
+	if !strings.Contains(req.body["body"].(string), "用户: root") {
+		t.Fatalf("unexpected body: %v", req.body["body"])
+	}
+	formatted := req.body["formatted_body"].(string)
+	if !strings.Contains(formatted, "<b>用户:</b> root") || !strings.Contains(formatted, "<pre><code>&lt;b&gt;Accepted&lt;/b&gt;</code></pre>") {
+		t.Fatalf("unexpected formatted body: %s", formatted)
+	}
+}
+
+func TestMatrixNotifierRetryReusesTxnID(t *testing.T) {
+	waits := stubChannelSleep(t)
+	server, requests := newRecordingServer(t, func(w http.ResponseWriter, attempt int) {
+		switch attempt {
+		case 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"errcode":"M_LIMIT_EXCEEDED","error":"Too many requests","retry_after_ms":1500}`))
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Write([]byte(`{"event_id":"$abc"}`))
+		}
+	})
+	n := NewMatrixNotifier(&MatrixConfig{Homeserver: server.URL, AccessToken: "t", RoomID: "!r:example.com"})
+	if err := n.Send(testLoginEvent()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*requests) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(*requests))
+	}
+	for _, req := range (*requests)[1:] {
+		if req.path != (*requests)[0].path {
+			t.Fatalf("retry used a different transaction: %s vs %s", req.path, (*requests)[0].path)
+		}
+	}
+	if (*waits)[0] != 1500*time.Millisecond {
+		t.Fatalf("retry_after_ms not honoured: %v", *waits)
+	}
+
+	// 新的一次发送使用新的事务 ID
+	if err := n.Send(testLoginEvent()); err != nil {
+		t.Fatal(err)
+	}
+	if (*requests)[3].path == (*requests)[0].path {
+		t.Fatal("separate sends must not share a transaction ID")
+	}
+}
+
+func TestMatrixNotifierForbidden(t *testing.T) {
+	stubChannelSleep(t)
+	server, requests := newRecordingServer(t, respondWith(http.StatusForbidden, `{"errcode":"M_FORBIDDEN","error":"User not in room"}`))
+	err := NewMatrixNotifier(&MatrixConfig{Homeserver: server.URL, AccessToken: "t", RoomID: "!r:example.com"}).Send(testLoginEvent())
+	if err == nil || !strings.Contains(err.Error(), "M_FORBIDDEN") {
+		t.Fatalf("expected M_FORBIDDEN error, got %v", err)
+	}
+	if len(*requests) != 1 {
+		t.Fatalf("client errors should not be retried, got %d attempts", len(*requests))
+	}
+}
+
+func TestValidateMatrixChannel(t *testing.T) {
+	ok := &MatrixConfig{Homeserver: "https://matrix.example.com", AccessToken: "t", RoomID: "!r:example.com"}
+	if err := ValidateChannelConfig(&ChannelConfig{Type: "matrix", Matrix: ok}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, cfg := range []*MatrixConfig{
+		nil,
+		{Homeserver: "matrix.example.com", AccessToken: "t", RoomID: "!r:example.com"},
+		{Homeserver: "https://matrix.example.com", AccessToken: "t", RoomID: "#ops:example.com"},
+		{Homeserver: "https://matrix.example.com", RoomID: "!r:example.com"},
+	} {
+		if err := ValidateChannelConfig(&ChannelConfig{Type: "matrix", Matrix: cfg}); err == nil {
+			t.Fatalf("expected validation error for %+v", cfg)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package notify
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	defaultMQTTTopic = "sshield/{{.Hostname}}/{{.Type}}"
+	mqttTimeout      = 10 * time.Second
+	mqttKeepAlive    = 30 // 秒，每次发送都是短连接，只需覆盖一次发布的时长
+)
+
+// MQTT 3.1.1 控制报文类型（高 4 位）
+const (
+	mqttConnect    byte = 1
+	mqttConnack    byte = 2
+	mqttPublish    byte = 3
+	mqttPuback     byte = 4
+	mqttDisconnect byte = 14
+)
+
+// CONNACK 返回码说明（MQTT 3.1.1 第 3.2.2.3 节）
+var mqttConnackErrors = map[byte]string{
+	1: "不支持的协议版本",
+	2: "客户端 ID 被拒绝",
+	3: "服务不可用",
+	4: "用户名或密码错误",
+	5: "未授权",
+}
+
+// MQTTNotifier 将事件以 JSON 发布到 MQTT 主题，每次发送建立一次连接
+type MQTTNotifier struct {
+	cfg            MQTTConfig
+	topic          *template.Template
+	clientIDPrefix string
+}
+
+// NewMQTTNotifier 创建 MQTT 通知器，主题模板无法解析时返回错误
+func NewMQTTNotifier(cfg *MQTTConfig) (*MQTTNotifier, error) {
+	text := cfg.Topic
+	if text == "" {
+		text = defaultMQTTTopic
+	}
+	topic, err := template.New("topic").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("解析主题模板失败: %w", err)
+	}
+
+	prefix := cfg.ClientID
+	if prefix == "" {
+		hostname, _ := os.Hostname()
+		prefix = "sshield-" + hostname
+	}
+	return &MQTTNotifier{cfg: *cfg, topic: topic, clientIDPrefix: prefix}, nil
+}
+
+// newClientID 为每次连接生成不同的客户端 ID：多个日志源与文件审计会并发发送，
+// 相同 ID 的第二个 CONNECT 会让 broker 断开先建立的连接，导致消息丢失
+func (m *MQTTNotifier) newClientID() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("生成客户端 ID 失败: %w", err)
+	}
+	return m.clientIDPrefix + "-" + hex.EncodeToString(b), nil
+}
+
+// Send 连接 broker 并发布事件，QoS 1 时等待 PUBACK
+func (m *MQTTNotifier) Send(event LoginEvent) error {
+	topic, err := m.renderTopic(event)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(newEventRecord(event))
+	if err != nil {
+		return fmt.Errorf("编码事件失败: %w", err)
+	}
+
+	clientID, err := m.newClientID()
+	if err != nil {
+		return err
+	}
+
+	conn, err := m.dial()
+	if err != nil {
+		return fmt.Errorf("连接 MQTT broker 失败: %w", err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(mqttTimeout))
+	r := bufio.NewReader(conn)
+
+	if _, err := conn.Write(mqttConnectPacket(clientID, m.cfg.Username, m.cfg.Password)); err != nil {
+		return fmt.Errorf("发送 CONNECT 失败: %w", err)
+	}
+	header, body, err := readMQTTPacket(r)
+	if err != nil {
+		return fmt.Errorf("读取 CONNACK 失败: %w", err)
+	}
+	if header>>4 != mqttConnack || len(body) != 2 {
+		return fmt.Errorf("broker 返回了意外的报文类型 %d", header>>4)
+	}
+	if rc := body[1]; rc != 0 {
+		reason := mqttConnackErrors[rc]
+		if reason == "" {
+			reason = "未知错误"
+		}
+		return fmt.Errorf("broker 拒绝连接（返回码 %d）: %s", rc, reason)
+	}
+
+	const packetID = 1
+	if _, err := conn.Write(mqttPublishPacket(topic, payload, m.cfg.QoS, m.cfg.Retain, packetID)); err != nil {
+		return fmt.Errorf("发布消息失败: %w", err)
+	}
+	if m.cfg.QoS > 0 {
+		header, body, err := readMQTTPacket(r)
+		if err != nil {
+			return fmt.Errorf("读取 PUBACK 失败: %w", err)
+		}
+		if header>>4 != mqttPuback || len(body) != 2 || binary.BigEndian.Uint16(body) != packetID {
+			return fmt.Errorf("broker 返回了意外的报文类型 %d", header>>4)
+		}
+	}
+
+	_, _ = conn.Write([]byte{mqttDisconnect << 4, 0})
+	return nil
+}
+
+// Test 发布一条测试事件
+func (m *MQTTNotifier) Test() error {
+	if err := m.Send(channelTestEvent()); err != nil {
+		return fmt.Errorf("mqtt 测试失败: %w", err)
+	}
+	return nil
+}
+
+// renderTopic 渲染主题模板，变量与 curl 相同；发布的主题不能包含通配符
+func (m *MQTTNotifier) renderTopic(event LoginEvent) (string, error) {
+	var buf bytes.Buffer
+	if err := m.topic.Execute(&buf, eventTemplateData(event)); err != nil {
+		return "", fmt.Errorf("渲染主题模板失败: %w", err)
+	}
+	topic := buf.String()
+	if topic == "" || strings.ContainsAny(topic, "+#\x00") {
+		return "", fmt.Errorf("无效的 MQTT 主题: %q", topic)
+	}
+	return topic, nil
+}
+
+// dial 按 broker 地址的 scheme 建立 TCP 或 TLS 连接
+func (m *MQTTNotifier) dial() (net.Conn, error) {
+	u, err := url.Parse(m.cfg.Broker)
+	if err != nil {
+		return nil, err
+	}
+	dialer := &net.Dialer{Timeout: mqttTimeout}
+
+	switch u.Scheme {
+	case "tcp", "mqtt":
+		return dialer.Dial("tcp", mqttHostPort(u, "1883"))
+	case "ssl", "tls", "mqtts":
+		tlsCfg := &tls.Config{
+			ServerName:         u.Hostname(),
+			InsecureSkipVerify: m.cfg.InsecureSkipVerify,
+		}
+		if m.cfg.CAFile != "" {
+			pem, err := os.ReadFile(m.cfg.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("读取 CA 证书失败: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("CA 证书文件中没有有效证书: %s", m.cfg.CAFile)
+			}
+			tlsCfg.RootCAs = pool
+		}
+		return tls.DialWithDialer(dialer, "tcp", mqttHostPort(u, "8883"), tlsCfg)
+	default:
+		return nil, fmt.Errorf("不支持的 broker 协议: %s", u.Scheme)
+	}
+}
+
+func mqttHostPort(u *url.URL, defaultPort string) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	return net.JoinHostPort(u.Hostname(), defaultPort)
+}
+
+// mqttConnectPacket 生成 CONNECT 报文（协议级别 4，clean session）
+func mqttConnectPacket(clientID, username, password string) []byte {
+	var vh bytes.Buffer
+	writeMQTTString(&vh, "MQTT")
+	vh.WriteByte(4)
+	flags := byte(0x02)
+	if username != "" {
+		flags |= 0x80
+		if password != "" {
+			flags |= 0x40
+		}
+	}
+	vh.WriteByte(flags)
+	_ = binary.Write(&vh, binary.BigEndian, uint16(mqttKeepAlive))
+
+	writeMQTTString(&vh, clientID)
+	if username != "" {
+		writeMQTTString(&vh, username)
+		if password != "" {
+			writeMQTTString(&vh, password)
+		}
+	}
+	return mqttPacket(mqttConnect<<4, vh.Bytes())
+}
+
+// mqttPublishPacket 生成 PUBLISH 报文，QoS 0 时不带报文标识
+func mqttPublishPacket(topic string, payload []byte, qos int, retain bool, packetID uint16) []byte {
+	header := mqttPublish<<4 | byte(qos)<<1
+	if retain {
+		header |= 0x01
+	}
+	var body bytes.Buffer
+	writeMQTTString(&body, topic)
+	if qos > 0 {
+		_ = binary.Write(&body, binary.BigEndian, packetID)
+	}
+	body.Write(payload)
+	return mqttPacket(header, body.Bytes())
+}
+
+// mqttPacket 拼接固定报头（类型与标志、剩余长度）和报文内容
+func mqttPacket(header byte, body []byte) []byte {
+	packet := []byte{header}
+	n := len(body)
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		packet = append(packet, b)
+		if n == 0 {
+			break
+		}
+	}
+	return append(packet, body...)
+}
+
+func writeMQTTString(w *bytes.Buffer, s string) {
+	_ = binary.Write(w, binary.BigEndian, uint16(len(s)))
+	w.WriteString(s)
+}
+
+// readMQTTPacket 读取一个报文，返回固定报头的第一个字节（类型与标志）与剩余部分
+func readMQTTPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, errors.New("剩余长度编码无效")
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7f) * multiplier
+		if b&0x80 == 0 {
+			break
+		}
+		multiplier *= 128
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
@@ -0,0 +1,274 @@
+package notify
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mqttMessage 测试 broker 收到的一次发布
+type mqttMessage struct {
+	clientID string
+	username string
+	password string
+	topic    string
+	qos      int
+	retain   bool
+	payload  []byte
+}
+
+// fakeMQTTBroker 最小的 MQTT broker 替身：处理 CONNECT、PUBLISH（QoS 0/1）与 DISCONNECT
+type fakeMQTTBroker struct {
+	ln       net.Listener
+	rc       byte
+	messages chan mqttMessage
+}
+
+func newFakeMQTTBroker(t *testing.T, ln net.Listener, rc byte) *fakeMQTTBroker {
+	t.Helper()
+	b := &fakeMQTTBroker{ln: ln, rc: rc, messages: make(chan mqttMessage, 4)}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(t, conn)
+		}
+	}()
+	return b
+}
+
+func (b *fakeMQTTBroker) serve(t *testing.T, conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	var msg mqttMessage
+	for {
+		header, body, err := readMQTTPacket(r)
+		if err != nil {
+			return
+		}
+		switch header >> 4 {
+		case mqttConnect:
+			fields, flags := parseMQTTConnect(body)
+			msg.clientID = fields[0]
+			if flags&0x80 != 0 {
+				msg.username = fields[1]
+			}
+			if flags&0x40 != 0 {
+				msg.password = fields[2]
+			}
+			conn.Write([]byte{mqttConnack << 4, 2, 0, b.rc})
+		case mqttPublish:
+			msg.qos = int(header>>1) & 0x03
+			msg.retain = header&0x01 != 0
+			n := int(binary.BigEndian.Uint16(body))
+			msg.topic = string(body[2 : 2+n])
+			rest := body[2+n:]
+			if msg.qos > 0 {
+				conn.Write([]byte{mqttPuback << 4, 2, rest[0], rest[1]})
+				rest = rest[2:]
+			}
+			msg.payload = rest
+			b.messages <- msg
+		case mqttDisconnect:
+			return
+		default:
+			t.Errorf("unexpected packet type %d", header>>4)
+			return
+		}
+	}
+}
+
+// parseMQTTConnect 返回载荷中的字符串（客户端 ID、用户名、密码）与连接标志
+func parseMQTTConnect(body []byte) ([]string, byte) {
+	flags := body[7]
+	var fields []string
+	for p := 10; p+2 <= len(body); {
+		n := int(binary.BigEndian.Uint16(body[p:]))
+		fields = append(fields, string(body[p+2:p+2+n]))
+		p += 2 + n
+	}
+	for len(fields) < 3 {
+		fields = append(fields, "")
+	}
+	return fields, flags
+}
+
+func (b *fakeMQTTBroker) receive(t *testing.T) mqttMessage {
+	t.Helper()
+	select {
+	case msg := <-b.messages:
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("broker did not receive a message")
+		return mqttMessage{}
+	}
+}
+
+func listenLocal(t *testing.T) net.Listener {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ln
+}
+
+func TestMQTTNotifierPublish(t *testing.T) {
+	broker := newFakeMQTTBroker(t, listenLocal(t), 0)
+	n, err := NewMQTTNotifier(&MQTTConfig{
+		Broker:   "tcp://" + broker.ln.Addr().String(),
+		QoS:      1,
+		Retain:   true,
+		Username: "sshield",
+		Password: "secret",
+		ClientID: "test-client",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Send(testLoginEvent()); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := broker.receive(t)
+	if msg.topic != "sshield/web-1/login_success" || msg.qos != 1 || !msg.retain {
+		t.Fatalf("unexpected publish: topic %s qos %d retain %v", msg.topic, msg.qos, msg.retain)
+	}
+	if !strings.HasPrefix(msg.clientID, "test-client-") || msg.username != "sshield" || msg.password != "secret" {
+		t.Fatalf("unexpected connect fields: %+v", msg)
+	}
+
+	// 并发发送时每个连接使用不同的客户端 ID，避免 broker 断开先建立的连接
+	if err := n.Send(testLoginEvent()); err != nil {
+		t.Fatal(err)
+	}
+	if next := broker.receive(t); next.clientID == msg.clientID || !strings.HasPrefix(next.clientID, "test-client-") {
+		t.Fatalf("client ID reused across connections: %s %s", msg.clientID, next.clientID)
+	}
+	var record eventRecord
+	if err := json.Unmarshal(msg.payload, &record); err != nil || record.User != "root" {
+		t.Fatalf("unexpected payload %s: %v", msg.payload, err)
+	}
+}
+
+func TestMQTTNotifierQoS0Topic(t *testing.T) {
+	broker := newFakeMQTTBroker(t, listenLocal(t), 0)
+	n, err := NewMQTTNotifier(&MQTTConfig{Broker: "mqtt://" + broker.ln.Addr().String(), Topic: "home/{{.User}}/{{.IP}}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Send(testLoginEvent()); err != nil {
+		t.Fatal(err)
+	}
+	if msg := broker.receive(t); msg.topic != "home/root/203.0.113.9" || msg.qos != 0 || msg.retain {
+		t.Fatalf("unexpected publish: %+v", msg)
+	}
+}
+
+func TestMQTTNotifierRejected(t *testing.T) {
+	broker := newFakeMQTTBroker(t, listenLocal(t), 5)
+	n, _ := NewMQTTNotifier(&MQTTConfig{Broker: "tcp://" + broker.ln.Addr().String()})
+	if err := n.Send(testLoginEvent()); err == nil || !strings.Contains(err.Error(), "未授权") {
+		t.Fatalf("expected not authorized error, got %v", err)
+	}
+}
+
+func TestMQTTNotifierTLS(t *testing.T) {
+	certPEM, keyPEM := selfSignedCert(t)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln := tls.NewListener(listenLocal(t), &tls.Config{Certificates: []tls.Certificate{cert}})
+	broker := newFakeMQTTBroker(t, ln, 0)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	n, _ := NewMQTTNotifier(&MQTTConfig{Broker: "ssl://localhost:" + port, CAFile: caFile})
+	if err := n.Send(testLoginEvent()); err != nil {
+		t.Fatal(err)
+	}
+	broker.receive(t)
+
+	// 未指定 CA 时自签名证书校验失败
+	n, _ = NewMQTTNotifier(&MQTTConfig{Broker: "ssl://localhost:" + port})
+	if err := n.Send(testLoginEvent()); err == nil {
+		t.Fatal("expected certificate verification error")
+	}
+}
+
+func selfSignedCert(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestMQTTPacketLength(t *testing.T) {
+	packet := mqttPacket(mqttPublish<<4, make([]byte, 321))
+	header, body, err := readMQTTPacket(bufio.NewReader(strings.NewReader(string(packet))))
+	if err != nil || header != mqttPublish<<4 || len(body) != 321 {
+		t.Fatalf("round trip failed: header %x len %d err %v", header, len(body), err)
+	}
+	if packet[1] != 0xC1 || packet[2] != 0x02 {
+		t.Fatalf("unexpected remaining length encoding: % x", packet[1:3])
+	}
+}
+
+func TestValidateMQTTChannel(t *testing.T) {
+	if err := ValidateChannelConfig(&ChannelConfig{Type: "mqtt", MQTT: &MQTTConfig{Broker: "tcp://10.0.0.5:1883"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, cfg := range []*MQTTConfig{
+		nil,
+		{Broker: "http://10.0.0.5"},
+		{Broker: "tcp://10.0.0.5", QoS: 2},
+		{Broker: "tcp://10.0.0.5", Topic: "ssh/{{.Nope}}"},
+		{Broker: "tcp://10.0.0.5", Topic: "ssh/#"},
+		{Broker: "tcp://10.0.0.5", Password: "secret"},
+	} {
+		if err := ValidateChannelConfig(&ChannelConfig{Type: "mqtt", MQTT: cfg}); err == nil {
+			t.Fatalf("expected validation error for %+v", cfg)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package notify
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// 多个日志源并行时，事件缓冲多久后按时间顺序交给下游
+	mergeDelay = time.Second
+	// 不同日志源中时间差在此范围内的相同事件视为同一事件
+	crossSourceDedupeWindow = 2 * time.Second
+)
+
+// eventHandler 接收解析后的事件
+type eventHandler interface {
+	handle(event *LoginEvent)
+}
+
+type mergedEvent struct {
+	event   *LoginEvent
+	arrived time.Time
+	seq     uint64 // 到达顺序，时间戳相同的事件按到达顺序交出
+}
+
+// mergeQueue 按事件时间排序的最小堆
+type mergeQueue []mergedEvent
+
+func (q mergeQueue) Len() int { return len(q) }
+func (q mergeQueue) Less(a, b int) bool {
+	if !q[a].event.Timestamp.Equal(q[b].event.Timestamp) {
+		return q[a].event.Timestamp.Before(q[b].event.Timestamp)
+	}
+	return q[a].seq < q[b].seq
+}
+func (q mergeQueue) Swap(a, b int) { q[a], q[b] = q[b], q[a] }
+func (q *mergeQueue) Push(x any)   { *q = append(*q, x.(mergedEvent)) }
+func (q *mergeQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// dedupeKey 跨日志源去重的索引键：事件内容相同且时间戳落在同一秒
+type dedupeKey struct {
+	typ, subtype, user, ip, message string
+	port                            int
+	second                          int64
+}
+
+func newDedupeKey(event *LoginEvent) dedupeKey {
+	return dedupeKey{
+		typ:     event.Type,
+		subtype: event.Subtype,
+		user:    event.User,
+		ip:      event.IP,
+		message: event.Message,
+		port:    event.Port,
+		second:  event.Timestamp.Unix(),
+	}
+}
+
+type dedupeEntry struct {
+	logPath   string
+	timestamp time.Time
+}
+
+// indexedEvent 按到达顺序记录的索引项，用于过期清理
+type indexedEvent struct {
+	key     dedupeKey
+	arrived time.Time
+}
+
+// eventMerger 合并多个日志源的事件：短暂缓冲后按时间顺序交给下游，
+// 同一事件同时出现在 journald 与转发的日志文件中时只保留先到的一条
+type eventMerger struct {
+	next  eventHandler
+	delay time.Duration
+	now   func() time.Time
+
+	mu      sync.Mutex
+	seq     uint64
+	pending mergeQueue
+	index   map[dedupeKey][]dedupeEntry // 近期事件（含尚未交出的），用于去重
+	indexed []indexedEvent
+}
+
+func newEventMerger(next eventHandler, delay time.Duration) *eventMerger {
+	return &eventMerger{next: next, delay: delay, now: time.Now, index: make(map[dedupeKey][]dedupeEntry)}
+}
+
+// handle 缓冲事件，来自其他日志源的重复事件直接丢弃。
+// 只需查找时间戳相邻几秒内内容相同的事件，sweep 一次读入大量历史事件时也不会逐条比较
+func (m *eventMerger) handle(event *LoginEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := newDedupeKey(event)
+	window := int64(crossSourceDedupeWindow / time.Second)
+	for second := key.second - window; second <= key.second+window; second++ {
+		k := key
+		k.second = second
+		for _, seen := range m.index[k] {
+			if isCrossSourceDuplicate(seen, event) {
+				debugf("notify: 跳过重复事件 %s（已来自 %s）", event.LogPath, seen.logPath)
+				return
+			}
+		}
+	}
+
+	now := m.now()
+	m.index[key] = append(m.index[key], dedupeEntry{logPath: event.LogPath, timestamp: event.Timestamp})
+	m.indexed = append(m.indexed, indexedEvent{key: key, arrived: now})
+	m.seq++
+	heap.Push(&m.pending, mergedEvent{event: event, arrived: now, seq: m.seq})
+}
+
+// flush 按时间顺序交出已缓冲足够久的事件，force 为 true 时交出全部
+func (m *eventMerger) flush(force bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	// 只交出有序前缀，更早的事件可能仍在其他日志源的路上
+	for m.pending.Len() > 0 && (force || now.Sub(m.pending[0].arrived) >= m.delay) {
+		item := heap.Pop(&m.pending).(mergedEvent)
+		m.next.handle(item.event)
+	}
+
+	// 其他日志源的延迟不会超过缓冲时间太多，过期的记录不再参与去重
+	n := 0
+	for n < len(m.indexed) && now.Sub(m.indexed[n].arrived) >= m.delay+crossSourceDedupeWindow*5 {
+		key := m.indexed[n].key
+		if entries := m.index[key][1:]; len(entries) > 0 {
+			m.index[key] = entries
+		} else {
+			delete(m.index, key)
+		}
+		n++
+	}
+	if n > 0 {
+		m.indexed = append(m.indexed[:0], m.indexed[n:]...)
+	}
+}
+
+// run 定期交出缓冲的事件，ctx 结束时交出剩余全部
+func (m *eventMerger) run(ctx context.Context) {
+	ticker := time.NewTicker(m.delay / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			m.flush(true)
+			return
+		case <-ticker.C:
+			m.flush(false)
+		}
+	}
+}
+
+// isCrossSourceDuplicate 判断索引中内容相同的事件是否为另一日志源中的同一条日志
+func isCrossSourceDuplicate(seen dedupeEntry, event *LoginEvent) bool {
+	if seen.logPath == event.LogPath {
+		return false
+	}
+	diff := seen.timestamp.Sub(event.Timestamp)
+	return diff <= crossSourceDedupeWindow && diff >= -crossSourceDedupeWindow
+}
+
+// existingLogFiles 返回所有存在的日志文件，路径支持通配符（如多个容器的 auth.log）
+func existingLogFiles(paths []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+		matches := []string{p}
+		if hasGlob(p) {
+			matches, _ = filepath.Glob(p)
+		}
+		for _, m := range matches {
+			if seen[m] {
+				continue
+			}
+			if info, err := os.Stat(m); err == nil && info.Mode().IsRegular() {
+				seen[m] = true
+				out = append(out, m)
+			}
+		}
+	}
+	return out
+}
+
+// logSourceRunner 运行单个日志源，直到结束或 ctx 取消
+type logSourceRunner struct {
+	name string
+	file string // 在 SourceState 中负责的日志文件，journald 为空
+	run  func(ctx context.Context, store *CursorStore, state *SourceState, handler eventHandler) error
+}
+
+// runLogSources 运行选定的日志源；多个日志源时并行运行，各自保存游标，事件合并后按时间顺序处理
+func runLogSources(ctx context.Context, store *CursorStore, state *SourceState, selection *sourceSelection, poll time.Duration, follow bool, since time.Duration, loc *time.Location, proc eventHandler) error {
+	var runners []logSourceRunner
+	if selection.Journal {
+		runners = append(runners, logSourceRunner{name: "journald", run: func(ctx context.Context, store *CursorStore, state *SourceState, handler eventHandler) error {
+			return runJournalWithFilter(ctx, store, state, selection.Match, selection.Reader, poll, follow, since, handler)
+		}})
+	}
+	for _, path := range selection.LogPaths {
+		path := path
+		runners = append(runners, logSourceRunner{name: path, file: path, run: func(ctx context.Context, store *CursorStore, state *SourceState, handler eventHandler) error {
+			if follow {
+				return followLogFileWithFilter(ctx, store, state, path, selection.Match, poll, loc, handler)
+			}
+			return sweepLogFileWithFilter(ctx, store, state, path, selection.Match, since, loc, handler)
+		}})
+	}
+
+	switch len(runners) {
+	case 0:
+		return fmt.Errorf("未选择任何日志源")
+	case 1:
+		return runners[0].run(ctx, store, state, proc)
+	}
+
+	merger := newEventMerger(proc, mergeDelay)
+	if !follow {
+		// 扫描模式依次读取全部日志源，再统一排序处理
+		var errs []error
+		for _, r := range runners {
+			subStore, subState := store.forSource(state, r.file)
+			if err := r.run(ctx, subStore, subState, merger); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", r.name, err))
+			}
+		}
+		merger.flush(true)
+		return errors.Join(errs...)
+	}
+
+	mergeCtx, stopMerge := context.WithCancel(context.Background())
+	mergeDone := make(chan struct{})
+	go func() {
+		merger.run(mergeCtx)
+		close(mergeDone)
+	}()
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(runners))
+	for i, r := range runners {
+		subStore, subState := store.forSource(state, r.file)
+		wg.Add(1)
+		go func(i int, r logSourceRunner) {
+			defer wg.Done()
+			if err := r.run(ctx, subStore, subState, merger); err != nil {
+				log.Printf("日志源 %s 退出: %v", r.name, err)
+				errs[i] = fmt.Errorf("%s: %w", r.name, err)
+			}
+		}(i, r)
+	}
+	wg.Wait()
+	stopMerge()
+	<-mergeDone
+	return errors.Join(errs...)
+}
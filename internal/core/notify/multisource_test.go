@@ -0,0 +1,184 @@
+package notify
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type collectHandler struct {
+	events []*LoginEvent
+}
+
+func (c *collectHandler) handle(event *LoginEvent) {
+	c.events = append(c.events, event)
+}
+
+func TestEventMergerOrderAndDedupe(t *testing.T) {
+	base := time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC)
+	out := &collectHandler{}
+	m := newEventMerger(out, time.Hour)
+
+	failed := func(path string, port int, offset time.Duration) *LoginEvent {
+		return &LoginEvent{Type: EventLoginFailed, User: "root", IP: "203.0.113.9", Port: port, LogPath: path,
+			Message: "Failed password for root", Timestamp: base.Add(offset)}
+	}
+	m.handle(failed("journald:ssh.service", 40002, 2*time.Second))
+	m.handle(failed("/var/log/secure", 40001, 0))
+	// 同一条日志经转发后出现在文件中，时间戳精度不同
+	m.handle(failed("/var/log/secure", 40002, 2*time.Second+500*time.Millisecond))
+	// 同一日志源内的相同内容不视为重复
+	m.handle(failed("journald:ssh.service", 40002, 3*time.Second))
+
+	m.flush(false)
+	if len(out.events) != 0 {
+		t.Fatalf("events released before delay: %d", len(out.events))
+	}
+	m.flush(true)
+	if len(out.events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(out.events))
+	}
+	if out.events[0].Port != 40001 || !out.events[1].Timestamp.Before(out.events[2].Timestamp) {
+		t.Fatalf("events not ordered by timestamp: %+v", out.events)
+	}
+
+	// 已交出的事件仍参与去重
+	m.handle(failed("/var/log/auth.log", 40001, time.Second))
+	m.flush(true)
+	if len(out.events) != 3 {
+		t.Fatalf("duplicate of released event was not dropped")
+	}
+}
+
+func TestEventMergerExpiresDedupeIndex(t *testing.T) {
+	now := time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC)
+	out := &collectHandler{}
+	m := newEventMerger(out, time.Second)
+	m.now = func() time.Time { return now }
+
+	event := func(path string) *LoginEvent {
+		return &LoginEvent{Type: EventLoginFailed, User: "root", IP: "203.0.113.9", Port: 40001, LogPath: path,
+			Message: "Failed password for root", Timestamp: now}
+	}
+	m.handle(event("journald:ssh.service"))
+	now = now.Add(time.Second)
+	m.flush(false)
+	if len(out.events) != 1 || len(m.index) != 1 {
+		t.Fatalf("expected one released and indexed event, got %d released, %d indexed", len(out.events), len(m.index))
+	}
+
+	now = now.Add(crossSourceDedupeWindow * 5)
+	m.flush(false)
+	if len(m.index) != 0 || len(m.indexed) != 0 {
+		t.Fatalf("expired events must leave the dedupe index: %d %d", len(m.index), len(m.indexed))
+	}
+}
+
+func TestEventMergerLargeSweep(t *testing.T) {
+	base := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	out := &collectHandler{}
+	m := newEventMerger(out, mergeDelay)
+
+	// 暴力破解主机上多天的日志：journald 与转发的日志文件各有一份，sweep 读完全部日志源后才统一交出
+	const n = 50000
+	for _, path := range []string{"journald:ssh.service", "/var/log/secure"} {
+		for i := 0; i < n; i++ {
+			m.handle(&LoginEvent{Type: EventLoginFailed, User: "root", IP: "203.0.113.9", Port: 40000 + i%20000, LogPath: path,
+				Message: "Failed password for root", Timestamp: base.Add(time.Duration(n-i) * 7 * time.Second)})
+		}
+	}
+	m.flush(true)
+	if len(out.events) != n {
+		t.Fatalf("expected %d events after dedupe, got %d", n, len(out.events))
+	}
+	for i := 1; i < len(out.events); i++ {
+		if out.events[i].Timestamp.Before(out.events[i-1].Timestamp) {
+			t.Fatalf("events not ordered at %d", i)
+		}
+	}
+}
+
+func TestCursorStoreForSource(t *testing.T) {
+	store, err := NewCursorStore(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := &SourceState{JournalCursor: "c1", FileOffsets: map[string]int64{"/a": 10, "/b": 20}}
+
+	journalStore, journalState := store.forSource(state, "")
+	fileStore, fileState := store.forSource(state, "/a")
+
+	fileState.FileOffsets["/a"] = 15
+	if err := fileStore.Save(fileState); err != nil {
+		t.Fatal(err)
+	}
+	// journald 的副本中文件偏移已过期，保存时不能覆盖
+	journalState.JournalCursor = "c2"
+	if err := journalStore.Save(journalState); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.JournalCursor != "c2" || loaded.FileOffsets["/a"] != 15 || loaded.FileOffsets["/b"] != 20 {
+		t.Fatalf("unexpected merged state: %+v", loaded)
+	}
+}
+
+func TestRunLogSourcesSweepMultipleFiles(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	line := func(ts time.Time, msg string) string {
+		return ts.Format("Jan _2 15:04:05") + " web sshd[100]: " + msg + "\n"
+	}
+	first := line(now.Add(-3*time.Minute), "Accepted password for alice from 203.0.113.9 port 40022 ssh2") +
+		line(now.Add(-time.Minute), "Failed password for root from 203.0.113.9 port 40024 ssh2")
+	second := line(now.Add(-2*time.Minute), "Failed password for root from 203.0.113.9 port 40023 ssh2") +
+		line(now.Add(-time.Minute), "Failed password for root from 203.0.113.9 port 40024 ssh2")
+	for name, content := range map[string]string{"a/auth.log": first, "b/auth.log": second} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paths := existingLogFiles([]string{filepath.Join(dir, "*", "auth.log"), filepath.Join(dir, "missing.log")})
+	if len(paths) != 2 {
+		t.Fatalf("unexpected paths: %v", paths)
+	}
+	store, err := NewCursorStore(filepath.Join(dir, "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := &SourceState{FileOffsets: make(map[string]int64)}
+	selection := &sourceSelection{Source: sourceFile, Match: newSourceMatcher(nil, nil, nil), LogPaths: paths}
+
+	out := &collectHandler{}
+	if err := runLogSources(context.Background(), store, state, selection, 0, false, time.Hour, nil, out); err != nil {
+		t.Fatal(err)
+	}
+	var ports []string
+	for _, e := range out.events {
+		ports = append(ports, strconv.Itoa(e.Port))
+	}
+	if strings.Join(ports, ",") != "40022,40023,40024" {
+		t.Fatalf("unexpected merged events: %v", ports)
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.FileOffsets[paths[0]] != int64(len(first)) || loaded.FileOffsets[paths[1]] != int64(len(second)) {
+		t.Fatalf("unexpected offsets: %+v", loaded.FileOffsets)
+	}
+}
@@ -12,8 +12,14 @@ import (
 
 var (
 	successRe = regexp.MustCompile(`^Accepted (\S+) for (\S+) from ([^ ]+) port (\d+)`)
-	failRe    = regexp.MustCompile(`^Failed (\S+) for (?:invalid user )?(\S+) from ([^ ]+) port (\d+)`)
-	syslogRe  = regexp.MustCompile(`^(Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec)\s+(\d{1,2})\s+(\d{2}:\d{2}:\d{2})\s+([^ ]+)\s+sshd(?:\[[^]]*\])?:\s+(.*)$`)
+	failRe    = regexp.MustCompile(`^Failed (\S+) for (invalid user )?(\S+) from ([^ ]+) port (\d+)`)
+
+	// 传统 syslog（RFC 3164）: "Jan  2 03:04:05 host sshd[123]: ..."，无年份与时区
+	syslogRe = regexp.MustCompile(`^(Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec)\s+(\d{1,2})\s+(\d{2}:\d{2}:\d{2})(?:\.\d+)?\s+([^ ]+)\s+` + syslogProcess + `(?:\[(\d*)\])?:\s+(.*)$`)
+	// rsyslog RSYSLOG_FileFormat / syslog-ng ISO 时间: "2025-01-02T03:04:05.123456+00:00 host sshd[123]: ..."
+	syslogISORe = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?)\s+([^ ]+)\s+` + syslogProcess + `(?:\[(\d*)\])?:\s+(.*)$`)
+	// RFC 5424: "<38>1 2025-01-02T03:04:05.123Z host sshd 123 - - ..."
+	syslog5424Re = regexp.MustCompile(`^<\d{1,3}>1 (\S+) (\S+) ` + syslogProcess + ` (\S+) \S+ (?:-|(?:\[(?:[^\]\\]|\\.)*\])+)(?: (.*))?$`)
 
 	// 认证过程中断开的连接（默认 LogLevel INFO 下可见）
 	// 匹配: "Disconnected from authenticating user root 1.1.1.1 port 51819 [preauth]"
@@ -21,7 +27,50 @@ var (
 	// 匹配: "Connection closed by 17.11.1.1 port 25124 [preauth]"
 	// 匹配: "Connection closed by authenticating user root 1.1.1.1 port 25124 [preauth]"
 	// 匹配: "Connection closed by invalid user tomcat 16.17.3.1 port 39352 [preauth]"
-	connectionClosedRe = regexp.MustCompile(`^Connection closed by (?:(?:authenticating|invalid) user (\S+) )?([^ ]+) port (\d+)`)
+	connectionClosedRe = regexp.MustCompile(`^Connection closed by (?:(authenticating|invalid) user (\S+) )?([^ ]+) port (\d+)`)
+
+	// 扫描器常见的探测日志，只用于扫描检测
+	// 匹配: "Did not receive identification string from 1.1.1.1 port 41234"
+	noIdentRe = regexp.MustCompile(`^Did not receive identification string from ([^ ]+) port (\d+)`)
+	// 匹配: "banner exchange: Connection from 1.1.1.1 port 41234: invalid format"
+	bannerExchangeRe = regexp.MustCompile(`^banner exchange: Connection from ([^ ]+) port (\d+):`)
+	// 匹配: "Bad protocol version identification 'GET / HTTP/1.1' from 1.1.1.1 port 41234"
+	badProtocolRe = regexp.MustCompile(`^Bad protocol version identification .* from ([^ ]+) port (\d+)`)
+	// 匹配: "Invalid user admin from 1.1.1.1 port 41234"
+	invalidUserRe = regexp.MustCompile(`^Invalid user (\S*) from ([^ ]+) port (\d+)`)
+
+	// 认证前阶段的细分失败类型
+	// 匹配: "Unable to negotiate with 1.1.1.1 port 41234: no matching key exchange method found."
+	unableNegotiateRe = regexp.MustCompile(`^Unable to negotiate with ([^ ]+) port (\d+):`)
+	// 匹配: "error: maximum authentication attempts exceeded for root from 1.1.1.1 port 41234 ssh2 [preauth]"
+	maxAuthRe = regexp.MustCompile(`^(?:error: )?maximum authentication attempts exceeded for (?:invalid user )?(\S+) from ([^ ]+) port (\d+)`)
+	// 匹配: "Disconnecting authenticating user root 1.1.1.1 port 41234: Too many authentication failures [preauth]"
+	tooManyAuthRe = regexp.MustCompile(`^Disconnecting (?:authenticating|invalid) user (\S+) ([^ ]+) port (\d+): Too many authentication failures`)
+	// 匹配: "drop connection #10 from [1.1.1.1]:41234 on [10.0.0.1]:22 past MaxStartups"
+	maxStartupsDropRe = regexp.MustCompile(`^drop connection #\d+ from \[([^\]]+)\]:(\d+) on .* past MaxStartups`)
+
+	// 会话生命周期日志，与 Accepted 事件按 PID/IP/端口关联
+	// 匹配: "pam_unix(sshd:session): session opened for user alice(uid=1000) by (uid=0)"
+	sessionOpenedRe = regexp.MustCompile(`^pam_unix\(sshd:session\): session opened for user ([^ (]+)`)
+	// 匹配: "pam_unix(sshd:session): session closed for user alice"
+	sessionClosedRe = regexp.MustCompile(`^pam_unix\(sshd:session\): session closed for user (\S+)`)
+	// 匹配: "Disconnected from user alice 1.1.1.1 port 51819"
+	disconnectUserRe = regexp.MustCompile(`^Disconnected from user (\S+) ([^ ]+) port (\d+)`)
+	// 匹配: "Received disconnect from 1.1.1.1 port 51819:11: disconnected by user"
+	receivedDisconnectRe = regexp.MustCompile(`^Received disconnect from ([^ ]+) port (\d+):\d+: disconnected by user`)
+)
+
+// syslog 中的进程名（syslog 标识），是否为 sshd 由 sourceMatcher 判断
+const syslogProcess = `([^\s\[:]+)`
+
+// 认证前阶段断开，无法确定具体认证方式
+const preauthMethod = "unknown[preauth]"
+
+// 扫描探测类型，记录在探测事件的 Method 中
+const (
+	probeNoIdent     = "no-ident"
+	probeProtocol    = "protocol"
+	probeInvalidUser = "invalid-user"
 )
 
 func parseJournalMessage(message, host string, ts time.Time) (*LoginEvent, bool) {
@@ -41,24 +90,43 @@ func parseJournalMessage(message, host string, ts time.Time) (*LoginEvent, bool)
 			Timestamp: ts,
 			Hostname:  host,
 			Message:   message,
-			Location:  LookupIPLocation(ip),
 			HostIP:    getHostIP(),
 		}, true
 	}
 
-	if matches := failRe.FindStringSubmatch(message); len(matches) == 5 {
-		port, _ := strconv.Atoi(matches[4])
-		ip := stripAddress(matches[3])
+	if matches := failRe.FindStringSubmatch(message); len(matches) == 6 {
+		subtype := SubtypeAuthFailed
+		if matches[2] != "" {
+			subtype = SubtypeInvalidUser
+		}
+		return newFailureEvent(subtype, matches[3], matches[4], matches[5], normalizeMethod(matches[1]), message, host, ts), true
+	}
+
+	// "error: maximum authentication attempts exceeded for root from 1.1.1.1 port 51819 ssh2 [preauth]"
+	if matches := maxAuthRe.FindStringSubmatch(message); len(matches) == 4 {
+		return newFailureEvent(SubtypeMaxAuthExceeded, matches[1], matches[2], matches[3], preauthMethod, message, host, ts), true
+	}
+	if matches := tooManyAuthRe.FindStringSubmatch(message); len(matches) == 4 {
+		return newFailureEvent(SubtypeMaxAuthExceeded, matches[1], matches[2], matches[3], preauthMethod, message, host, ts), true
+	}
+
+	// 密钥交换失败（客户端算法不兼容或扫描器）
+	if matches := unableNegotiateRe.FindStringSubmatch(message); len(matches) == 3 {
+		return newFailureEvent(SubtypeKexFailed, "", matches[1], matches[2], preauthMethod, message, host, ts), true
+	}
+
+	// 超过 MaxStartups 被丢弃的连接
+	if matches := maxStartupsDropRe.FindStringSubmatch(message); len(matches) == 3 {
+		return newFailureEvent(SubtypeTooManyConnections, "", matches[1], matches[2], preauthMethod, message, host, ts), true
+	}
+	if strings.HasPrefix(message, "beginning MaxStartups throttling") {
 		return &LoginEvent{
 			Type:      EventLoginFailed,
-			User:      matches[2],
-			IP:        ip,
-			Method:    normalizeMethod(matches[1]),
-			Port:      port,
+			Subtype:   SubtypeTooManyConnections,
+			Method:    preauthMethod,
 			Timestamp: ts,
 			Hostname:  host,
 			Message:   message,
-			Location:  LookupIPLocation(ip),
 			HostIP:    getHostIP(),
 		}, true
 	}
@@ -66,51 +134,124 @@ func parseJournalMessage(message, host string, ts time.Time) (*LoginEvent, bool)
 	// 匹配认证过程中断开（默认 LogLevel INFO 下可见，归类为登录失败）
 	// "Disconnected from authenticating user root 1.1.1.1 port 51819 [preauth]"
 	if matches := disconnectAuthRe.FindStringSubmatch(message); len(matches) == 4 {
-		port, _ := strconv.Atoi(matches[3])
-		ip := stripAddress(matches[2])
-		return &LoginEvent{
-			Type:      EventLoginFailed,
-			User:      matches[1],
-			IP:        ip,
-			Method:    "unknown[preauth]", //认证前阶段断开，无法确定具体方式 // "unknown",
-			Port:      port,
-			Timestamp: ts,
-			Hostname:  host,
-			Message:   message,
-			Location:  LookupIPLocation(ip),
-			HostIP:    getHostIP(),
-		}, true
+		return newFailureEvent(SubtypePreauthDisconnect, matches[1], matches[2], matches[3], preauthMethod, message, host, ts), true
 	}
 
 	// 匹配连接关闭（preauth 阶段，归类为登录失败）
 	// "Connection closed by 1.1.1.1 port 25124 [preauth]"
 	// "Connection closed by authenticating user root 1.1.1.1 port 25124 [preauth]"
+	// "Connection closed by invalid user tomcat 1.1.1.1 port 25124 [preauth]"
 	if strings.Contains(message, "[preauth]") {
-		if matches := connectionClosedRe.FindStringSubmatch(message); len(matches) == 4 {
-			port, _ := strconv.Atoi(matches[3])
-			ip := stripAddress(matches[2])
-			user := matches[1]
+		if matches := connectionClosedRe.FindStringSubmatch(message); len(matches) == 5 {
+			subtype := SubtypePreauthDisconnect
+			if matches[1] == "invalid" {
+				subtype = SubtypeInvalidUser
+			}
+			user := matches[2]
 			if user == "" {
 				user = "unknown"
 			}
-			return &LoginEvent{
-				Type:      EventLoginFailed,
-				User:      user,
-				IP:        ip,
-				Method:    "unknown[preauth]", //认证前阶段断开，无法确定具体方式 // "unknown",
-				Port:      port,
-				Timestamp: ts,
-				Hostname:  host,
-				Message:   message,
-				Location:  LookupIPLocation(ip),
-				HostIP:    getHostIP(),
-			}, true
+			return newFailureEvent(subtype, user, matches[3], matches[4], preauthMethod, message, host, ts), true
 		}
 	}
 
+	if event, ok := parsePrivilegeMessage(message, host, ts); ok {
+		return event, true
+	}
+
+	if event, ok := parseAccountMessage(message, host, ts); ok {
+		return event, true
+	}
+
+	if event, ok := parseSessionMessage(message, host, ts); ok {
+		return event, true
+	}
+
+	return parseScanProbe(message, host, ts)
+}
+
+// parseSessionMessage 解析会话打开/关闭日志，IP 与地理位置在关联 Accepted 事件时补全
+func parseSessionMessage(message, host string, ts time.Time) (*LoginEvent, bool) {
+	event := &LoginEvent{
+		Timestamp: ts,
+		Hostname:  host,
+		Message:   message,
+	}
+
+	if matches := sessionOpenedRe.FindStringSubmatch(message); len(matches) == 2 {
+		event.Type = EventSessionOpened
+		event.User = matches[1]
+		return event, true
+	}
+	if matches := sessionClosedRe.FindStringSubmatch(message); len(matches) == 2 {
+		event.Type = EventSessionClosed
+		event.User = matches[1]
+		return event, true
+	}
+	if matches := disconnectUserRe.FindStringSubmatch(message); len(matches) == 4 {
+		event.Type = EventSessionClosed
+		event.User = matches[1]
+		event.IP = stripAddress(matches[2])
+		event.Port, _ = strconv.Atoi(matches[3])
+		return event, true
+	}
+	if !strings.Contains(message, "[preauth]") {
+		if matches := receivedDisconnectRe.FindStringSubmatch(message); len(matches) == 3 {
+			event.Type = EventSessionClosed
+			event.IP = stripAddress(matches[1])
+			event.Port, _ = strconv.Atoi(matches[2])
+			return event, true
+		}
+	}
 	return nil, false
 }
 
+// newFailureEvent 构造登录失败事件，subtype 区分具体失败原因
+func newFailureEvent(subtype, user, addr, portStr, method, message, host string, ts time.Time) *LoginEvent {
+	port, _ := strconv.Atoi(portStr)
+	ip := stripAddress(addr)
+	return &LoginEvent{
+		Type:      EventLoginFailed,
+		Subtype:   subtype,
+		User:      user,
+		IP:        ip,
+		Method:    method,
+		Port:      port,
+		Timestamp: ts,
+		Hostname:  host,
+		Message:   message,
+		HostIP:    getHostIP(),
+	}
+}
+
+// parseScanProbe 解析扫描器留下的未认证/协议异常日志
+func parseScanProbe(message, host string, ts time.Time) (*LoginEvent, bool) {
+	var user, kind, addr, portStr string
+	if matches := noIdentRe.FindStringSubmatch(message); len(matches) == 3 {
+		kind, addr, portStr = probeNoIdent, matches[1], matches[2]
+	} else if matches := bannerExchangeRe.FindStringSubmatch(message); len(matches) == 3 {
+		kind, addr, portStr = probeProtocol, matches[1], matches[2]
+	} else if matches := badProtocolRe.FindStringSubmatch(message); len(matches) == 3 {
+		kind, addr, portStr = probeProtocol, matches[1], matches[2]
+	} else if matches := invalidUserRe.FindStringSubmatch(message); len(matches) == 4 {
+		kind, user, addr, portStr = probeInvalidUser, matches[1], matches[2], matches[3]
+	} else {
+		return nil, false
+	}
+
+	port, _ := strconv.Atoi(portStr)
+	return &LoginEvent{
+		Type:      EventScanProbe,
+		User:      user,
+		IP:        stripAddress(addr),
+		Method:    kind,
+		Port:      port,
+		Timestamp: ts,
+		Hostname:  host,
+		Message:   message,
+	}, true
+}
+
 func normalizeMethod(method string) string {
 	method = strings.ToLower(method)
 	switch method {
@@ -166,37 +307,89 @@ func getHostIP() string {
 	return cachedHostIP
 }
 
-func parseAuthLogLine(line string) (*LoginEvent, bool) {
+// parseAuthLogLine 解析 auth.log/secure 中的一行，支持传统 syslog、ISO 8601 与 RFC 5424 前缀
+// loc 为不含时区的时间戳所使用的时区，nil 表示本机时区；match 为 nil 时使用默认的 sshd 标识
+func parseAuthLogLine(line string, loc *time.Location, match *sourceMatcher) (*LoginEvent, bool) {
 	if line == "" {
 		return nil, false
 	}
+	if loc == nil {
+		loc = time.Local
+	}
+	if match == nil {
+		match = newSourceMatcher(nil, nil, nil)
+	}
 
-	matches := syslogRe.FindStringSubmatch(line)
-	if len(matches) != 6 {
+	var (
+		ts                           time.Time
+		host, ident, pidStr, message string
+	)
+	if matches := syslogRe.FindStringSubmatch(line); len(matches) == 8 {
+		var ok bool
+		if ts, ok = parseSyslogTimestamp(matches[1], matches[2], matches[3], loc, time.Now()); !ok {
+			return nil, false
+		}
+		host, ident, pidStr, message = matches[4], matches[5], matches[6], matches[7]
+	} else if matches := syslogISORe.FindStringSubmatch(line); len(matches) == 6 {
+		var ok bool
+		if ts, ok = parseISOTimestamp(matches[1], loc); !ok {
+			return nil, false
+		}
+		host, ident, pidStr, message = matches[2], matches[3], matches[4], matches[5]
+	} else if matches := syslog5424Re.FindStringSubmatch(line); len(matches) == 6 {
+		var ok bool
+		if ts, ok = parseISOTimestamp(matches[1], loc); !ok {
+			return nil, false
+		}
+		host, ident, pidStr = matches[2], matches[3], matches[4]
+		message = strings.TrimPrefix(matches[5], "\ufeff")
+	} else {
 		return nil, false
 	}
 
-	month := matches[1]
-	day, err := strconv.Atoi(matches[2])
-	if err != nil {
+	if !match.matchIdentifier(ident) {
 		return nil, false
 	}
-	clock := matches[3]
-	host := matches[4]
-	message := matches[5]
 
-	now := time.Now()
+	event, ok := parseJournalMessage(message, host, ts)
+	if ok {
+		event.PID, _ = strconv.Atoi(pidStr)
+	}
+	return event, ok
+}
+
+// parseSyslogTimestamp 解析不含年份的传统 syslog 时间，年份取 now 所在年
+func parseSyslogTimestamp(month, dayStr, clock string, loc *time.Location, now time.Time) (time.Time, bool) {
+	day, err := strconv.Atoi(dayStr)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	now = now.In(loc)
 	layout := "Jan 2 15:04:05 2006"
 	timestampStr := fmt.Sprintf("%s %d %s %d", month, day, clock, now.Year())
-	ts, err := time.ParseInLocation(layout, timestampStr, shanghaiLocation)
+	ts, err := time.ParseInLocation(layout, timestampStr, loc)
 	if err != nil {
-		return nil, false
+		return time.Time{}, false
 	}
 
 	// 处理跨年日志：如果解析结果在未来较远时间，向前调整一年
 	if ts.After(now.Add(24 * time.Hour)) {
 		ts = ts.AddDate(-1, 0, 0)
 	}
+	return ts, true
+}
 
-	return parseJournalMessage(message, host, ts)
+// parseISOTimestamp 解析 ISO 8601 时间，不含时区时使用 loc
+func parseISOTimestamp(s string, loc *time.Location) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999Z0700"} {
+		if ts, err := time.Parse(layout, s); err == nil {
+			return ts, true
+		}
+	}
+	ts, err := time.ParseInLocation("2006-01-02T15:04:05.999999999", s, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return ts, true
 }
@@ -0,0 +1,132 @@
+package notify
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFailureSubtypes(t *testing.T) {
+	ts := time.Now()
+	cases := []struct {
+		message string
+		subtype string
+		user    string
+		ip      string
+	}{
+		{"Failed password for root from 203.0.113.9 port 40022 ssh2", SubtypeAuthFailed, "root", "203.0.113.9"},
+		{"Failed password for invalid user oracle from 203.0.113.9 port 40022 ssh2", SubtypeInvalidUser, "oracle", "203.0.113.9"},
+		{"Connection closed by invalid user tomcat 203.0.113.9 port 40022 [preauth]", SubtypeInvalidUser, "tomcat", "203.0.113.9"},
+		{"Connection closed by authenticating user root 203.0.113.9 port 40022 [preauth]", SubtypePreauthDisconnect, "root", "203.0.113.9"},
+		{"Connection closed by 203.0.113.9 port 40022 [preauth]", SubtypePreauthDisconnect, "unknown", "203.0.113.9"},
+		{"Disconnected from authenticating user root 203.0.113.9 port 40022 [preauth]", SubtypePreauthDisconnect, "root", "203.0.113.9"},
+		{"error: maximum authentication attempts exceeded for invalid user admin from 203.0.113.9 port 40022 ssh2 [preauth]", SubtypeMaxAuthExceeded, "admin", "203.0.113.9"},
+		{"Disconnecting authenticating user root 203.0.113.9 port 40022: Too many authentication failures [preauth]", SubtypeMaxAuthExceeded, "root", "203.0.113.9"},
+		{"Unable to negotiate with 203.0.113.9 port 40022: no matching key exchange method found. Their offer: diffie-hellman-group1-sha1 [preauth]", SubtypeKexFailed, "", "203.0.113.9"},
+		{"drop connection #10 from [203.0.113.9]:40022 on [10.0.0.1]:22 past MaxStartups", SubtypeTooManyConnections, "", "203.0.113.9"},
+		{"beginning MaxStartups throttling", SubtypeTooManyConnections, "", ""},
+	}
+	for _, tc := range cases {
+		event, ok := parseJournalMessage(tc.message, "host", ts)
+		if !ok {
+			t.Fatalf("expected event for %q", tc.message)
+		}
+		if event.Type != EventLoginFailed || event.Subtype != tc.subtype || event.User != tc.user || event.IP != tc.ip {
+			t.Fatalf("unexpected event for %q: %+v", tc.message, event)
+		}
+	}
+}
+
+func TestEventSeverity(t *testing.T) {
+	cases := []struct {
+		event    LoginEvent
+		severity string
+	}{
+		{LoginEvent{Type: EventLoginSuccess}, SeverityMedium},
+		{LoginEvent{Type: EventLoginSuccess, User: "root"}, SeverityHigh},
+		{LoginEvent{Type: EventLoginSuccess, Action: "已终止会话"}, SeverityCritical},
+		{LoginEvent{Type: EventLoginFailed, Subtype: SubtypeInvalidUser}, SeverityLow},
+		{LoginEvent{Type: EventLoginFailed, Subtype: SubtypeKexFailed}, SeverityInfo},
+		{LoginEvent{Type: EventLoginFailed, Subtype: SubtypeTooManyConnections}, SeverityHigh},
+		{LoginEvent{Type: EventAuthorizedKeysChanged}, SeverityCritical},
+	}
+	for _, tc := range cases {
+		if got := eventSeverity(&tc.event, severityPolicy{}); got != tc.severity {
+			t.Fatalf("eventSeverity(%s/%s) = %s, want %s", tc.event.Type, tc.event.Subtype, got, tc.severity)
+		}
+	}
+}
+
+func TestNotifyOnSubtype(t *testing.T) {
+	on, err := parseNotifyOn("success,invalid_user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !on.matches(&LoginEvent{Type: EventLoginSuccess}) || !on.matches(&LoginEvent{Type: EventLoginFailed, Subtype: SubtypeInvalidUser}) {
+		t.Fatalf("expected success and invalid_user to match %q", on)
+	}
+	if on.matches(&LoginEvent{Type: EventLoginFailed, Subtype: SubtypeAuthFailed}) {
+		t.Fatalf("auth_failed should not match %q", on)
+	}
+
+	on, _ = parseNotifyOn("login_failed")
+	if !on.matches(&LoginEvent{Type: EventLoginFailed, Subtype: SubtypeKexFailed}) {
+		t.Fatalf("login_failed should match all of its subtypes")
+	}
+}
+
+func TestParseAuthLogLineFormats(t *testing.T) {
+	utc := time.UTC
+	cases := []struct {
+		line string
+		want time.Time
+		host string
+		pid  int
+	}{
+		{
+			"2025-01-02T03:04:05.123456+08:00 web sshd[123]: Accepted password for alice from 203.0.113.9 port 40022 ssh2",
+			time.Date(2025, 1, 1, 19, 4, 5, 123456000, utc), "web", 123,
+		},
+		{
+			"2025-01-02T03:04:05Z web sshd-session[456]: Failed password for root from 203.0.113.9 port 40022 ssh2",
+			time.Date(2025, 1, 2, 3, 4, 5, 0, utc), "web", 456,
+		},
+		{
+			"2025-01-02T03:04:05.5 web sshd[7]: Failed password for root from 203.0.113.9 port 40022 ssh2",
+			time.Date(2025, 1, 2, 3, 4, 5, 500000000, utc), "web", 7,
+		},
+		{
+			"<38>1 2025-01-02T03:04:05.000Z web sshd 789 - - Accepted publickey for alice from 203.0.113.9 port 40022 ssh2",
+			time.Date(2025, 1, 2, 3, 4, 5, 0, utc), "web", 789,
+		},
+		{
+			`<38>1 2025-01-02T03:04:05Z web sshd-session 789 - [meta sequenceId="1"] Accepted publickey for alice from 203.0.113.9 port 40022 ssh2`,
+			time.Date(2025, 1, 2, 3, 4, 5, 0, utc), "web", 789,
+		},
+	}
+	for _, tc := range cases {
+		event, ok := parseAuthLogLine(tc.line, utc, nil)
+		if !ok {
+			t.Fatalf("expected event for %q", tc.line)
+		}
+		if !event.Timestamp.Equal(tc.want) || event.Hostname != tc.host || event.PID != tc.pid {
+			t.Fatalf("unexpected event for %q: %+v", tc.line, event)
+		}
+	}
+}
+
+func TestParseSyslogTimestamp(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*3600)
+	now := time.Date(2025, 1, 1, 2, 0, 0, 0, time.UTC) // 当地时间 2024-12-31 21:00
+
+	ts, ok := parseSyslogTimestamp("Dec", "31", "20:00:00", loc, now)
+	if !ok || !ts.Equal(time.Date(2024, 12, 31, 20, 0, 0, 0, loc)) {
+		t.Fatalf("unexpected timestamp: %v", ts)
+	}
+
+	// 一月初读取去年十二月的日志
+	now = time.Date(2025, 1, 2, 12, 0, 0, 0, loc)
+	ts, ok = parseSyslogTimestamp("Dec", "30", "08:00:00", loc, now)
+	if !ok || ts.Year() != 2024 {
+		t.Fatalf("expected previous year, got %v", ts)
+	}
+}
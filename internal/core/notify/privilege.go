@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// 匹配 sudo 日志: "alice : TTY=pts/0 ; PWD=/home/alice ; USER=root ; COMMAND=/usr/bin/apt update"
+	// 失败时在 TTY 前多出原因: "alice : 3 incorrect password attempts ; TTY=pts/0 ; ..."
+	// 无终端执行（脚本、ssh 不带 -t）时没有 TTY 字段；原因中不含 '='，以免把 TTY=... 误认为原因
+	sudoRe = regexp.MustCompile(`^\s*(\S+) : (?:([^=;]+?) ; )?(?:TTY=\S+ ; )?PWD=.* ; USER=(\S+) ;(?: .*;)? COMMAND=(.*)$`)
+	// 匹配: "pam_unix(su:session): session opened for user root(uid=0) by alice(uid=1000)"
+	suSessionRe = regexp.MustCompile(`^pam_unix\(su(?:-l)?:session\): session opened for user ([^ (]+)(?:\(uid=\d+\))? by ([^ (]*)`)
+	// 匹配: "FAILED SU (to root) alice on pts/0"
+	suFailedRe = regexp.MustCompile(`^FAILED SU \(to (\S+)\) (\S+) on`)
+	// 匹配: "pam_unix(su:auth): authentication failure; logname=alice uid=1000 euid=0 tty=/dev/pts/0 ruser=alice rhost=  user=root"
+	suAuthFailRe = regexp.MustCompile(`^pam_unix\(su(?:-l)?:auth\): authentication failure;.*\bruser=(\S*).*\buser=(\S+)`)
+)
+
+// notifyOnPrivilege 通知所有提权事件（sudo/su）
+const notifyOnPrivilege = "privilege"
+
+// isPrivilegeEvent 判断是否为提权事件
+func isPrivilegeEvent(eventType string) bool {
+	switch eventType {
+	case EventSudoCommand, EventSudoFailed, EventSuSession, EventSuFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+// parsePrivilegeMessage 解析 sudo/su 日志，来源 IP 在关联 SSH 会话时补全
+func parsePrivilegeMessage(message, host string, ts time.Time) (*LoginEvent, bool) {
+	event := &LoginEvent{
+		Timestamp: ts,
+		Hostname:  host,
+		Message:   message,
+	}
+
+	if matches := sudoRe.FindStringSubmatch(message); len(matches) == 5 {
+		event.User = matches[1]
+		event.TargetUser = matches[3]
+		event.Command = strings.TrimSpace(matches[4])
+		event.Method = "sudo"
+		event.Type = EventSudoCommand
+		// sudo 仅在拒绝时于 TTY 前记录原因（密码错误、不在 sudoers 中等）
+		if matches[2] != "" {
+			event.Type = EventSudoFailed
+		}
+		return event, true
+	}
+
+	if matches := suSessionRe.FindStringSubmatch(message); len(matches) == 3 {
+		event.Type = EventSuSession
+		event.TargetUser = matches[1]
+		event.User = matches[2]
+		event.Method = "su"
+		return event, true
+	}
+
+	if matches := suFailedRe.FindStringSubmatch(message); len(matches) == 3 {
+		event.Type = EventSuFailed
+		event.TargetUser = matches[1]
+		event.User = matches[2]
+		event.Method = "su"
+		return event, true
+	}
+
+	if matches := suAuthFailRe.FindStringSubmatch(message); len(matches) == 3 {
+		event.Type = EventSuFailed
+		event.User = matches[1]
+		event.TargetUser = matches[2]
+		event.Method = "su"
+		return event, true
+	}
+
+	return nil, false
+}
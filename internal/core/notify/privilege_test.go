@@ -0,0 +1,129 @@
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParsePrivilegeMessage(t *testing.T) {
+	ts := time.Now()
+	cases := []struct {
+		message string
+		typ     string
+		user    string
+		target  string
+		command string
+	}{
+		{"alice : TTY=pts/0 ; PWD=/home/alice ; USER=root ; COMMAND=/usr/bin/apt update", EventSudoCommand, "alice", "root", "/usr/bin/apt update"},
+		{"    alice : TTY=pts/1 ; PWD=/srv ; USER=postgres ; ENV=FOO=bar ; COMMAND=/usr/bin/psql", EventSudoCommand, "alice", "postgres", "/usr/bin/psql"},
+		{"alice : 3 incorrect password attempts ; TTY=pts/0 ; PWD=/home/alice ; USER=root ; COMMAND=/bin/ls", EventSudoFailed, "alice", "root", "/bin/ls"},
+		{"bob : user NOT in sudoers ; TTY=pts/0 ; PWD=/home/bob ; USER=root ; COMMAND=/bin/bash", EventSudoFailed, "bob", "root", "/bin/bash"},
+		// 无终端执行（脚本、ssh 不带 -t、Ansible）时没有 TTY 字段
+		{"deploy : PWD=/home/deploy ; USER=root ; COMMAND=/usr/bin/systemctl restart app", EventSudoCommand, "deploy", "root", "/usr/bin/systemctl restart app"},
+		{"deploy : a password is required ; PWD=/home/deploy ; USER=root ; COMMAND=/usr/bin/id", EventSudoFailed, "deploy", "root", "/usr/bin/id"},
+		{"pam_unix(su:session): session opened for user root(uid=0) by alice(uid=1000)", EventSuSession, "alice", "root", ""},
+		{"pam_unix(su-l:session): session opened for user postgres by alice(uid=1000)", EventSuSession, "alice", "postgres", ""},
+		{"FAILED SU (to root) alice on pts/0", EventSuFailed, "alice", "root", ""},
+		{"pam_unix(su:auth): authentication failure; logname=alice uid=1000 euid=0 tty=/dev/pts/0 ruser=alice rhost=  user=root", EventSuFailed, "alice", "root", ""},
+	}
+	for _, tc := range cases {
+		event, ok := parseJournalMessage(tc.message, "host", ts)
+		if !ok {
+			t.Fatalf("expected event for %q", tc.message)
+		}
+		if event.Type != tc.typ || event.User != tc.user || event.TargetUser != tc.target || event.Command != tc.command {
+			t.Fatalf("unexpected event for %q: %+v", tc.message, event)
+		}
+	}
+
+	if _, ok := parsePrivilegeMessage("pam_unix(sudo:session): session opened for user root(uid=0) by alice(uid=1000)", "host", ts); ok {
+		t.Fatalf("sudo pam session should be ignored")
+	}
+}
+
+func TestParseAuthLogLineSudo(t *testing.T) {
+	event, ok := parseAuthLogLine("Mar  3 10:00:00 web sudo:    alice : TTY=pts/0 ; PWD=/home/alice ; USER=root ; COMMAND=/usr/bin/id", nil, nil)
+	if !ok || event.Type != EventSudoCommand || event.Command != "/usr/bin/id" {
+		t.Fatalf("unexpected event: %+v ok=%v", event, ok)
+	}
+}
+
+func TestNotifyOnMatches(t *testing.T) {
+	on, err := parseNotifyOn("Success, sudo_command")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !on.matches(&LoginEvent{Type: EventLoginSuccess}) || !on.matches(&LoginEvent{Type: EventSudoCommand}) || on.matches(&LoginEvent{Type: EventSuSession}) || on.matches(&LoginEvent{Type: EventLoginFailed}) {
+		t.Fatalf("unexpected matches for %q", on)
+	}
+
+	on, _ = parseNotifyOn("privilege")
+	if !on.matches(&LoginEvent{Type: EventSuFailed}) || on.matches(&LoginEvent{Type: EventLoginSuccess}) {
+		t.Fatalf("privilege should match only sudo/su events")
+	}
+
+	on, _ = parseNotifyOn("failed")
+	if !on.matches(&LoginEvent{Type: EventSudoFailed}) || on.matches(&LoginEvent{Type: EventSudoCommand}) {
+		t.Fatalf("failed should include sudo failures")
+	}
+
+	if on, _ := parseNotifyOn("success,all"); on != NotifyOnAll {
+		t.Fatalf("all should override other values, got %q", on)
+	}
+	if _, err := parseNotifyOn("bogus"); err == nil {
+		t.Fatalf("expected error for unknown type")
+	}
+}
+
+func TestSessionTrackerAttach(t *testing.T) {
+	root := t.TempDir()
+	old := procRoot
+	procRoot = root
+	defer func() { procRoot = old }()
+
+	// sudo(300) -> bash(200) -> sshd(100)
+	for pid, stat := range map[string]string{
+		"300": "300 (sudo) S 200 300 200 0",
+		"200": "200 (bash) S 100 200 200 0",
+		"100": "100 (sshd-session) S 1 100 100 0",
+		// 控制台登录中的 sudo(400) -> bash(350) -> login(340)，祖先中没有 sshd 会话
+		"400": "400 (sudo) S 350 400 350 0",
+		"350": "350 (bash) S 340 350 350 0",
+		"340": "340 (login) S 1 340 340 0",
+	} {
+		if err := os.MkdirAll(filepath.Join(root, pid), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, pid, "stat"), []byte(stat), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now()
+	tracker := &sessionTracker{sessions: []*sessionRecord{
+		{User: "alice", IP: "203.0.113.1", Port: 1111, PID: 100, Start: now.Add(-time.Hour)},
+		{User: "alice", IP: "203.0.113.2", Port: 2222, PID: 999, Start: now},
+	}}
+
+	event := &LoginEvent{Type: EventSudoCommand, User: "alice", PID: 300}
+	tracker.attach(event)
+	if event.IP != "203.0.113.1" || event.Port != 1111 {
+		t.Fatalf("expected process-tree match, got %s:%d", event.IP, event.Port)
+	}
+
+	// 祖先进程中没有 sshd 会话（控制台、cron 或本地 sudo）时不能借用同一用户其他会话的来源
+	event = &LoginEvent{Type: EventSudoCommand, User: "alice", PID: 400}
+	tracker.attach(event)
+	if event.IP != "" || event.Port != 0 || event.Location != "" {
+		t.Fatalf("console sudo must not be attributed to an ssh session, got %s:%d", event.IP, event.Port)
+	}
+
+	// 进程已退出、无法确认会话时同样不补全来源
+	event = &LoginEvent{Type: EventSuSession, User: "alice", PID: 12345}
+	tracker.attach(event)
+	if event.IP != "" {
+		t.Fatalf("unresolvable process must not be attributed, got %s", event.IP)
+	}
+}
@@ -0,0 +1,331 @@
+package notify
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultNtfyServer = "https://ntfy.sh"
+	defaultBarkServer = "https://api.day.app"
+	pushoverEndpoint  = "https://api.pushover.net/1/messages.json"
+
+	// Pushover 紧急级别消息的默认重发间隔与持续时间（秒），接口要求 retry >= 30、expire <= 10800
+	defaultPushoverRetry  = 60
+	defaultPushoverExpire = 3600
+)
+
+// pushSeverity 返回事件级别，测试消息等未标注级别的事件按 medium 处理
+func pushSeverity(event LoginEvent) string {
+	if event.Severity == "" {
+		return SeverityMedium
+	}
+	return event.Severity
+}
+
+// pushMessage 手机推送的正文：每行一个字段，最后附原始日志
+func pushMessage(event LoginEvent) string {
+	var b strings.Builder
+	for _, f := range eventFields(event) {
+		fmt.Fprintf(&b, "%s: %s\n", f.Label, f.Value)
+	}
+	if event.Message != "" {
+		b.WriteString("\n" + event.Message)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// NtfyNotifier 通过 ntfy 主题推送
+type NtfyNotifier struct {
+	server     string
+	cfg        NtfyConfig
+	httpClient *http.Client
+}
+
+// NewNtfyNotifier 创建 ntfy 通知器
+func NewNtfyNotifier(cfg *NtfyConfig) *NtfyNotifier {
+	server := strings.TrimRight(cfg.Server, "/")
+	if server == "" {
+		server = defaultNtfyServer
+	}
+	return &NtfyNotifier{server: server, cfg: *cfg, httpClient: newChannelHTTPClient()}
+}
+
+// ntfyPriority ntfy 优先级 1（min）到 5（max），max 会持续振动并绕过勿扰
+func ntfyPriority(severity string) int {
+	switch severity {
+	case SeverityCritical:
+		return 5
+	case SeverityHigh:
+		return 4
+	case SeverityLow:
+		return 2
+	case SeverityInfo:
+		return 1
+	default:
+		return 3
+	}
+}
+
+// ntfySeverityTag 与级别对应的 emoji 标签，ntfy 会显示在标题前
+func ntfySeverityTag(severity string) string {
+	switch severity {
+	case SeverityCritical:
+		return "rotating_light"
+	case SeverityHigh:
+		return "warning"
+	case SeverityLow, SeverityInfo:
+		return "information_source"
+	default:
+		return "bell"
+	}
+}
+
+// Send 以 JSON 方式发布到主题，标签包含级别 emoji、事件类型与配置的固定标签
+func (n *NtfyNotifier) Send(event LoginEvent) error {
+	severity := pushSeverity(event)
+	tags := append([]string{ntfySeverityTag(severity), event.displayType()}, n.cfg.Tags...)
+	payload := map[string]any{
+		"topic":    n.cfg.Topic,
+		"title":    robotTitle(event),
+		"message":  pushMessage(event),
+		"priority": ntfyPriority(severity),
+		"tags":     tags,
+	}
+
+	header := http.Header{}
+	switch {
+	case n.cfg.Token != "":
+		header.Set("Authorization", "Bearer "+n.cfg.Token)
+	case n.cfg.Username != "":
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(n.cfg.Username+":"+n.cfg.Password)))
+	}
+
+	if _, err := postJSON(n.httpClient, n.server, payload, header); err != nil {
+		return fmt.Errorf("发送 ntfy 消息失败: %w", err)
+	}
+	return nil
+}
+
+// Test 测试 ntfy 配置
+func (n *NtfyNotifier) Test() error {
+	if err := n.Send(channelTestEvent()); err != nil {
+		return fmt.Errorf("ntfy 测试失败: %w", err)
+	}
+	return nil
+}
+
+// GotifyNotifier 通过 Gotify 应用 token 推送
+type GotifyNotifier struct {
+	server     string
+	token      string
+	httpClient *http.Client
+}
+
+// NewGotifyNotifier 创建 Gotify 通知器
+func NewGotifyNotifier(cfg *GotifyConfig) *GotifyNotifier {
+	return &GotifyNotifier{
+		server:     strings.TrimRight(cfg.Server, "/"),
+		token:      cfg.Token,
+		httpClient: newChannelHTTPClient(),
+	}
+}
+
+// gotifyPriority Gotify 优先级 0-10，Android 客户端 1-3 仅显示图标，4-7 响铃振动，8 以上弹出
+func gotifyPriority(severity string) int {
+	switch severity {
+	case SeverityCritical:
+		return 10
+	case SeverityHigh:
+		return 8
+	case SeverityLow:
+		return 2
+	case SeverityInfo:
+		return 1
+	default:
+		return 5
+	}
+}
+
+// Send 发送到 /message，token 通过 X-Gotify-Key 传递
+func (g *GotifyNotifier) Send(event LoginEvent) error {
+	payload := map[string]any{
+		"title":    robotTitle(event),
+		"message":  pushMessage(event),
+		"priority": gotifyPriority(pushSeverity(event)),
+		"extras": map[string]any{
+			"client::display": map[string]string{"contentType": "text/plain"},
+		},
+	}
+	header := http.Header{}
+	header.Set("X-Gotify-Key", g.token)
+
+	if _, err := postJSON(g.httpClient, g.server+"/message", payload, header); err != nil {
+		return fmt.Errorf("发送 gotify 消息失败: %w", err)
+	}
+	return nil
+}
+
+// Test 测试 Gotify 配置
+func (g *GotifyNotifier) Test() error {
+	if err := g.Send(channelTestEvent()); err != nil {
+		return fmt.Errorf("gotify 测试失败: %w", err)
+	}
+	return nil
+}
+
+// PushoverNotifier 通过 Pushover 推送
+type PushoverNotifier struct {
+	endpoint   string
+	cfg        PushoverConfig
+	httpClient *http.Client
+}
+
+// NewPushoverNotifier 创建 Pushover 通知器
+func NewPushoverNotifier(cfg *PushoverConfig) *PushoverNotifier {
+	return &PushoverNotifier{endpoint: pushoverEndpoint, cfg: *cfg, httpClient: newChannelHTTPClient()}
+}
+
+// pushoverPriority Pushover 优先级 -2 到 2：-2 不提醒，-1 静默，1 绕过免打扰，2 为紧急级别，需确认前持续重发
+func pushoverPriority(severity string) int {
+	switch severity {
+	case SeverityCritical:
+		return 2
+	case SeverityHigh:
+		return 1
+	case SeverityLow:
+		return -1
+	case SeverityInfo:
+		return -2
+	default:
+		return 0
+	}
+}
+
+// Send 调用 messages.json，紧急级别附带重发间隔与持续时间
+func (p *PushoverNotifier) Send(event LoginEvent) error {
+	priority := pushoverPriority(pushSeverity(event))
+	payload := map[string]any{
+		"token":     p.cfg.AppToken,
+		"user":      p.cfg.UserKey,
+		"title":     robotTitle(event),
+		"message":   truncateRunes(pushMessage(event), 1024),
+		"priority":  priority,
+		"timestamp": event.Timestamp.Unix(),
+	}
+	if p.cfg.Device != "" {
+		payload["device"] = p.cfg.Device
+	}
+	if priority == 2 {
+		retry, expire := p.cfg.Retry, p.cfg.Expire
+		if retry == 0 {
+			retry = defaultPushoverRetry
+		}
+		if expire == 0 {
+			expire = defaultPushoverExpire
+		}
+		payload["retry"] = retry
+		payload["expire"] = expire
+	}
+
+	_, err := postJSON(p.httpClient, p.endpoint, payload, nil)
+	if err != nil {
+		var statusErr *httpStatusError
+		if errors.As(err, &statusErr) {
+			// 出错时在 errors 中给出原因，例如 user key 无效
+			var resp struct {
+				Errors []string `json:"errors"`
+			}
+			if json.Unmarshal([]byte(statusErr.Body), &resp) == nil && len(resp.Errors) > 0 {
+				return fmt.Errorf("pushover 返回错误（状态码 %d）: %s", statusErr.StatusCode, strings.Join(resp.Errors, "; "))
+			}
+		}
+		return fmt.Errorf("发送 pushover 消息失败: %w", err)
+	}
+	return nil
+}
+
+// Test 测试 Pushover 配置
+func (p *PushoverNotifier) Test() error {
+	if err := p.Send(channelTestEvent()); err != nil {
+		return fmt.Errorf("pushover 测试失败: %w", err)
+	}
+	return nil
+}
+
+// BarkNotifier 通过 Bark（iOS）推送
+type BarkNotifier struct {
+	server     string
+	cfg        BarkConfig
+	httpClient *http.Client
+}
+
+// NewBarkNotifier 创建 Bark 通知器
+func NewBarkNotifier(cfg *BarkConfig) *BarkNotifier {
+	server := strings.TrimRight(cfg.Server, "/")
+	if server == "" {
+		server = defaultBarkServer
+	}
+	return &BarkNotifier{server: server, cfg: *cfg, httpClient: newChannelHTTPClient()}
+}
+
+// barkLevel Bark 中断级别：critical 在静音与勿扰下仍会响铃，timeSensitive 可突破专注模式，passive 仅加入通知列表
+func barkLevel(severity string) string {
+	switch severity {
+	case SeverityCritical:
+		return "critical"
+	case SeverityHigh:
+		return "timeSensitive"
+	case SeverityLow, SeverityInfo:
+		return "passive"
+	default:
+		return "active"
+	}
+}
+
+// Send 调用 /push 接口
+func (b *BarkNotifier) Send(event LoginEvent) error {
+	level := barkLevel(pushSeverity(event))
+	group := b.cfg.Group
+	if group == "" {
+		group = "SSHield"
+	}
+	payload := map[string]any{
+		"device_key": b.cfg.DeviceKey,
+		"title":      robotTitle(event),
+		"body":       pushMessage(event),
+		"level":      level,
+		"group":      group,
+	}
+	if b.cfg.Sound != "" {
+		payload["sound"] = b.cfg.Sound
+	}
+	if level == "critical" {
+		payload["volume"] = 5
+	}
+
+	body, err := postJSON(b.httpClient, b.server+"/push", payload, nil)
+	if err != nil {
+		return fmt.Errorf("发送 bark 消息失败: %w", err)
+	}
+	var resp struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &resp) == nil && resp.Code != 0 && resp.Code != http.StatusOK {
+		return fmt.Errorf("bark 返回错误 %d: %s", resp.Code, resp.Message)
+	}
+	return nil
+}
+
+// Test 测试 Bark 配置
+func (b *BarkNotifier) Test() error {
+	if err := b.Send(channelTestEvent()); err != nil {
+		return fmt.Errorf("bark 测试失败: %w", err)
+	}
+	return nil
+}
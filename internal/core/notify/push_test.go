@@ -0,0 +1,141 @@
+package notify
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPushPriorityMapping(t *testing.T) {
+	cases := []struct {
+		severity string
+		ntfy     int
+		gotify   int
+		pushover int
+		bark     string
+	}{
+		{SeverityCritical, 5, 10, 2, "critical"},
+		{SeverityHigh, 4, 8, 1, "timeSensitive"},
+		{SeverityMedium, 3, 5, 0, "active"},
+		{SeverityLow, 2, 2, -1, "passive"},
+		{SeverityInfo, 1, 1, -2, "passive"},
+	}
+	for _, tc := range cases {
+		if ntfyPriority(tc.severity) != tc.ntfy || gotifyPriority(tc.severity) != tc.gotify ||
+			pushoverPriority(tc.severity) != tc.pushover || barkLevel(tc.severity) != tc.bark {
+			t.Fatalf("unexpected priority mapping for %s", tc.severity)
+		}
+	}
+}
+
+func TestNtfyNotifier(t *testing.T) {
+	server, reqs := newRecordingServer(t, respondWith(http.StatusOK, `{"id":"abc"}`))
+	event := testLoginEvent()
+	event.Severity = SeverityHigh
+	n := NewNtfyNotifier(&NtfyConfig{Server: server.URL + "/", Topic: "ssh", Token: "tk_abc", Tags: []string{"prod"}})
+	if err := n.Send(event); err != nil {
+		t.Fatal(err)
+	}
+	got := (*reqs)[0]
+
+	if got.header.Get("Authorization") != "Bearer tk_abc" {
+		t.Fatalf("unexpected auth header: %q", got.header.Get("Authorization"))
+	}
+	if got.body["topic"] != "ssh" || got.body["priority"] != float64(4) {
+		t.Fatalf("unexpected payload: %v", got.body)
+	}
+	tags := got.body["tags"].([]any)
+	if len(tags) != 3 || tags[0] != "warning" || tags[1] != EventLoginSuccess || tags[2] != "prod" {
+		t.Fatalf("unexpected tags: %v", tags)
+	}
+	if !strings.Contains(got.body["message"].(string), "用户: root") {
+		t.Fatalf("unexpected message: %v", got.body["message"])
+	}
+}
+
+func TestGotifyNotifier(t *testing.T) {
+	server, reqs := newRecordingServer(t, respondWith(http.StatusOK, `{"id":1}`))
+	event := testLoginEvent()
+	event.Severity = SeverityLow
+	if err := NewGotifyNotifier(&GotifyConfig{Server: server.URL, Token: "AbC"}).Send(event); err != nil {
+		t.Fatal(err)
+	}
+	got := (*reqs)[0]
+	if got.path != "/message" || got.header.Get("X-Gotify-Key") != "AbC" || got.body["priority"] != float64(2) {
+		t.Fatalf("unexpected request: %s %v %v", got.path, got.header, got.body)
+	}
+}
+
+func TestPushoverNotifierEmergency(t *testing.T) {
+	server, reqs := newRecordingServer(t, respondWith(http.StatusOK, `{"status":1,"request":"x"}`))
+	event := testLoginEvent()
+	event.Severity = SeverityCritical
+	n := NewPushoverNotifier(&PushoverConfig{UserKey: "u", AppToken: "a", Retry: 120})
+	n.endpoint = server.URL
+	if err := n.Send(event); err != nil {
+		t.Fatal(err)
+	}
+	got := (*reqs)[0]
+	if got.body["priority"] != float64(2) || got.body["retry"] != float64(120) || got.body["expire"] != float64(defaultPushoverExpire) {
+		t.Fatalf("unexpected emergency payload: %v", got.body)
+	}
+
+	event.Severity = SeverityMedium
+	if err := n.Send(event); err != nil {
+		t.Fatal(err)
+	}
+	got = (*reqs)[1]
+	if _, ok := got.body["retry"]; ok || got.body["priority"] != float64(0) {
+		t.Fatalf("retry should only be set for emergency priority: %v", got.body)
+	}
+}
+
+func TestPushoverNotifierError(t *testing.T) {
+	server, _ := newRecordingServer(t, respondWith(http.StatusBadRequest, `{"user":"invalid","errors":["user identifier is invalid"],"status":0}`))
+	n := NewPushoverNotifier(&PushoverConfig{UserKey: "u", AppToken: "a"})
+	n.endpoint = server.URL
+	if err := n.Send(testLoginEvent()); err == nil || !strings.Contains(err.Error(), "user identifier is invalid") {
+		t.Fatalf("expected pushover error, got %v", err)
+	}
+}
+
+func TestBarkNotifier(t *testing.T) {
+	server, reqs := newRecordingServer(t, respondWith(http.StatusOK, `{"code":200,"message":"success"}`))
+	event := testLoginEvent()
+	event.Severity = SeverityCritical
+	if err := NewBarkNotifier(&BarkConfig{Server: server.URL, DeviceKey: "dk"}).Send(event); err != nil {
+		t.Fatal(err)
+	}
+	got := (*reqs)[0]
+	if got.path != "/push" || got.body["device_key"] != "dk" || got.body["level"] != "critical" || got.body["group"] != "SSHield" {
+		t.Fatalf("unexpected request: %s %v", got.path, got.body)
+	}
+
+	server, _ = newRecordingServer(t, respondWith(http.StatusOK, `{"code":400,"message":"failed to get device token"}`))
+	if err := NewBarkNotifier(&BarkConfig{Server: server.URL, DeviceKey: "dk"}).Send(event); err == nil {
+		t.Fatal("expected bark error")
+	}
+}
+
+func TestValidatePushChannels(t *testing.T) {
+	for _, ch := range []*ChannelConfig{
+		{Type: "ntfy", Ntfy: &NtfyConfig{Topic: "ssh"}},
+		{Type: "gotify", Gotify: &GotifyConfig{Server: "https://gotify.example.com", Token: "t"}},
+		{Type: "pushover", Pushover: &PushoverConfig{UserKey: "u", AppToken: "a"}},
+		{Type: "bark", Bark: &BarkConfig{DeviceKey: "dk"}},
+	} {
+		if err := ValidateChannelConfig(ch); err != nil {
+			t.Fatalf("unexpected error for %s: %v", ch.Type, err)
+		}
+	}
+	for _, ch := range []*ChannelConfig{
+		{Type: "ntfy", Ntfy: &NtfyConfig{Topic: "ssh", Token: "t", Username: "u"}},
+		{Type: "gotify", Gotify: &GotifyConfig{Token: "t"}},
+		{Type: "pushover", Pushover: &PushoverConfig{UserKey: "u", AppToken: "a", Retry: 10}},
+		{Type: "bark"},
+	} {
+		if err := ValidateChannelConfig(ch); err == nil {
+			t.Fatalf("expected validation error for %s", ch.Type)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package notify
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 默认 /proc 路径，测试时可替换
+var procRoot = "/proc"
+
+// 匹配 Accepted publickey 日志中的公钥指纹
+var keyFingerprintRe = regexp.MustCompile(`SHA256:[A-Za-z0-9+/=]+`)
+
+// violation 返回登录事件违反处置策略的原因，未违反时返回空字符串
+func (c *ResponseConfig) violation(event *LoginEvent, countryCode string) string {
+	if countryCode != "" {
+		for _, denied := range c.DenyCountries {
+			if strings.EqualFold(strings.TrimSpace(denied), countryCode) {
+				return fmt.Sprintf("来源国家 %s 在禁止列表中", strings.ToUpper(countryCode))
+			}
+		}
+	}
+
+	if event.Method == "publickey" && len(c.AllowedKeys) > 0 {
+		fingerprint := keyFingerprintRe.FindString(event.Message)
+		if fingerprint == "" {
+			return "无法识别登录公钥指纹"
+		}
+		for _, allowed := range c.AllowedKeys {
+			if strings.TrimSpace(allowed) == fingerprint {
+				return ""
+			}
+		}
+		return fmt.Sprintf("公钥 %s 不在允许列表中", fingerprint)
+	}
+
+	return ""
+}
+
+// 日志时间戳可能只精确到秒或与本机时钟略有偏差，判断是否为启动后的登录时留出余量
+const responseClockSkew = time.Minute
+
+// responder 对违反策略的成功登录执行处置：终止 sshd 会话，可选锁定账户
+type responder struct {
+	dryRun      bool
+	since       time.Time // 早于该时间的登录不处置（如 watch 启动时从游标补读的历史日志），零值表示不限制
+	config      func() *ResponseConfig
+	country     func(ip string) string
+	findSession func(ip string, port int) ([]int, error)
+	kill        func(pid int) error
+	lock        func(user string) error
+}
+
+func newResponder(dryRun bool, since time.Time) *responder {
+	return &responder{
+		dryRun: dryRun,
+		since:  since,
+		config: func() *ResponseConfig {
+			cfg, err := loadConfig()
+			if err != nil || cfg == nil {
+				return nil
+			}
+			return cfg.Response
+		},
+		country: func(ip string) string {
+			return GetIPLookup().LookupResult(ip).CountryCode
+		},
+		findSession: findSSHDSession,
+		kill: func(pid int) error {
+			proc, err := os.FindProcess(pid)
+			if err != nil {
+				return err
+			}
+			return proc.Kill()
+		},
+		lock: lockAccount,
+	}
+}
+
+// respond 按配置检查事件，命中策略时执行处置并将结果写入 event.Action
+func (r *responder) respond(event *LoginEvent) {
+	if r == nil || event.Type != EventLoginSuccess {
+		return
+	}
+	if !r.since.IsZero() && event.Timestamp.Before(r.since.Add(-responseClockSkew)) {
+		debugf("notify: 跳过启动前的登录 %s@%s，不执行自动处置", event.User, event.IP)
+		return
+	}
+
+	cfg := r.config()
+	if cfg == nil || !cfg.Enabled {
+		return
+	}
+
+	countryCode := ""
+	if len(cfg.DenyCountries) > 0 && event.IP != "" {
+		countryCode = r.country(event.IP)
+	}
+	reason := cfg.violation(event, countryCode)
+	if reason == "" {
+		return
+	}
+	if !event.Verified {
+		// 日志文件与 SYSLOG_IDENTIFIER 可由任意本地用户写入（如 logger -t sshd），不能据此终止会话或锁定账户
+		fmt.Printf(">>> 跳过自动处置：%s@%s 的登录日志无法确认来自 sshd（仅处置 journald 中 _UID=0 的 sshd 进程日志）\n", event.User, event.IP)
+		return
+	}
+
+	event.Action = r.execute(cfg, event, reason)
+	fmt.Printf(">>> 自动处置：%s\n", event.Action)
+}
+
+func (r *responder) execute(cfg *ResponseConfig, event *LoginEvent, reason string) string {
+	prefix := ""
+	if r.dryRun {
+		prefix = "[dry-run] "
+	}
+	actions := []string{"原因：" + reason}
+
+	// 只有找到持有该来源 IP/端口连接的 sshd 进程，才能确认登录真实存在，否则不锁定账户
+	pids, err := r.findSession(event.IP, event.Port)
+	sessionFound := err == nil && len(pids) > 0
+	switch {
+	case err != nil:
+		actions = append(actions, fmt.Sprintf("查找会话失败：%v", err))
+	case len(pids) == 0:
+		actions = append(actions, "未找到对应的 sshd 会话")
+	case r.dryRun:
+		actions = append(actions, "将终止会话 PID "+joinPIDs(pids))
+	default:
+		var killed []int
+		for _, pid := range pids {
+			if err := r.kill(pid); err != nil {
+				log.Printf("终止进程 %d 失败: %v", pid, err)
+				continue
+			}
+			killed = append(killed, pid)
+		}
+		if len(killed) > 0 {
+			actions = append(actions, "已终止会话 PID "+joinPIDs(killed))
+		} else {
+			actions = append(actions, "终止会话失败")
+		}
+	}
+
+	if cfg.LockAccount && event.User != "" {
+		switch {
+		case event.User == "root":
+			// 锁定 root 可能导致服务器无法管理，仅终止会话
+			actions = append(actions, "root 账户不自动锁定")
+		case !sessionFound:
+			actions = append(actions, "未确认会话，不锁定账户 "+event.User)
+		case r.dryRun:
+			actions = append(actions, "将锁定账户 "+event.User)
+		default:
+			if err := r.lock(event.User); err != nil {
+				actions = append(actions, fmt.Sprintf("锁定账户 %s 失败：%v", event.User, err))
+			} else {
+				actions = append(actions, "已锁定账户 "+event.User)
+			}
+		}
+	}
+
+	return prefix + strings.Join(actions, "；")
+}
+
+// lockAccount 锁定密码并使账户过期，过期后公钥登录同样被拒绝
+func lockAccount(user string) error {
+	out, err := exec.Command("usermod", "-L", "-e", "1", user).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// findSSHDSession 通过 /proc/net/tcp{,6} 找到与来源 IP/端口对应的连接，
+// 再在 /proc/<pid>/fd 中查找持有该 socket 的 sshd 进程
+func findSSHDSession(ip string, port int) ([]int, error) {
+	remote := net.ParseIP(ip)
+	if remote == nil || port <= 0 {
+		return nil, fmt.Errorf("来源地址无效: %s:%d", ip, port)
+	}
+
+	inodes := make(map[string]struct{})
+	for _, name := range []string{"tcp", "tcp6"} {
+		if err := collectSocketInodes(filepath.Join(procRoot, "net", name), remote, port, inodes); err != nil {
+			return nil, err
+		}
+	}
+	if len(inodes) == 0 {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 失败: %w", procRoot, err)
+	}
+
+	var pids []int
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		comm, err := os.ReadFile(filepath.Join(procRoot, entry.Name(), "comm"))
+		if err != nil || !strings.HasPrefix(strings.TrimSpace(string(comm)), "sshd") {
+			continue
+		}
+		if processHoldsSocket(filepath.Join(procRoot, entry.Name(), "fd"), inodes) {
+			pids = append(pids, pid)
+		}
+	}
+	sort.Ints(pids)
+	return pids, nil
+}
+
+func collectSocketInodes(path string, remote net.IP, port int, inodes map[string]struct{}) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("读取 %s 失败: %w", path, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // 跳过表头
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+		if len(fields) < 10 || fields[3] != "01" { // 01 = ESTABLISHED
+			continue
+		}
+		addr, rport, ok := parseProcNetAddr(fields[2])
+		if !ok || rport != port || !addr.Equal(remote) {
+			continue
+		}
+		inodes[fields[9]] = struct{}{}
+	}
+	return scanner.Err()
+}
+
+// parseProcNetAddr 解析 /proc/net/tcp 中的 "0100007F:1F90" 格式地址
+// 地址按 32 位字以主机字节序（小端）存储
+func parseProcNetAddr(s string) (net.IP, int, bool) {
+	hostHex, portHex, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, 0, false
+	}
+	raw, err := hex.DecodeString(hostHex)
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return nil, 0, false
+	}
+	port, err := strconv.ParseUint(portHex, 16, 16)
+	if err != nil {
+		return nil, 0, false
+	}
+
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		binary.BigEndian.PutUint32(ip[i:], binary.LittleEndian.Uint32(raw[i:]))
+	}
+	return ip, int(port), true
+}
+
+func processHoldsSocket(fdDir string, inodes map[string]struct{}) bool {
+	fds, err := os.ReadDir(fdDir)
+	if err != nil {
+		return false
+	}
+	for _, fd := range fds {
+		target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+		if err != nil || !strings.HasPrefix(target, "socket:[") {
+			continue
+		}
+		inode := strings.TrimSuffix(strings.TrimPrefix(target, "socket:["), "]")
+		if _, ok := inodes[inode]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPIDs(pids []int) string {
+	var b bytes.Buffer
+	for i, pid := range pids {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.Itoa(pid))
+	}
+	return b.String()
+}
+
+// configureResponse 保存自动处置配置
+func configureResponse(respCfg ResponseConfig) error {
+	cm := NewConfigManager()
+	cfg, err := cm.LoadConfig()
+	if err != nil {
+		if err != ErrConfigNotFound {
+			return fmt.Errorf("加载配置失败: %w", err)
+		}
+		cfg = &Config{}
+	}
+
+	// 仅关闭时保留已有策略，便于再次启用
+	if !respCfg.Enabled && cfg.Response != nil && len(respCfg.DenyCountries) == 0 && len(respCfg.AllowedKeys) == 0 {
+		kept := *cfg.Response
+		kept.Enabled = false
+		respCfg = kept
+	}
+	if err := ValidateResponseConfig(&respCfg); err != nil {
+		return err
+	}
+
+	cfg.Response = &respCfg
+	if err := saveConfigWithBackup(cm, cfg); err != nil {
+		return err
+	}
+	printResponseSummary(&respCfg)
+	return nil
+}
@@ -0,0 +1,260 @@
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseProcNetAddr(t *testing.T) {
+	ip, port, ok := parseProcNetAddr("0A00A8C0:C350")
+	if !ok || ip.String() != "192.168.0.10" || port != 50000 {
+		t.Fatalf("ipv4 = %v:%d ok=%v", ip, port, ok)
+	}
+
+	ip, port, ok = parseProcNetAddr("0000000000000000FFFF00000A00A8C0:0016")
+	if !ok || ip.String() != "192.168.0.10" || port != 22 {
+		t.Fatalf("mapped ipv6 = %v:%d ok=%v", ip, port, ok)
+	}
+
+	if _, _, ok := parseProcNetAddr("zz:0016"); ok {
+		t.Fatalf("expected invalid address")
+	}
+}
+
+func TestFindSSHDSession(t *testing.T) {
+	root := t.TempDir()
+	old := procRoot
+	procRoot = root
+	defer func() { procRoot = old }()
+
+	mustWrite := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	header := "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"
+	mustWrite(filepath.Join(root, "net", "tcp"), header+
+		"   0: 0100000A:0016 0A00A8C0:C350 01 00000000:00000000 00:00000000 00000000     0        0 4242 1 0 20 4 30 10 -1\n"+
+		"   1: 0100000A:0016 0B00A8C0:C350 01 00000000:00000000 00:00000000 00000000     0        0 9999 1 0 20 4 30 10 -1\n")
+
+	procs := []struct {
+		pid, comm, inode string
+	}{
+		{"100", "sshd", "4242"},
+		{"101", "sshd-session", "4242"},
+		{"200", "bash", "4242"},
+		{"300", "sshd", "9999"},
+	}
+	for _, p := range procs {
+		mustWrite(filepath.Join(root, p.pid, "comm"), p.comm+"\n")
+		if err := os.MkdirAll(filepath.Join(root, p.pid, "fd"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink("socket:["+p.inode+"]", filepath.Join(root, p.pid, "fd", "3")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pids, err := findSSHDSession("192.168.0.10", 50000)
+	if err != nil {
+		t.Fatalf("findSSHDSession: %v", err)
+	}
+	if len(pids) != 2 || pids[0] != 100 || pids[1] != 101 {
+		t.Fatalf("pids = %v, want [100 101]", pids)
+	}
+
+	pids, err = findSSHDSession("192.168.0.10", 50001)
+	if err != nil || len(pids) != 0 {
+		t.Fatalf("expected no session, got %v err=%v", pids, err)
+	}
+}
+
+func TestResponseViolation(t *testing.T) {
+	cfg := &ResponseConfig{
+		Enabled:       true,
+		DenyCountries: []string{"ru"},
+		AllowedKeys:   []string{"SHA256:good"},
+	}
+
+	event := &LoginEvent{Type: EventLoginSuccess, Method: "password"}
+	if reason := cfg.violation(event, "RU"); !strings.Contains(reason, "RU") {
+		t.Fatalf("expected country violation, got %q", reason)
+	}
+	if reason := cfg.violation(event, "DE"); reason != "" {
+		t.Fatalf("unexpected violation %q", reason)
+	}
+
+	event = &LoginEvent{Type: EventLoginSuccess, Method: "publickey", Message: "Accepted publickey for alice from 203.0.113.1 port 5000 ssh2: ED25519 SHA256:good"}
+	if reason := cfg.violation(event, "DE"); reason != "" {
+		t.Fatalf("allowed key flagged: %q", reason)
+	}
+	event.Message = "Accepted publickey for alice from 203.0.113.1 port 5000 ssh2: ED25519 SHA256:evil"
+	if reason := cfg.violation(event, "DE"); !strings.Contains(reason, "SHA256:evil") {
+		t.Fatalf("expected key violation, got %q", reason)
+	}
+}
+
+func TestResponderDryRun(t *testing.T) {
+	var killed, locked bool
+	r := &responder{
+		dryRun:      true,
+		findSession: func(ip string, port int) ([]int, error) { return []int{10, 11}, nil },
+		kill:        func(pid int) error { killed = true; return nil },
+		lock:        func(user string) error { locked = true; return nil },
+	}
+
+	event := &LoginEvent{Type: EventLoginSuccess, User: "alice", IP: "203.0.113.1", Port: 5000}
+	action := r.execute(&ResponseConfig{LockAccount: true}, event, "test")
+	if killed || locked {
+		t.Fatalf("dry-run must not kill or lock")
+	}
+	for _, want := range []string{"[dry-run]", "将终止会话 PID 10,11", "将锁定账户 alice"} {
+		if !strings.Contains(action, want) {
+			t.Fatalf("action %q missing %q", action, want)
+		}
+	}
+
+	r.dryRun = false
+	event.User = "root"
+	action = r.execute(&ResponseConfig{LockAccount: true}, event, "test")
+	if !killed || locked {
+		t.Fatalf("expected kill without locking root, killed=%v locked=%v", killed, locked)
+	}
+	if !strings.Contains(action, "已终止会话 PID 10,11") || !strings.Contains(action, "root 账户不自动锁定") {
+		t.Fatalf("unexpected action %q", action)
+	}
+}
+
+// testPolicyResponder 只允许 SHA256:good 公钥登录，记录被终止的 PID
+func testPolicyResponder(killed *[]int, since time.Time) *responder {
+	return &responder{
+		since:       since,
+		config:      func() *ResponseConfig { return &ResponseConfig{Enabled: true, AllowedKeys: []string{"SHA256:good"}} },
+		findSession: func(ip string, port int) ([]int, error) { return []int{port}, nil },
+		kill:        func(pid int) error { *killed = append(*killed, pid); return nil },
+		lock:        func(user string) error { return nil },
+	}
+}
+
+func evilKeyLogin(port int, ts time.Time) *LoginEvent {
+	return &LoginEvent{
+		Type:      EventLoginSuccess,
+		User:      "alice",
+		IP:        "203.0.113.1",
+		Port:      port,
+		Method:    "publickey",
+		Timestamp: ts,
+		Message:   "Accepted publickey for alice from 203.0.113.1 port 5000 ssh2: ED25519 SHA256:evil",
+		Verified:  true,
+	}
+}
+
+func TestResponderIgnoresUnverifiedEvents(t *testing.T) {
+	var killed []int
+	var locked []string
+	r := testPolicyResponder(&killed, time.Time{})
+	r.config = func() *ResponseConfig {
+		return &ResponseConfig{Enabled: true, AllowedKeys: []string{"SHA256:good"}, LockAccount: true}
+	}
+	r.lock = func(user string) error { locked = append(locked, user); return nil }
+
+	// logger -t sshd 伪造的日志：SYSLOG_IDENTIFIER 匹配 sshd，但 _COMM/_UID 不是 sshd
+	forged := evilKeyLogin(5000, time.Now())
+	forged.Verified = false
+	r.respond(forged)
+	if len(killed) != 0 || len(locked) != 0 || forged.Action != "" {
+		t.Fatalf("forged login must not be handled, killed=%v locked=%v action=%q", killed, locked, forged.Action)
+	}
+
+	// 找不到持有该连接的 sshd 进程时不锁定账户
+	r.findSession = func(ip string, port int) ([]int, error) { return nil, nil }
+	unmatched := evilKeyLogin(5001, time.Now())
+	r.respond(unmatched)
+	if len(locked) != 0 {
+		t.Fatalf("account locked without a live session: %v", locked)
+	}
+	for _, want := range []string{"未找到对应的 sshd 会话", "未确认会话，不锁定账户 alice"} {
+		if !strings.Contains(unmatched.Action, want) {
+			t.Fatalf("action %q missing %q", unmatched.Action, want)
+		}
+	}
+
+	r.findSession = func(ip string, port int) ([]int, error) { return []int{port}, nil }
+	r.respond(evilKeyLogin(5002, time.Now()))
+	if len(killed) != 1 || len(locked) != 1 || locked[0] != "alice" {
+		t.Fatalf("expected verified login with live session to be handled, killed=%v locked=%v", killed, locked)
+	}
+}
+
+func TestResponderSkipsEventsBeforeStart(t *testing.T) {
+	var killed []int
+	start := time.Now()
+	r := testPolicyResponder(&killed, start)
+
+	old := evilKeyLogin(5000, start.Add(-time.Hour))
+	r.respond(old)
+	if len(killed) != 0 || old.Action != "" {
+		t.Fatalf("login before start must not be handled, killed=%v action=%q", killed, old.Action)
+	}
+
+	// 时间戳只精确到秒的日志略早于启动时间，仍视为启动后的登录
+	recent := evilKeyLogin(5001, start.Add(-time.Second))
+	r.respond(recent)
+	if len(killed) != 1 || recent.Action == "" {
+		t.Fatalf("expected recent login to be handled, killed=%v action=%q", killed, recent.Action)
+	}
+}
+
+func TestEventProcessorRespondsRegardlessOfFilterOutsideLock(t *testing.T) {
+	var killed []int
+	var sent []LoginEvent
+	proc := &eventProcessor{
+		notify:   true,
+		filter:   newNotifyFilter(NotifyOn("failed"), 0, 0),
+		respond:  testPolicyResponder(&killed, time.Time{}),
+		dispatch: func(event *LoginEvent) error { sent = append(sent, *event); return nil },
+	}
+
+	// 被通知过滤排除的违规登录不发送通知，但仍需终止会话
+	filtered := evilKeyLogin(5000, time.Now())
+	proc.handle(filtered)
+	if len(killed) != 1 || killed[0] != 5000 || len(sent) != 0 {
+		t.Fatalf("filtered login must still be handled without notification, killed=%v sent=%d", killed, len(sent))
+	}
+	if filtered.Location != "" {
+		t.Fatalf("filtered event must not look up the IP location, got %q", filtered.Location)
+	}
+	killed = nil
+
+	// 处置在锁外执行：处置过程中其他日志源仍可提交事件
+	proc.filter = newNotifyFilter(NotifyOnAll, 0, 0)
+	r := proc.respond
+	kill := r.kill
+	r.kill = func(pid int) error {
+		done := make(chan struct{})
+		go func() {
+			proc.process(&LoginEvent{Type: EventLoginFailed, IP: "198.51.100.7", Timestamp: time.Now()})
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Error("event processing blocked while responding")
+		}
+		return kill(pid)
+	}
+	proc.handle(evilKeyLogin(5001, time.Now()))
+	if len(killed) != 1 || killed[0] != 5001 {
+		t.Fatalf("expected session 5001 killed, got %v", killed)
+	}
+	if len(sent) != 1 || sent[0].Action == "" || sent[0].Severity != SeverityCritical {
+		t.Fatalf("notification must carry the action, got %+v", sent)
+	}
+}
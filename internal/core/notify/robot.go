@@ -0,0 +1,255 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 群机器人渠道类型
+const (
+	robotLark     = "lark"
+	robotDingTalk = "dingtalk"
+	robotWeCom    = "wecom"
+)
+
+// RobotNotifier 飞书、钉钉、企业微信群机器人通知器
+type RobotNotifier struct {
+	kind       string
+	webhook    string
+	secret     string
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewRobotNotifier 创建群机器人通知器，kind 为 lark/dingtalk/wecom
+func NewRobotNotifier(kind string, cfg *RobotConfig) *RobotNotifier {
+	return &RobotNotifier{
+		kind:       kind,
+		webhook:    cfg.Webhook,
+		secret:     cfg.Secret,
+		httpClient: newChannelHTTPClient(),
+		now:        time.Now,
+	}
+}
+
+// Send 按机器人类型生成消息并发送，配置了密钥时附带签名
+func (r *RobotNotifier) Send(event LoginEvent) error {
+	endpoint := r.webhook
+	var payload map[string]any
+	switch r.kind {
+	case robotLark:
+		payload = map[string]any{"msg_type": "interactive", "card": larkCard(event)}
+		if r.secret != "" {
+			ts := r.now().Unix()
+			payload["timestamp"] = strconv.FormatInt(ts, 10)
+			payload["sign"] = larkSign(r.secret, ts)
+		}
+	case robotDingTalk:
+		payload = map[string]any{
+			"msgtype":  "markdown",
+			"markdown": map[string]string{"title": robotTitle(event), "text": dingTalkMarkdown(event)},
+		}
+		if r.secret != "" {
+			var err error
+			endpoint, err = dingTalkSignedURL(r.webhook, r.secret, r.now().UnixMilli())
+			if err != nil {
+				return err
+			}
+		}
+	case robotWeCom:
+		payload = map[string]any{
+			"msgtype":  "markdown",
+			"markdown": map[string]string{"content": weComMarkdown(event)},
+		}
+	default:
+		return fmt.Errorf("未知机器人类型: %s", r.kind)
+	}
+
+	body, err := postJSON(r.httpClient, endpoint, payload, nil)
+	if err != nil {
+		return fmt.Errorf("发送 %s 消息失败: %w", r.kind, err)
+	}
+	return checkRobotResponse(r.kind, body)
+}
+
+// Test 测试机器人配置
+func (r *RobotNotifier) Test() error {
+	if err := r.Send(channelTestEvent()); err != nil {
+		return fmt.Errorf("%s 测试失败: %w", r.kind, err)
+	}
+	return nil
+}
+
+// checkRobotResponse 机器人接口出错时 HTTP 状态仍为 200，需要检查返回的错误码
+// 飞书返回 code/msg（旧版为 StatusCode/StatusMessage），钉钉与企业微信返回 errcode/errmsg
+func checkRobotResponse(kind string, body []byte) error {
+	var resp struct {
+		Code          *int   `json:"code"`
+		Msg           string `json:"msg"`
+		StatusCode    *int   `json:"StatusCode"`
+		StatusMessage string `json:"StatusMessage"`
+		ErrCode       *int   `json:"errcode"`
+		ErrMsg        string `json:"errmsg"`
+	}
+	if len(body) == 0 || json.Unmarshal(body, &resp) != nil {
+		return nil
+	}
+	switch {
+	case resp.Code != nil && *resp.Code != 0:
+		return fmt.Errorf("%s 返回错误 %d: %s", kind, *resp.Code, resp.Msg)
+	case resp.StatusCode != nil && *resp.StatusCode != 0:
+		return fmt.Errorf("%s 返回错误 %d: %s", kind, *resp.StatusCode, resp.StatusMessage)
+	case resp.ErrCode != nil && *resp.ErrCode != 0:
+		return fmt.Errorf("%s 返回错误 %d: %s", kind, *resp.ErrCode, resp.ErrMsg)
+	}
+	return nil
+}
+
+// larkSign 飞书签名：以 "timestamp\nsecret" 为密钥对空串做 HMAC-SHA256，再 base64 编码
+func larkSign(secret string, ts int64) string {
+	mac := hmac.New(sha256.New, []byte(strconv.FormatInt(ts, 10)+"\n"+secret))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// dingTalkSignedURL 钉钉签名：以 secret 为密钥对 "毫秒时间戳\nsecret" 做 HMAC-SHA256，
+// base64 后与时间戳一起作为 URL 参数
+func dingTalkSignedURL(webhook, secret string, tsMillis int64) (string, error) {
+	u, err := url.Parse(webhook)
+	if err != nil {
+		return "", fmt.Errorf("解析 webhook 地址失败: %w", err)
+	}
+	ts := strconv.FormatInt(tsMillis, 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "\n" + secret))
+
+	q := u.Query()
+	q.Set("timestamp", ts)
+	q.Set("sign", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+func robotTitle(event LoginEvent) string {
+	host := event.HostIP
+	if host == "" {
+		host = event.Hostname
+	}
+	return fmt.Sprintf("🔐 SSH %s Alert @%s", event.displayType(), host)
+}
+
+// larkCard 飞书消息卡片，布局与 README 中的 curl 示例一致
+func larkCard(event LoginEvent) map[string]any {
+	template := "red"
+	switch {
+	case event.Type == EventLoginSuccess:
+		template = "yellow"
+	case event.Severity == SeverityInfo || event.Severity == SeverityLow:
+		template = "grey"
+	}
+
+	f := robotFields(event)
+	main := fmt.Sprintf("**👤 用户:** %s@%s\n**🔑 方式:** %s\n**🌐 IP:** %s\n**📍 位置:** %s\n**⏰ 时间:** %s",
+		f.user, f.host, f.method, f.ip, f.location, f.time)
+	for _, extra := range extraEventFields(event) {
+		main += fmt.Sprintf("\n**%s:** %s", extra.Label, extra.Value)
+	}
+
+	return map[string]any{
+		"header": map[string]any{
+			"template": template,
+			"title":    map[string]string{"content": robotTitle(event), "tag": "plain_text"},
+		},
+		"config": map[string]any{"wide_screen_mode": true},
+		"elements": []any{
+			map[string]any{"tag": "div", "text": map[string]string{"content": main, "tag": "lark_md"}},
+			map[string]any{"tag": "hr"},
+			map[string]any{"tag": "div", "text": map[string]string{
+				"content": fmt.Sprintf("**📝 消息:** %s\n**📂 日志:** %s", f.message, f.logPath),
+				"tag":     "lark_md",
+			}},
+			map[string]any{"tag": "hr"},
+			map[string]any{"tag": "note", "elements": []any{
+				map[string]string{"tag": "plain_text", "content": "Powered by SSHield"},
+			}},
+		},
+	}
+}
+
+// dingTalkMarkdown 钉钉 markdown 消息，换行需要以两个空格结尾
+func dingTalkMarkdown(event LoginEvent) string {
+	f := robotFields(event)
+	lines := []string{
+		"### " + robotTitle(event),
+		"**👤 用户:** " + f.user + "@" + f.host,
+		"**🔑 方式:** " + f.method,
+		"**🌐 IP:** " + f.ip,
+		"**📍 位置:** " + f.location,
+		"**⏰ 时间:** " + f.time,
+	}
+	for _, extra := range extraEventFields(event) {
+		lines = append(lines, "**"+extra.Label+":** "+extra.Value)
+	}
+	lines = append(lines, "**📂 日志:** "+f.logPath, "> "+f.message)
+	return strings.Join(lines, "  \n")
+}
+
+// weComMarkdown 企业微信 markdown 消息，标题按事件级别着色
+func weComMarkdown(event LoginEvent) string {
+	color := "warning"
+	if event.Severity == SeverityInfo || event.Severity == SeverityLow {
+		color = "comment"
+	}
+	f := robotFields(event)
+	lines := []string{
+		fmt.Sprintf(`<font color="%s">%s</font>`, color, robotTitle(event)),
+		"> 用户: " + f.user + "@" + f.host,
+		"> 方式: " + f.method,
+		"> IP: " + f.ip,
+		"> 位置: " + f.location,
+		"> 时间: " + f.time,
+	}
+	for _, extra := range extraEventFields(event) {
+		lines = append(lines, "> "+extra.Label+": "+extra.Value)
+	}
+	lines = append(lines, "> 日志: "+f.logPath, "> 消息: "+f.message)
+	return strings.Join(lines, "\n")
+}
+
+type robotEventFields struct {
+	user, host, method, ip, location, time, message, logPath string
+}
+
+func robotFields(event LoginEvent) robotEventFields {
+	return robotEventFields{
+		user:     orDash(event.User),
+		host:     orDash(event.Hostname),
+		method:   orDash(event.Method),
+		ip:       orDash(event.IP),
+		location: orDash(event.Location),
+		time:     formatShanghaiRFC3339(event.Timestamp),
+		message:  orDash(event.Message),
+		logPath:  orDash(event.LogPath),
+	}
+}
+
+// RobotInput 配置群机器人渠道的参数
+type RobotInput struct {
+	Name    string
+	Webhook string
+	Secret  string
+}
+
+// configureRobot 测试并保存群机器人渠道
+func configureRobot(kind string, input RobotInput) error {
+	channel := ChannelConfig{Name: input.Name, Enabled: true, Type: kind}
+	channel.setRobot(kind, &RobotConfig{Webhook: input.Webhook, Secret: input.Secret})
+	return configureChannel(channel)
+}
@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRobotNotifierLark(t *testing.T) {
+	server, reqs := newRecordingServer(t, respondWith(http.StatusOK, `{"code":0,"msg":"success"}`))
+	n := NewRobotNotifier(robotLark, &RobotConfig{Webhook: server.URL, Secret: "s3cret"})
+	n.now = func() time.Time { return time.Unix(1700000000, 0) }
+	if err := n.Send(testLoginEvent()); err != nil {
+		t.Fatal(err)
+	}
+	got := (*reqs)[0]
+
+	mac := hmac.New(sha256.New, []byte("1700000000\ns3cret"))
+	if got.body["timestamp"] != "1700000000" || got.body["sign"] != base64.StdEncoding.EncodeToString(mac.Sum(nil)) {
+		t.Fatalf("unexpected signature fields: %v %v", got.body["timestamp"], got.body["sign"])
+	}
+	card := got.body["card"].(map[string]any)
+	header := card["header"].(map[string]any)
+	if header["template"] != "yellow" || !strings.Contains(header["title"].(map[string]any)["content"].(string), "login_success Alert @10.0.0.1") {
+		t.Fatalf("unexpected card header: %v", header)
+	}
+	content := card["elements"].([]any)[0].(map[string]any)["text"].(map[string]any)["content"].(string)
+	if !strings.Contains(content, "**👤 用户:** root@web-1") || !strings.Contains(content, "**级别:** medium") {
+		t.Fatalf("unexpected card content: %s", content)
+	}
+}
+
+func TestRobotNotifierDingTalk(t *testing.T) {
+	server, reqs := newRecordingServer(t, respondWith(http.StatusOK, `{"errcode":0,"errmsg":"ok"}`))
+	n := NewRobotNotifier(robotDingTalk, &RobotConfig{Webhook: server.URL + "/robot/send?access_token=abc", Secret: "SECxyz"})
+	n.now = func() time.Time { return time.UnixMilli(1700000000123) }
+	if err := n.Send(testLoginEvent()); err != nil {
+		t.Fatal(err)
+	}
+	got := (*reqs)[0]
+
+	mac := hmac.New(sha256.New, []byte("SECxyz"))
+	mac.Write([]byte("1700000000123\nSECxyz"))
+	if got.query.Get("access_token") != "abc" || got.query.Get("timestamp") != "1700000000123" ||
+		got.query.Get("sign") != base64.StdEncoding.EncodeToString(mac.Sum(nil)) {
+		t.Fatalf("unexpected query: %v", got.query)
+	}
+	markdown := got.body["markdown"].(map[string]any)
+	if !strings.HasPrefix(markdown["text"].(string), "### 🔐 SSH login_success") || got.body["msgtype"] != "markdown" {
+		t.Fatalf("unexpected payload: %v", got.body)
+	}
+}
+
+func TestRobotNotifierWeComError(t *testing.T) {
+	server, reqs := newRecordingServer(t, respondWith(http.StatusOK, `{"errcode":93000,"errmsg":"invalid webhook url"}`))
+	err := NewRobotNotifier(robotWeCom, &RobotConfig{Webhook: server.URL}).Send(testLoginEvent())
+	if err == nil || !strings.Contains(err.Error(), "93000") {
+		t.Fatalf("expected errcode in error, got %v", err)
+	}
+	content := (*reqs)[0].body["markdown"].(map[string]any)["content"].(string)
+	if !strings.Contains(content, "> IP: 203.0.113.9") {
+		t.Fatalf("unexpected content: %s", content)
+	}
+}
+
+func TestValidateRobotChannel(t *testing.T) {
+	ok := &ChannelConfig{Type: "dingtalk", DingTalk: &RobotConfig{Webhook: "https://oapi.dingtalk.com/robot/send?access_token=x", Secret: "SEC"}}
+	if err := ValidateChannelConfig(ok); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, ch := range []*ChannelConfig{
+		{Type: "lark"},
+		{Type: "lark", Lark: &RobotConfig{Webhook: "open.feishu.cn/hook"}},
+		{Type: "wecom", WeCom: &RobotConfig{Webhook: "https://qyapi.weixin.qq.com/x", Secret: "s"}},
+	} {
+		if err := ValidateChannelConfig(ch); err == nil {
+			t.Fatalf("expected validation error for %+v", ch)
+		}
+	}
+}
+
+func TestMaskWebhook(t *testing.T) {
+	if got := maskWebhook("https://open.feishu.cn/open-apis/bot/v2/hook/abcdef"); got != "https://open.feishu.cn/open-apis/bot/v2/hook/***" {
+		t.Fatalf("unexpected mask: %s", got)
+	}
+	if got := maskWebhook("https://oapi.dingtalk.com/robot/send?access_token=abc"); got != "https://oapi.dingtalk.com/robot/***?***" {
+		t.Fatalf("unexpected mask: %s", got)
+	}
+}
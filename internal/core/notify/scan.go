@@ -0,0 +1,133 @@
+package notify
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultScanLimit = 5
+	// scan_detected 事件中列出的用户名数量上限
+	scanSampleUsers = 5
+)
+
+// scanDetector 按来源 IP 聚合扫描行为：未认证断开、协议异常、大量不同用户名
+// 同一来源在一个时间窗口内只产生一条 scan_detected，之后该来源的失败事件不再单独通知
+type scanDetector struct {
+	window  time.Duration
+	limit   int
+	sources map[string]*scanSource
+}
+
+type scanSource struct {
+	first    time.Time
+	last     time.Time
+	noAuth   int
+	protocol int
+	users    map[string]struct{}
+	reported bool
+}
+
+func newScanDetector(window time.Duration, limit int) *scanDetector {
+	if limit <= 0 {
+		limit = defaultScanLimit
+	}
+	return &scanDetector{
+		window:  window,
+		limit:   limit,
+		sources: make(map[string]*scanSource),
+	}
+}
+
+// observe 记录事件，返回新产生的聚合事件以及该事件是否应被合并（不再单独通知）
+func (d *scanDetector) observe(event *LoginEvent) (*LoginEvent, bool) {
+	if d == nil || d.window <= 0 || event.IP == "" {
+		return nil, false
+	}
+	if event.Type != EventLoginFailed && event.Type != EventScanProbe {
+		return nil, false
+	}
+
+	now := event.Timestamp
+	d.expire(now)
+
+	src, ok := d.sources[event.IP]
+	if !ok || now.Sub(src.first) > d.window {
+		src = &scanSource{first: now, users: make(map[string]struct{})}
+		d.sources[event.IP] = src
+	}
+	src.last = now
+
+	switch {
+	case event.Type == EventScanProbe && event.Method == probeNoIdent:
+		src.noAuth++
+	case event.Type == EventScanProbe && event.Method == probeProtocol, event.Subtype == SubtypeKexFailed:
+		src.protocol++
+	case event.Type == EventLoginFailed && (event.User == "" || event.User == "unknown"):
+		// 未提供用户名即断开，典型的端口探测
+		src.noAuth++
+	}
+	if event.User != "" && event.User != "unknown" {
+		src.users[event.User] = struct{}{}
+	}
+
+	if src.reported {
+		return nil, true
+	}
+	if src.noAuth < d.limit && src.protocol < d.limit && len(src.users) < d.limit {
+		return nil, false
+	}
+
+	src.reported = true
+	return d.aggregate(event, src), true
+}
+
+func (d *scanDetector) aggregate(trigger *LoginEvent, src *scanSource) *LoginEvent {
+	users := make([]string, 0, len(src.users))
+	for u := range src.users {
+		users = append(users, u)
+	}
+	sort.Strings(users)
+	sample := users
+	if len(sample) > scanSampleUsers {
+		sample = sample[:scanSampleUsers]
+	}
+
+	userList := strings.Join(sample, ",")
+	if len(users) > len(sample) {
+		userList += ",..."
+	}
+	if userList == "" {
+		userList = "-"
+	}
+
+	hostIP := trigger.HostIP
+	if hostIP == "" {
+		hostIP = getHostIP()
+	}
+
+	return &LoginEvent{
+		Type:      EventScanDetected,
+		User:      userList,
+		IP:        trigger.IP,
+		Method:    "scan",
+		Timestamp: trigger.Timestamp,
+		Hostname:  trigger.Hostname,
+		Location:  trigger.Location,
+		LogPath:   trigger.LogPath,
+		HostIP:    hostIP,
+		Message: fmt.Sprintf("%v 内检测到扫描：未认证断开 %d 次，协议异常 %d 次，尝试用户名 %d 个；窗口内后续失败事件不再单独通知",
+			d.window, src.noAuth, src.protocol, len(users)),
+	}
+}
+
+// expire 清理过期来源，避免长期运行时内存增长
+func (d *scanDetector) expire(now time.Time) {
+	for ip, src := range d.sources {
+		if now.Sub(src.last) > 2*d.window {
+			delete(d.sources, ip)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func scanEvent(eventType, user, method string, at time.Time) *LoginEvent {
+	return &LoginEvent{
+		Type:      eventType,
+		User:      user,
+		IP:        "198.51.100.7",
+		Method:    method,
+		Timestamp: at,
+		Location:  "test",
+		HostIP:    "192.0.2.1",
+	}
+}
+
+func TestScanDetectorAggregatesUsernames(t *testing.T) {
+	d := newScanDetector(10*time.Minute, 3)
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var aggregates []*LoginEvent
+	var suppressed int
+	for i, user := range []string{"admin", "oracle", "test", "ubuntu", "admin"} {
+		agg, merged := d.observe(scanEvent(EventLoginFailed, user, "password", base.Add(time.Duration(i)*time.Second)))
+		if agg != nil {
+			aggregates = append(aggregates, agg)
+		}
+		if merged {
+			suppressed++
+		}
+	}
+
+	if len(aggregates) != 1 {
+		t.Fatalf("expected 1 scan_detected, got %d", len(aggregates))
+	}
+	agg := aggregates[0]
+	if agg.Type != EventScanDetected || agg.User != "admin,oracle,test" {
+		t.Fatalf("unexpected aggregate: %+v", agg)
+	}
+	// 第三个用户名触发聚合，其后的两条均被合并
+	if suppressed != 3 {
+		t.Fatalf("expected 3 merged events, got %d", suppressed)
+	}
+	if !isFailureEvent(agg.Type) {
+		t.Fatalf("scan_detected should be treated as failure")
+	}
+}
+
+func TestScanDetectorProbesAndWindow(t *testing.T) {
+	d := newScanDetector(time.Minute, 2)
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if agg, _ := d.observe(scanEvent(EventScanProbe, "", probeNoIdent, base)); agg != nil {
+		t.Fatalf("single probe should not trigger")
+	}
+	agg, _ := d.observe(scanEvent(EventLoginFailed, "unknown", "unknown[preauth]", base.Add(time.Second)))
+	if agg == nil || !strings.Contains(agg.Message, "未认证断开 2 次") {
+		t.Fatalf("expected no-auth aggregate, got %+v", agg)
+	}
+
+	// 成功登录从不合并
+	if agg, merged := d.observe(scanEvent(EventLoginSuccess, "root", "publickey", base.Add(2*time.Second))); agg != nil || merged {
+		t.Fatalf("success must pass through")
+	}
+
+	// 窗口过后重新计数
+	later := base.Add(2 * time.Minute)
+	if agg, merged := d.observe(scanEvent(EventScanProbe, "", probeProtocol, later)); agg != nil || merged {
+		t.Fatalf("new window should start fresh")
+	}
+	if agg, _ := d.observe(scanEvent(EventScanProbe, "", probeProtocol, later.Add(time.Second))); agg == nil {
+		t.Fatalf("expected protocol aggregate in new window")
+	}
+}
+
+func TestScanDetectorDisabled(t *testing.T) {
+	var d *scanDetector
+	if agg, merged := d.observe(scanEvent(EventLoginFailed, "root", "password", time.Now())); agg != nil || merged {
+		t.Fatalf("nil detector should be a no-op")
+	}
+	d = newScanDetector(0, 1)
+	if agg, merged := d.observe(scanEvent(EventLoginFailed, "root", "password", time.Now())); agg != nil || merged {
+		t.Fatalf("zero window should disable detection")
+	}
+}
+
+func TestParseScanProbe(t *testing.T) {
+	ts := time.Now()
+	cases := []struct {
+		message string
+		kind    string
+		user    string
+	}{
+		{"Did not receive identification string from 203.0.113.9 port 40022", probeNoIdent, ""},
+		{"banner exchange: Connection from 203.0.113.9 port 40022: invalid format", probeProtocol, ""},
+		{"Bad protocol version identification 'GET / HTTP/1.1' from 203.0.113.9 port 40022", probeProtocol, ""},
+		{"Invalid user oracle from 203.0.113.9 port 40022", probeInvalidUser, "oracle"},
+	}
+	for _, tc := range cases {
+		event, ok := parseScanProbe(tc.message, "host", ts)
+		if !ok {
+			t.Fatalf("expected probe for %q", tc.message)
+		}
+		if event.Type != EventScanProbe || event.Method != tc.kind || event.User != tc.user || event.IP != "203.0.113.9" || event.Port != 40022 {
+			t.Fatalf("unexpected probe for %q: %+v", tc.message, event)
+		}
+	}
+
+	if _, ok := parseScanProbe("Server listening on 0.0.0.0 port 22.", "host", ts); ok {
+		t.Fatalf("unexpected probe for unrelated message")
+	}
+}
@@ -0,0 +1,359 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// 保存当前会话表的文件
+const sessionsFileName = "sessions.json"
+
+// sessionRecord 当前在线会话
+type sessionRecord struct {
+	User     string    `json:"user"`
+	IP       string    `json:"ip"`
+	Port     int       `json:"port"`
+	PID      int       `json:"pid,omitempty"`
+	Method   string    `json:"method,omitempty"`
+	Start    time.Time `json:"start"`
+	Location string    `json:"location,omitempty"`
+	HostIP   string    `json:"host_ip,omitempty"`
+	LogPath  string    `json:"log_path,omitempty"`
+}
+
+// sessionTracker 根据 Accepted 与会话关闭日志维护在线会话表，并为 session_closed 补全来源与时长
+type sessionTracker struct {
+	path     string // 持久化路径，为空时仅保存在内存
+	sessions []*sessionRecord
+}
+
+// loadSessionTracker 读取会话表，文件不存在时返回空表
+func loadSessionTracker(path string) (*sessionTracker, error) {
+	t := &sessionTracker{path: path}
+	if path == "" {
+		return t, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return t, nil
+		}
+		return t, fmt.Errorf("failed to read sessions: %w", err)
+	}
+	if len(data) == 0 {
+		return t, nil
+	}
+	if err := json.Unmarshal(data, &t.sessions); err != nil {
+		return t, fmt.Errorf("failed to unmarshal sessions: %w", err)
+	}
+	return t, nil
+}
+
+// observe 更新会话表，返回事件是否需要继续处理
+// session_opened 只用于跟踪；无法关联的 session_closed（如重复的断开日志）直接丢弃
+func (t *sessionTracker) observe(event *LoginEvent) bool {
+	switch event.Type {
+	case EventLoginSuccess:
+		if t == nil {
+			return true
+		}
+		if idx := t.match(event); idx >= 0 {
+			t.remove(idx)
+		}
+		t.sessions = append(t.sessions, &sessionRecord{
+			User:     event.User,
+			IP:       event.IP,
+			Port:     event.Port,
+			PID:      event.PID,
+			Method:   event.Method,
+			Start:    event.Timestamp,
+			Location: event.Location,
+			HostIP:   event.HostIP,
+			LogPath:  event.LogPath,
+		})
+		t.save()
+		return true
+	case EventSessionOpened:
+		return false
+	case EventSessionClosed:
+		if t == nil {
+			return false
+		}
+		idx := t.match(event)
+		if idx < 0 {
+			return false
+		}
+		s := t.sessions[idx]
+		t.remove(idx)
+		t.save()
+
+		event.User = s.User
+		event.IP = s.IP
+		event.Port = s.Port
+		event.PID = s.PID
+		event.Method = s.Method
+		event.Location = s.Location
+		event.HostIP = s.HostIP
+		if event.Timestamp.After(s.Start) {
+			event.Duration = event.Timestamp.Sub(s.Start)
+		}
+		return true
+	default:
+		return true
+	}
+}
+
+// locate 为会话补全位置：位置在事件处理锁外查询，晚于会话记录
+func (t *sessionTracker) locate(event *LoginEvent) {
+	if t == nil || event.Type != EventLoginSuccess || event.Location == "" {
+		return
+	}
+	if idx := t.match(event); idx >= 0 && t.sessions[idx].Location == "" {
+		t.sessions[idx].Location = event.Location
+		t.save()
+	}
+}
+
+// fillLocations 为缺少位置的会话补查归属地（watch 只为需要通知或输出的登录查询位置）
+func (t *sessionTracker) fillLocations() bool {
+	changed := false
+	for _, s := range t.sessions {
+		if s.Location != "" || s.IP == "" {
+			continue
+		}
+		if location := LookupIPLocation(s.IP); location != "" {
+			s.Location = location
+			changed = true
+		}
+	}
+	return changed
+}
+
+// attach 将提权事件关联到发起它的 SSH 会话，补全来源信息。
+// 只沿进程树向上查找会话的 sshd 进程；找不到时（控制台、cron、进程已退出）不补全，
+// 避免把本地提权误报为来自某个远程 IP
+func (t *sessionTracker) attach(event *LoginEvent) {
+	if t == nil || len(t.sessions) == 0 {
+		return
+	}
+
+	var found *sessionRecord
+	for _, pid := range processAncestors(event.PID) {
+		for _, s := range t.sessions {
+			if s.PID > 0 && s.PID == pid {
+				found = s
+				break
+			}
+		}
+		if found != nil {
+			break
+		}
+	}
+	if found == nil {
+		return
+	}
+
+	event.IP = found.IP
+	event.Port = found.Port
+	event.Location = found.Location
+	event.HostIP = found.HostIP
+}
+
+// processAncestors 返回进程的祖先 PID 列表（由近及远）
+func processAncestors(pid int) []int {
+	var ancestors []int
+	for i := 0; i < 32 && pid > 1; i++ {
+		data, err := os.ReadFile(filepath.Join(procRoot, strconv.Itoa(pid), "stat"))
+		if err != nil {
+			break
+		}
+		// 格式: pid (comm) state ppid ...，comm 可能包含空格，从最后一个 ')' 之后解析
+		stat := string(data)
+		idx := strings.LastIndexByte(stat, ')')
+		if idx < 0 {
+			break
+		}
+		fields := strings.Fields(stat[idx+1:])
+		if len(fields) < 2 {
+			break
+		}
+		ppid, err := strconv.Atoi(fields[1])
+		if err != nil {
+			break
+		}
+		ancestors = append(ancestors, ppid)
+		pid = ppid
+	}
+	return ancestors
+}
+
+// match 按 PID 优先、IP/端口其次查找会话
+func (t *sessionTracker) match(event *LoginEvent) int {
+	if event.PID > 0 {
+		for i, s := range t.sessions {
+			if s.PID == event.PID {
+				return i
+			}
+		}
+	}
+	if event.IP != "" && event.Port > 0 {
+		for i, s := range t.sessions {
+			if s.IP == event.IP && s.Port == event.Port {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func (t *sessionTracker) remove(idx int) {
+	t.sessions = append(t.sessions[:idx], t.sessions[idx+1:]...)
+}
+
+// prune 移除 sshd 进程已不存在的会话（错过关闭日志或重启后残留）
+func (t *sessionTracker) prune() bool {
+	if _, err := os.Stat(procRoot); err != nil {
+		return false
+	}
+	kept := t.sessions[:0]
+	changed := false
+	for _, s := range t.sessions {
+		if s.PID > 0 {
+			if _, err := os.Stat(filepath.Join(procRoot, strconv.Itoa(s.PID))); os.IsNotExist(err) {
+				changed = true
+				continue
+			}
+		}
+		kept = append(kept, s)
+	}
+	t.sessions = kept
+	return changed
+}
+
+func (t *sessionTracker) save() {
+	if t.path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(t.sessions, "", "  ")
+	if err != nil {
+		log.Printf("写入会话表失败: %v", err)
+		return
+	}
+	if err := os.WriteFile(t.path, data, 0600); err != nil {
+		log.Printf("写入会话表失败: %v", err)
+	}
+}
+
+// newDefaultSessionTracker 打开默认位置的会话表，失败时退化为内存会话表
+func newDefaultSessionTracker() *sessionTracker {
+	path, err := DefaultStatePath(sessionsFileName)
+	if err != nil {
+		log.Printf("会话表不可用，仅在内存中跟踪: %v", err)
+		return &sessionTracker{}
+	}
+	t, err := loadSessionTracker(path)
+	if err != nil {
+		log.Printf("读取会话表失败，重新开始跟踪: %v", err)
+		t.sessions = nil
+	}
+	return t
+}
+
+// NewSessionsCommand 返回 sessions 命令，显示当前在线的 SSH 会话
+func NewSessionsCommand() *cobra.Command {
+	var timezone string
+
+	cmd := &cobra.Command{
+		Use:   "sessions",
+		Short: "显示当前在线的 SSH 会话",
+		Long: `显示当前在线的 SSH 会话：用户、来源、登录时间与已登录时长。
+
+会话表由 sshield ssh watch（或 sweep）根据 sshd 日志维护，需要先运行 watch 服务。`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			loc, err := resolveLocation(timezone)
+			if err != nil {
+				return err
+			}
+
+			path, err := DefaultStatePath(sessionsFileName)
+			if err != nil {
+				return err
+			}
+			t, err := loadSessionTracker(path)
+			if err != nil {
+				return err
+			}
+			pruned := t.prune()
+			if located := t.fillLocations(); pruned || located {
+				t.save()
+			}
+
+			printSessions(t.sessions, time.Now(), normalizeLocation(loc))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&timezone, "timezone", "Asia/Shanghai", "显示使用的时区（示例：'Asia/Shanghai'｜'Local'，默认 Asia/Shanghai）")
+
+	return cmd
+}
+
+func printSessions(sessions []*sessionRecord, now time.Time, loc *time.Location) {
+	if len(sessions) == 0 {
+		fmt.Println("当前没有在线会话。")
+		return
+	}
+
+	sorted := append([]*sessionRecord(nil), sessions...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	fmt.Printf("当前在线会话（共 %d 个）：\n", len(sorted))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "用户\t来源\t位置\t方式\tPID\t登录时间\t时长")
+	for _, s := range sorted {
+		source := s.IP
+		if s.Port > 0 {
+			source = fmt.Sprintf("%s:%d", s.IP, s.Port)
+		}
+		pid := "-"
+		if s.PID > 0 {
+			pid = strconv.Itoa(s.PID)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			s.User,
+			source,
+			orDash(s.Location),
+			orDash(s.Method),
+			pid,
+			s.Start.In(loc).Format("2006-01-02 15:04:05"),
+			formatSessionDuration(now.Sub(s.Start)),
+		)
+	}
+	_ = w.Flush()
+}
+
+// formatSessionDuration 以秒为精度显示会话时长
+func formatSessionDuration(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	return d.Round(time.Second).String()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseSessionMessages(t *testing.T) {
+	ts := time.Now()
+	cases := []struct {
+		message string
+		typ     string
+		user    string
+		ip      string
+		port    int
+	}{
+		{"pam_unix(sshd:session): session opened for user alice(uid=1000) by (uid=0)", EventSessionOpened, "alice", "", 0},
+		{"pam_unix(sshd:session): session closed for user alice", EventSessionClosed, "alice", "", 0},
+		{"Disconnected from user alice 203.0.113.5 port 50022", EventSessionClosed, "alice", "203.0.113.5", 50022},
+		{"Received disconnect from 203.0.113.5 port 50022:11: disconnected by user", EventSessionClosed, "", "203.0.113.5", 50022},
+	}
+	for _, tc := range cases {
+		event, ok := parseJournalMessage(tc.message, "host", ts)
+		if !ok {
+			t.Fatalf("expected event for %q", tc.message)
+		}
+		if event.Type != tc.typ || event.User != tc.user || event.IP != tc.ip || event.Port != tc.port {
+			t.Fatalf("unexpected event for %q: %+v", tc.message, event)
+		}
+	}
+
+	if _, ok := parseSessionMessage("Received disconnect from 203.0.113.5 port 50022:11: disconnected by user [preauth]", "host", ts); ok {
+		t.Fatalf("preauth disconnect must not close a session")
+	}
+}
+
+func TestParseAuthLogLinePID(t *testing.T) {
+	event, ok := parseAuthLogLine("Mar  3 10:00:00 web sshd[4321]: pam_unix(sshd:session): session closed for user alice", nil, nil)
+	if !ok || event.PID != 4321 || event.Type != EventSessionClosed {
+		t.Fatalf("unexpected event: %+v ok=%v", event, ok)
+	}
+}
+
+func TestSessionTrackerLifecycle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), sessionsFileName)
+	tracker, err := loadSessionTracker(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	login := &LoginEvent{Type: EventLoginSuccess, User: "alice", IP: "203.0.113.5", Port: 50022, PID: 4321, Method: "publickey", Timestamp: start, Location: "test"}
+	if !tracker.observe(login) {
+		t.Fatalf("login_success must pass through")
+	}
+	if tracker.observe(&LoginEvent{Type: EventSessionOpened, User: "alice", PID: 4321, Timestamp: start}) {
+		t.Fatalf("session_opened must not be emitted")
+	}
+
+	// 会话表持久化后可被 sessions 命令读取
+	reloaded, err := loadSessionTracker(path)
+	if err != nil || len(reloaded.sessions) != 1 || reloaded.sessions[0].User != "alice" {
+		t.Fatalf("reloaded sessions = %+v err=%v", reloaded.sessions, err)
+	}
+
+	closed := &LoginEvent{Type: EventSessionClosed, IP: "203.0.113.5", Port: 50022, Timestamp: start.Add(90 * time.Minute)}
+	if !tracker.observe(closed) {
+		t.Fatalf("matching session_closed must be emitted")
+	}
+	if closed.User != "alice" || closed.Method != "publickey" || closed.Location != "test" || closed.Duration != 90*time.Minute {
+		t.Fatalf("closed event not enriched: %+v", closed)
+	}
+
+	// 同一会话的后续关闭日志被丢弃
+	dup := &LoginEvent{Type: EventSessionClosed, User: "alice", PID: 4321, Timestamp: start.Add(90 * time.Minute)}
+	if tracker.observe(dup) {
+		t.Fatalf("duplicate close must be dropped")
+	}
+	if len(tracker.sessions) != 0 {
+		t.Fatalf("expected empty session table, got %d", len(tracker.sessions))
+	}
+}
+
+func TestSessionTrackerNil(t *testing.T) {
+	var tracker *sessionTracker
+	if !tracker.observe(&LoginEvent{Type: EventLoginSuccess}) {
+		t.Fatalf("nil tracker must pass login events")
+	}
+	if tracker.observe(&LoginEvent{Type: EventSessionClosed}) {
+		t.Fatalf("nil tracker must drop session events")
+	}
+}
@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+)
+
+// severityPolicy 提供判定事件级别所需的常用国家配置与来源国家查询，零值表示只按事件类型判定
+type severityPolicy struct {
+	config  func() *SeverityConfig
+	country func(ip string) string
+}
+
+func defaultSeverityPolicy() severityPolicy {
+	return severityPolicy{
+		config: func() *SeverityConfig {
+			cfg, err := loadConfig()
+			if err != nil || cfg == nil {
+				return nil
+			}
+			return cfg.Severity
+		},
+		country: func(ip string) string {
+			return GetIPLookup().LookupResult(ip).CountryCode
+		},
+	}
+}
+
+// abroad 判断事件来源国家是否不在常用国家列表中。未配置常用国家或查询不到国家时视为非境外，
+// 避免 IP 查询失败时误报最高级别
+func (p severityPolicy) abroad(event *LoginEvent) bool {
+	if p.config == nil || p.country == nil || event.IP == "" {
+		return false
+	}
+	cfg := p.config()
+	if cfg == nil || len(cfg.HomeCountries) == 0 {
+		return false
+	}
+	countryCode := p.country(event.IP)
+	if countryCode == "" {
+		return false
+	}
+	for _, home := range cfg.HomeCountries {
+		if strings.EqualFold(strings.TrimSpace(home), countryCode) {
+			return false
+		}
+	}
+	debugf("notify: %s 来源国家 %s 不在常用国家列表中", event.IP, strings.ToUpper(countryCode))
+	return true
+}
+
+// configureSeverity 保存事件级别配置，常用国家为空时清除配置
+func configureSeverity(sevCfg SeverityConfig) error {
+	if err := ValidateSeverityConfig(&sevCfg); err != nil {
+		return err
+	}
+
+	cm := NewConfigManager()
+	cfg, err := cm.LoadConfig()
+	if err != nil {
+		if err != ErrConfigNotFound {
+			return fmt.Errorf("加载配置失败: %w", err)
+		}
+		cfg = &Config{}
+	}
+
+	cfg.Severity = &sevCfg
+	if len(sevCfg.HomeCountries) == 0 {
+		cfg.Severity = nil
+	}
+	if err := saveConfigWithBackup(cm, cfg); err != nil {
+		return err
+	}
+	printSeveritySummary(&sevCfg)
+	return nil
+}
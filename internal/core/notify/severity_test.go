@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"net/http"
+	"testing"
+)
+
+func testSeverityPolicy(home []string, country string) severityPolicy {
+	return severityPolicy{
+		config:  func() *SeverityConfig { return &SeverityConfig{HomeCountries: home} },
+		country: func(string) string { return country },
+	}
+}
+
+func TestEventSeverityHomeCountries(t *testing.T) {
+	root := testLoginEvent()
+	user := testLoginEvent()
+	user.User = "deploy"
+
+	cases := []struct {
+		name   string
+		event  LoginEvent
+		policy severityPolicy
+		want   string
+	}{
+		{"no policy", root, severityPolicy{}, SeverityHigh},
+		{"no home countries", root, testSeverityPolicy(nil, "US"), SeverityHigh},
+		{"home country", root, testSeverityPolicy([]string{"cn", "JP"}, "JP"), SeverityHigh},
+		{"unknown country", root, testSeverityPolicy([]string{"JP"}, ""), SeverityHigh},
+		{"abroad", root, testSeverityPolicy([]string{"JP"}, "US"), SeverityCritical},
+		{"abroad non-root", user, testSeverityPolicy([]string{"JP"}, "US"), SeverityMedium},
+	}
+	for _, tc := range cases {
+		if got := eventSeverity(&tc.event, tc.policy); got != tc.want {
+			t.Fatalf("%s: eventSeverity = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRootLoginAbroadPushPriority(t *testing.T) {
+	cases := []struct {
+		country  string
+		severity string
+		ntfy     float64
+		pushover float64
+		retry    bool
+	}{
+		{"JP", SeverityHigh, 4, 1, false},
+		{"US", SeverityCritical, 5, 2, true},
+	}
+	for _, tc := range cases {
+		ntfyServer, ntfyReqs := newRecordingServer(t, respondWith(http.StatusOK, `{"id":"abc"}`))
+		pushoverServer, pushoverReqs := newRecordingServer(t, respondWith(http.StatusOK, `{"status":1,"request":"x"}`))
+		ntfy := NewNtfyNotifier(&NtfyConfig{Server: ntfyServer.URL, Topic: "ssh"})
+		pushover := NewPushoverNotifier(&PushoverConfig{UserKey: "u", AppToken: "a"})
+		pushover.endpoint = pushoverServer.URL
+
+		proc := &eventProcessor{
+			notify:   true,
+			filter:   newNotifyFilter(NotifyOnAll, 0, 0),
+			sessions: &sessionTracker{},
+			severity: testSeverityPolicy([]string{"JP"}, tc.country),
+			dispatch: func(event *LoginEvent) error {
+				if err := ntfy.Send(*event); err != nil {
+					return err
+				}
+				return pushover.Send(*event)
+			},
+		}
+		event := testLoginEvent()
+		event.Severity = ""
+		event.Location = "Tokyo"
+		proc.handle(&event)
+
+		if event.Severity != tc.severity {
+			t.Fatalf("%s: severity = %s, want %s", tc.country, event.Severity, tc.severity)
+		}
+		if len(*ntfyReqs) != 1 || len(*pushoverReqs) != 1 {
+			t.Fatalf("%s: expected one request per channel, got ntfy=%d pushover=%d", tc.country, len(*ntfyReqs), len(*pushoverReqs))
+		}
+		if got := (*ntfyReqs)[0].body["priority"]; got != tc.ntfy {
+			t.Fatalf("%s: ntfy priority = %v, want %v", tc.country, got, tc.ntfy)
+		}
+		body := (*pushoverReqs)[0].body
+		if body["priority"] != tc.pushover {
+			t.Fatalf("%s: pushover priority = %v, want %v", tc.country, body["priority"], tc.pushover)
+		}
+		if _, ok := body["retry"]; ok != tc.retry {
+			t.Fatalf("%s: unexpected pushover retry: %v", tc.country, body)
+		}
+		if tc.retry && (body["retry"] != float64(defaultPushoverRetry) || body["expire"] != float64(defaultPushoverExpire)) {
+			t.Fatalf("%s: unexpected pushover retry/expire: %v", tc.country, body)
+		}
+	}
+}
+
+func TestValidateSeverityConfig(t *testing.T) {
+	if err := ValidateSeverityConfig(&SeverityConfig{HomeCountries: []string{"CN", "jp"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ValidateSeverityConfig(&SeverityConfig{HomeCountries: []string{"CHN"}}); err == nil {
+		t.Fatal("expected error for invalid country code")
+	}
+}
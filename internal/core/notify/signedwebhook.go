@@ -0,0 +1,125 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// webhookSchemaVersion webhook 消息格式版本，字段只增不改；删除或修改字段时递增
+const webhookSchemaVersion = 1
+
+// webhook 渠道的请求头
+const (
+	webhookSignatureHeader = "X-SSHield-Signature" // sha256=<hex(HMAC-SHA256(secret, timestamp + "." + body))>
+	webhookTimestampHeader = "X-SSHield-Timestamp" // 发送时的 Unix 秒，接收方应拒绝与当前时间相差过大的请求
+	webhookEventIDHeader   = "X-SSHield-Event-ID"
+)
+
+// webhookPayload webhook 渠道的消息格式（version 1）：
+//
+//	{
+//	  "version": 1,
+//	  "id": "3f9a...",                       // 事件 ID，同一事件重复发送时不变，可用于去重
+//	  "sent_at": "2025-03-03T10:00:01Z",     // 发送时间（UTC）
+//	  "event": {                              // 字段同 file 渠道，空字段省略
+//	    "time": "2025-03-03T10:00:00Z",      // 事件时间（UTC）
+//	    "type": "login_success", "subtype": "", "severity": "high",
+//	    "hostname": "web-1", "host_ip": "10.0.0.1",
+//	    "user": "root", "ip": "203.0.113.9", "port": 52214, "method": "publickey", "location": "...",
+//	    "target_user": "", "command": "", "pid": 1234, "duration_seconds": 0,
+//	    "action": "", "log_path": "/var/log/auth.log", "message": "Accepted publickey for root ..."
+//	  }
+//	}
+type webhookPayload struct {
+	Version int         `json:"version"`
+	ID      string      `json:"id"`
+	SentAt  string      `json:"sent_at"`
+	Event   eventRecord `json:"event"`
+}
+
+// SignedWebhookNotifier 以固定格式 POST 事件 JSON，并用共享密钥签名
+type SignedWebhookNotifier struct {
+	url        string
+	secret     string
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewSignedWebhookNotifier 创建签名 webhook 通知器
+func NewSignedWebhookNotifier(cfg *SignedWebhookConfig) *SignedWebhookNotifier {
+	return &SignedWebhookNotifier{
+		url:        cfg.URL,
+		secret:     cfg.Secret,
+		httpClient: newChannelHTTPClient(),
+		now:        time.Now,
+	}
+}
+
+// Send 生成消息并签名，遇到限流时退避重试
+func (w *SignedWebhookNotifier) Send(event LoginEvent) error {
+	now := w.now()
+	id := webhookEventID(event)
+	body, err := json.Marshal(webhookPayload{
+		Version: webhookSchemaVersion,
+		ID:      id,
+		SentAt:  now.UTC().Format(time.RFC3339),
+		Event:   newEventRecord(event),
+	})
+	if err != nil {
+		return fmt.Errorf("编码事件失败: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	header := http.Header{}
+	header.Set(webhookTimestampHeader, timestamp)
+	header.Set(webhookSignatureHeader, "sha256="+webhookSignature(w.secret, timestamp, body))
+	header.Set(webhookEventIDHeader, id)
+
+	if _, err := postJSONWithBackoff(w.httpClient, w.url, json.RawMessage(body), header); err != nil {
+		return fmt.Errorf("发送 webhook 失败: %w", err)
+	}
+	return nil
+}
+
+// Test 发送测试事件
+func (w *SignedWebhookNotifier) Test() error {
+	if err := w.Send(channelTestEvent()); err != nil {
+		return fmt.Errorf("webhook 测试失败: %w", err)
+	}
+	return nil
+}
+
+// webhookSignature 签名内容为 "时间戳.请求体"，时间戳参与签名，防止旧请求被重放
+func webhookSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookEventID 由事件的关键字段计算，同一条日志重复处理（如 sweep 重跑）得到相同 ID
+func webhookEventID(event LoginEvent) string {
+	h := sha256.New()
+	for _, part := range []string{
+		event.Hostname,
+		event.Type,
+		event.Subtype,
+		event.Timestamp.UTC().Format(time.RFC3339Nano),
+		event.User,
+		event.IP,
+		strconv.Itoa(event.Port),
+		strconv.Itoa(event.PID),
+		event.LogPath,
+		event.Message,
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
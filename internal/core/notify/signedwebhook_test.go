@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSignedWebhookNotifier(t *testing.T) {
+	var (
+		header http.Header
+		body   []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	const secret = "0123456789abcdef"
+	n := NewSignedWebhookNotifier(&SignedWebhookConfig{URL: server.URL, Secret: secret})
+	n.now = func() time.Time { return time.Unix(1741000000, 0) }
+	event := testLoginEvent()
+	event.Timestamp = time.Date(2025, 3, 3, 18, 0, 0, 0, shanghaiLocation)
+	if err := n.Send(event); err != nil {
+		t.Fatal(err)
+	}
+
+	if header.Get(webhookTimestampHeader) != "1741000000" {
+		t.Fatalf("unexpected timestamp header: %q", header.Get(webhookTimestampHeader))
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("1741000000."))
+	mac.Write(body)
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); header.Get(webhookSignatureHeader) != want {
+		t.Fatalf("signature mismatch: got %s want %s", header.Get(webhookSignatureHeader), want)
+	}
+
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Version != 1 || payload.SentAt != "2025-03-03T11:06:40Z" {
+		t.Fatalf("unexpected envelope: %+v", payload)
+	}
+	if payload.Event.Time != "2025-03-03T10:00:00Z" || payload.Event.Severity != SeverityMedium || payload.Event.User != "root" {
+		t.Fatalf("unexpected event: %+v", payload.Event)
+	}
+	if payload.ID == "" || header.Get(webhookEventIDHeader) != payload.ID {
+		t.Fatalf("event ID header %q does not match payload %q", header.Get(webhookEventIDHeader), payload.ID)
+	}
+}
+
+func TestWebhookEventIDStable(t *testing.T) {
+	a := testLoginEvent()
+	b := testLoginEvent()
+	b.Timestamp = b.Timestamp.In(shanghaiLocation)
+	b.Location = "resolved later"
+	if webhookEventID(a) != webhookEventID(b) {
+		t.Fatal("event ID should only depend on the log record")
+	}
+	b.Port = 2222
+	if webhookEventID(a) == webhookEventID(b) {
+		t.Fatal("different events should have different IDs")
+	}
+}
+
+func TestValidateSignedWebhookChannel(t *testing.T) {
+	if err := ValidateChannelConfig(&ChannelConfig{Type: "webhook", Webhook: &SignedWebhookConfig{URL: "https://hooks.example.com/x", Secret: "0123456789abcdef"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, cfg := range []*SignedWebhookConfig{
+		nil,
+		{URL: "https://hooks.example.com/x"},
+		{URL: "https://hooks.example.com/x", Secret: "short"},
+		{URL: "hooks.example.com", Secret: "0123456789abcdef"},
+	} {
+		if err := ValidateChannelConfig(&ChannelConfig{Type: "webhook", Webhook: cfg}); err == nil {
+			t.Fatalf("expected validation error for %+v", cfg)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package notify
+
+import (
+	"path"
+	"strings"
+)
+
+var (
+	// sshd 的 systemd 单元：Debian/Ubuntu 为 ssh.service，RHEL/Arch 为 sshd.service，
+	// socket 激活时每个连接对应一个 sshd@<实例>.service / ssh@<实例>.service
+	defaultJournalUnits = []string{"sshd.service", "ssh.service", "sshd@*.service", "ssh@*.service"}
+	// sshd 的 syslog 标识与进程名：OpenSSH 9.8+ 将每连接工作拆分到 sshd-session 与 sshd-auth
+	defaultSSHDIdentifiers = []string{"sshd", "sshd-session", "sshd-auth"}
+)
+
+// 提权与账户管理事件来自用户会话中的 sudo/su/useradd 等进程，不属于 sshd 单元，需要按进程名匹配
+var auditComms = []string{
+	"sudo", "su",
+	"useradd", "usermod", "userdel", "groupadd", "groupdel", "passwd", "chpasswd", "gpasswd",
+}
+
+// sourceMatcher 按 systemd 单元、syslog 标识与进程名筛选 sshd 日志，三者均支持通配符（path.Match 语法）
+// 审计进程（auditComms）始终匹配
+type sourceMatcher struct {
+	units       []string
+	identifiers []string
+	comms       []string
+}
+
+// newSourceMatcher 创建日志来源匹配器，未指定的项使用默认值
+func newSourceMatcher(units, identifiers, comms []string) *sourceMatcher {
+	m := &sourceMatcher{
+		units:       normalizePatterns(units, defaultJournalUnits),
+		identifiers: normalizePatterns(identifiers, defaultSSHDIdentifiers),
+		comms:       normalizePatterns(comms, defaultSSHDIdentifiers),
+	}
+	for i, unit := range m.units {
+		if !strings.Contains(unit, ".") {
+			m.units[i] = unit + ".service"
+		}
+	}
+	return m
+}
+
+func normalizePatterns(patterns, defaults []string) []string {
+	var out []string
+	for _, p := range patterns {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	if len(out) == 0 {
+		out = append(out, defaults...)
+	}
+	return out
+}
+
+// journalArgs 生成 journalctl 匹配条件：单元、标识与进程名取并集
+// journalctl 的字段匹配不支持通配符：带通配符的单元依靠 syslog 标识匹配（socket 激活实例的标识仍为 sshd），
+// 标识或进程名本身带通配符时无法在服务端过滤，返回空并完全由 matchJournal 在本地筛选
+// -u 无法与 "+" 组合，这里直接使用 _SYSTEMD_UNIT 字段匹配
+func (m *sourceMatcher) journalArgs() []string {
+	var matches []string
+	for _, unit := range m.units {
+		if !hasGlob(unit) {
+			matches = append(matches, "_SYSTEMD_UNIT="+unit)
+		}
+	}
+	for _, id := range m.identifiers {
+		if hasGlob(id) {
+			return nil
+		}
+		matches = append(matches, "SYSLOG_IDENTIFIER="+id)
+	}
+	for _, comm := range append(append([]string{}, m.comms...), auditComms...) {
+		if hasGlob(comm) {
+			return nil
+		}
+		matches = append(matches, "_COMM="+comm)
+	}
+
+	args := make([]string, 0, len(matches)*2)
+	for i, match := range matches {
+		if i > 0 {
+			args = append(args, "+")
+		}
+		args = append(args, match)
+	}
+	return args
+}
+
+// matchJournal 判断 journald 记录是否来自 sshd 或审计进程
+func (m *sourceMatcher) matchJournal(unit, identifier, comm string) bool {
+	return matchAny(m.units, unit) || m.matchIdentifier(identifier) || matchAny(m.comms, comm) || isAuditComm(comm)
+}
+
+// verifiedSSHD 判断 journald 记录是否确实来自以 root 运行的 sshd 进程。
+// _COMM 与 _UID 由 journald 根据发送进程填写，SYSLOG_IDENTIFIER 则可由任意进程自行指定
+func (m *sourceMatcher) verifiedSSHD(comm, uid string) bool {
+	return uid == "0" && matchAny(m.comms, comm)
+}
+
+// matchField 判断 journald 字段值是否来自 sshd 或审计进程，用于按字段索引筛选条目
+func (m *sourceMatcher) matchField(field, value string) bool {
+	switch field {
+	case "_SYSTEMD_UNIT":
+		return matchAny(m.units, value)
+	case "SYSLOG_IDENTIFIER":
+		return m.matchIdentifier(value)
+	case "_COMM":
+		return matchAny(m.comms, value) || isAuditComm(value)
+	default:
+		return false
+	}
+}
+
+// matchIdentifier 判断 syslog 标识（日志文件中的进程名）是否来自 sshd 或审计进程
+func (m *sourceMatcher) matchIdentifier(identifier string) bool {
+	return matchAny(m.identifiers, identifier) || isAuditComm(identifier)
+}
+
+// describe 返回用于启动提示的来源描述
+func (m *sourceMatcher) describe() string {
+	return "units=" + strings.Join(m.units, ",") + " 标识=" + strings.Join(m.identifiers, ",")
+}
+
+func isAuditComm(name string) bool {
+	for _, comm := range auditComms {
+		if comm == name {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(patterns []string, value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func hasGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSourceMatcherJournalArgs(t *testing.T) {
+	m := newSourceMatcher([]string{"sshd", "sshd@*.service"}, nil, []string{"sshd"})
+	got := strings.Join(m.journalArgs(), " ")
+	want := "_SYSTEMD_UNIT=sshd.service + SYSLOG_IDENTIFIER=sshd + SYSLOG_IDENTIFIER=sshd-session + SYSLOG_IDENTIFIER=sshd-auth + _COMM=sshd + _COMM=sudo"
+	if !strings.HasPrefix(got, want) {
+		t.Fatalf("args = %s, want prefix %s", got, want)
+	}
+	if !strings.HasSuffix(got, "_COMM=gpasswd") {
+		t.Fatalf("audit comms should always be matched, got %s", got)
+	}
+
+	// 标识带通配符时无法在服务端过滤
+	m = newSourceMatcher(nil, []string{"sshd*"}, nil)
+	if args := m.journalArgs(); args != nil {
+		t.Fatalf("expected no server-side matches, got %v", args)
+	}
+}
+
+func TestSourceMatcherMatch(t *testing.T) {
+	m := newSourceMatcher(nil, nil, nil)
+	cases := []struct {
+		unit, ident, comm string
+		want              bool
+	}{
+		{"sshd@12-10.0.0.1:22-203.0.113.9:40022.service", "", "", true},
+		{"ssh.service", "sshd", "sshd", true},
+		{"", "sshd-session", "sshd-session", true},
+		{"", "", "sudo", true},
+		{"cron.service", "CRON", "cron", false},
+	}
+	for _, tc := range cases {
+		if got := m.matchJournal(tc.unit, tc.ident, tc.comm); got != tc.want {
+			t.Fatalf("matchJournal(%q, %q, %q) = %v, want %v", tc.unit, tc.ident, tc.comm, got, tc.want)
+		}
+	}
+
+	m = newSourceMatcher(nil, []string{"my-sshd"}, nil)
+	if _, ok := parseAuthLogLine("Mar  3 10:00:00 web my-sshd[42]: Accepted password for alice from 203.0.113.9 port 40022 ssh2", nil, m); !ok {
+		t.Fatalf("custom identifier should be parsed")
+	}
+	if _, ok := parseAuthLogLine("Mar  3 10:00:00 web sshd[42]: Accepted password for alice from 203.0.113.9 port 40022 ssh2", nil, m); ok {
+		t.Fatalf("identifier not in the configured list should be ignored")
+	}
+	if _, ok := parseAuthLogLine("Mar  3 10:00:00 web myapp[42]: Accepted password for alice from 203.0.113.9 port 40022 ssh2", nil, nil); ok {
+		t.Fatalf("other processes should be ignored by default")
+	}
+}
+
+func TestSourceMatcherVerifiedSSHD(t *testing.T) {
+	m := newSourceMatcher(nil, nil, nil)
+	cases := []struct {
+		comm, uid string
+		want      bool
+	}{
+		{"sshd", "0", true},
+		{"sshd-session", "0", true},
+		{"logger", "1000", false}, // logger -t sshd 只能伪造 SYSLOG_IDENTIFIER
+		{"sshd", "1000", false},
+		{"sshd", "", false},
+	}
+	for _, tc := range cases {
+		if got := m.verifiedSSHD(tc.comm, tc.uid); got != tc.want {
+			t.Fatalf("verifiedSSHD(%q, %q) = %v, want %v", tc.comm, tc.uid, got, tc.want)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 )
 
 // 存储读取进度的文件
@@ -20,6 +21,11 @@ type SourceState struct {
 // CursorStore 管理状态持久化
 type CursorStore struct {
 	path string
+
+	// 多个日志源并行运行时，各自持有独立的状态副本，保存时只把自己负责的部分合并到共享状态
+	mu     *sync.Mutex
+	shared *SourceState
+	file   string // 负责的日志文件，为空表示 journald
 }
 
 // NewCursorStore 创建游标管理器
@@ -65,11 +71,42 @@ func (c *CursorStore) Load() (*SourceState, error) {
 	return state, nil
 }
 
+// forSource 为并行运行的日志源返回独立的状态副本与对应的 CursorStore，file 为空表示 journald
+func (c *CursorStore) forSource(state *SourceState, file string) (*CursorStore, *SourceState) {
+	if c.mu == nil {
+		c.mu = &sync.Mutex{}
+		c.shared = state
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	local := &SourceState{JournalCursor: state.JournalCursor, FileOffsets: make(map[string]int64)}
+	if file != "" {
+		local.FileOffsets[file] = state.FileOffsets[file]
+	}
+	return &CursorStore{path: c.path, mu: c.mu, shared: c.shared, file: file}, local
+}
+
 // Save 持久化状态
 func (c *CursorStore) Save(state *SourceState) error {
 	if state == nil {
 		return nil
 	}
+	if c.mu != nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if state != c.shared {
+			if c.file != "" {
+				if c.shared.FileOffsets == nil {
+					c.shared.FileOffsets = make(map[string]int64)
+				}
+				c.shared.FileOffsets[c.file] = state.FileOffsets[c.file]
+			} else {
+				c.shared.JournalCursor = state.JournalCursor
+			}
+			state = c.shared
+		}
+	}
 	if state.FileOffsets == nil {
 		state.FileOffsets = make(map[string]int64)
 	}
@@ -84,11 +121,16 @@ func (c *CursorStore) Save(state *SourceState) error {
 
 // defaultCursorPath 根据身份选择游标存储位置
 func defaultCursorPath() (string, error) {
+	return DefaultStatePath(cursorFileName)
+}
+
+// DefaultStatePath 根据身份选择状态文件位置：root 使用 /var/lib/sshield，普通用户使用用户配置目录
+func DefaultStatePath(name string) (string, error) {
 	if os.Geteuid() == 0 {
 		if err := os.MkdirAll(defaultStateRoot, 0700); err != nil {
 			return "", fmt.Errorf("failed to create state directory: %w", err)
 		}
-		return filepath.Join(defaultStateRoot, cursorFileName), nil
+		return filepath.Join(defaultStateRoot, name), nil
 	}
 
 	configDir, err := os.UserConfigDir()
@@ -100,7 +142,7 @@ func defaultCursorPath() (string, error) {
 		configDir = filepath.Join(home, ".config")
 	}
 
-	path := filepath.Join(configDir, "sshield", cursorFileName)
+	path := filepath.Join(configDir, "sshield", name)
 	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
 		return "", fmt.Errorf("failed to create user state directory: %w", err)
 	}
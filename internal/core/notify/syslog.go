@@ -0,0 +1,237 @@
+package notify
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	syslogDialTimeout = 5 * time.Second
+	// RFC 5424 结构化数据 ID，32473 为 RFC 5612 保留给文档示例的企业号
+	syslogSDID    = "sshield@32473"
+	syslogAppName = "sshield"
+)
+
+// 默认本地 syslog 套接字，按顺序尝试
+var syslogLocalSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// syslog facility 名称与编号（RFC 5424 表 1）
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslog severity 名称与编号
+var syslogSeverities = map[string]int{
+	"emerg": 0, "alert": 1, "crit": 2, "err": 3, "warning": 4, "notice": 5, "info": 6, "debug": 7,
+}
+
+// syslogSeverity 事件级别映射到 syslog severity
+func syslogSeverity(severity string) int {
+	switch severity {
+	case SeverityCritical:
+		return syslogSeverities["crit"]
+	case SeverityHigh:
+		return syslogSeverities["err"]
+	case SeverityLow:
+		return syslogSeverities["notice"]
+	case SeverityInfo:
+		return syslogSeverities["info"]
+	default:
+		return syslogSeverities["warning"]
+	}
+}
+
+// SyslogNotifier 以 RFC 5424 格式写入 syslog
+type SyslogNotifier struct {
+	cfg SyslogConfig
+}
+
+// NewSyslogNotifier 创建 syslog 通知器
+func NewSyslogNotifier(cfg *SyslogConfig) *SyslogNotifier {
+	return &SyslogNotifier{cfg: *cfg}
+}
+
+// Send 格式化并发送一条消息，每次发送单独建立连接
+func (s *SyslogNotifier) Send(event LoginEvent) error {
+	msg, err := s.format(event)
+	if err != nil {
+		return err
+	}
+
+	conn, network, err := s.dial()
+	if err != nil {
+		return fmt.Errorf("连接 syslog 失败: %w", err)
+	}
+	defer conn.Close()
+	_ = conn.SetWriteDeadline(time.Now().Add(syslogDialTimeout))
+
+	var frame string
+	switch network {
+	case "tcp":
+		// RFC 6587 octet counting
+		frame = strconv.Itoa(len(msg)) + " " + msg
+	case "unix":
+		frame = msg + "\n"
+	default:
+		frame = msg
+	}
+	if _, err := conn.Write([]byte(frame)); err != nil {
+		return fmt.Errorf("写入 syslog 失败: %w", err)
+	}
+	return nil
+}
+
+// Test 测试 syslog 配置
+func (s *SyslogNotifier) Test() error {
+	if err := s.Send(channelTestEvent()); err != nil {
+		return fmt.Errorf("syslog 测试失败: %w", err)
+	}
+	return nil
+}
+
+// dial 建立连接，返回实际使用的网络类型；本地套接字先尝试数据报再尝试流
+func (s *SyslogNotifier) dial() (net.Conn, string, error) {
+	network := strings.ToLower(s.cfg.Network)
+	if network == "" {
+		network = "unix"
+	}
+	if network != "unix" {
+		conn, err := net.DialTimeout(network, s.cfg.Address, syslogDialTimeout)
+		return conn, network, err
+	}
+
+	addrs := syslogLocalSockets
+	if s.cfg.Address != "" {
+		addrs = []string{s.cfg.Address}
+	}
+	var lastErr error
+	for _, addr := range addrs {
+		for _, n := range []string{"unixgram", "unix"} {
+			conn, err := net.DialTimeout(n, addr, syslogDialTimeout)
+			if err == nil {
+				return conn, n, nil
+			}
+			lastErr = err
+		}
+	}
+	return nil, "", lastErr
+}
+
+// format 生成 RFC 5424 消息：<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD] MSG，MSG 以 BOM 开头表示 UTF-8
+func (s *SyslogNotifier) format(event LoginEvent) (string, error) {
+	facility := syslogFacilities["auth"]
+	if s.cfg.Facility != "" {
+		f, ok := syslogFacilities[strings.ToLower(s.cfg.Facility)]
+		if !ok {
+			return "", fmt.Errorf("未知 syslog facility: %s", s.cfg.Facility)
+		}
+		facility = f
+	}
+	severity := syslogSeverity(event.Severity)
+	if s.cfg.Severity != "" {
+		sev, ok := syslogSeverities[strings.ToLower(s.cfg.Severity)]
+		if !ok {
+			return "", fmt.Errorf("未知 syslog severity: %s", s.cfg.Severity)
+		}
+		severity = sev
+	}
+
+	timestamp := "-"
+	if !event.Timestamp.IsZero() {
+		timestamp = event.Timestamp.Format("2006-01-02T15:04:05.000000Z07:00")
+	}
+	hostname := event.Hostname
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+
+	return fmt.Sprintf("<%d>1 %s %s %s %d %s %s \ufeff%s",
+		facility*8+severity,
+		timestamp,
+		syslogHeaderField(hostname, 255),
+		syslogAppName,
+		os.Getpid(),
+		syslogHeaderField(event.Type, 32),
+		syslogStructuredData(event),
+		syslogText(event),
+	), nil
+}
+
+// syslogHeaderField 头部字段只允许可打印 ASCII 且不含空格，为空时使用 NILVALUE
+func syslogHeaderField(s string, max int) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r > 32 && r < 127 {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+		if b.Len() == max {
+			break
+		}
+	}
+	if b.Len() == 0 {
+		return "-"
+	}
+	return b.String()
+}
+
+// syslogStructuredData 每个非空的事件字段作为一个 SD-PARAM
+func syslogStructuredData(event LoginEvent) string {
+	params := []struct{ name, value string }{
+		{"type", event.Type},
+		{"subtype", event.Subtype},
+		{"severity", event.Severity},
+		{"user", event.User},
+		{"ip", event.IP},
+		{"port", intParam(event.Port)},
+		{"method", event.Method},
+		{"location", event.Location},
+		{"host_ip", event.HostIP},
+		{"target_user", event.TargetUser},
+		{"command", event.Command},
+		{"pid", intParam(event.PID)},
+		{"duration", intParam(int(event.Duration.Seconds()))},
+		{"action", event.Action},
+		{"log_path", event.LogPath},
+	}
+
+	var b strings.Builder
+	b.WriteString("[" + syslogSDID)
+	for _, p := range params {
+		if p.value == "" {
+			continue
+		}
+		fmt.Fprintf(&b, ` %s="%s"`, p.name, escapeSDParam(p.value))
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+func intParam(v int) string {
+	if v <= 0 {
+		return ""
+	}
+	return strconv.Itoa(v)
+}
+
+// escapeSDParam 转义 PARAM-VALUE 中的 '"'、'\' 与 ']'
+func escapeSDParam(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
+
+// syslogText 消息正文：事件摘要加原始日志，换行替换为空格
+func syslogText(event LoginEvent) string {
+	text := fmt.Sprintf("%s user=%s ip=%s", event.displayType(), orDash(event.User), orDash(event.IP))
+	if event.Message != "" {
+		text += ": " + event.Message
+	}
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(text)
+}
@@ -0,0 +1,116 @@
+package notify
+
+import (
+	"bufio"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogFormat(t *testing.T) {
+	event := testLoginEvent()
+	event.Severity = SeverityHigh
+	event.Message = `Accepted publickey for root "x" ]`
+	msg, err := NewSyslogNotifier(&SyslogConfig{Facility: "authpriv"}).format(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// authpriv(10)*8 + err(3)
+	if !strings.HasPrefix(msg, "<83>1 2025-03-03T10:00:00.000000Z web-1 sshield ") {
+		t.Fatalf("unexpected header: %s", msg)
+	}
+	if !strings.Contains(msg, " login_success [sshield@32473 type=\"login_success\" severity=\"high\" user=\"root\" ip=\"203.0.113.9\"") {
+		t.Fatalf("unexpected structured data: %s", msg)
+	}
+	if !strings.HasSuffix(msg, "] \ufefflogin_success user=root ip=203.0.113.9: Accepted publickey for root \"x\" ]") {
+		t.Fatalf("unexpected message: %s", msg)
+	}
+
+	fixed, _ := NewSyslogNotifier(&SyslogConfig{Facility: "local0", Severity: "notice"}).format(event)
+	if !strings.HasPrefix(fixed, "<133>1 ") {
+		t.Fatalf("fixed severity not applied: %s", fixed)
+	}
+}
+
+func TestEscapeSDParam(t *testing.T) {
+	if got := escapeSDParam(`a"b\c]d`); got != `a\"b\\c\]d` {
+		t.Fatalf("unexpected escape: %s", got)
+	}
+}
+
+func TestSyslogNotifierUnixgram(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "log")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: sock, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err := NewSyslogNotifier(&SyslogConfig{Address: sock}).Send(testLoginEvent()); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4096)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// auth(4)*8 + warning(4)
+	if !strings.HasPrefix(string(buf[:n]), "<36>1 ") {
+		t.Fatalf("unexpected datagram: %s", buf[:n])
+	}
+}
+
+func TestSyslogNotifierTCPFraming(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		length, _ := r.ReadString(' ')
+		rest := make([]byte, 4096)
+		n, _ := r.Read(rest)
+		received <- length + string(rest[:n])
+	}()
+
+	if err := NewSyslogNotifier(&SyslogConfig{Network: "tcp", Address: ln.Addr().String()}).Send(testLoginEvent()); err != nil {
+		t.Fatal(err)
+	}
+	frame := <-received
+	length, msg, _ := strings.Cut(frame, " ")
+	if length != strconv.Itoa(len(msg)) {
+		t.Fatalf("octet count %s does not match message length %d", length, len(msg))
+	}
+}
+
+func TestValidateSyslogChannel(t *testing.T) {
+	for _, ch := range []*ChannelConfig{
+		{Type: "syslog", Syslog: &SyslogConfig{}},
+		{Type: "syslog", Syslog: &SyslogConfig{Network: "udp", Address: "10.0.0.5:514", Facility: "local4"}},
+	} {
+		if err := ValidateChannelConfig(ch); err != nil {
+			t.Fatalf("unexpected error for %s: %v", ch.Type, err)
+		}
+	}
+	for _, ch := range []*ChannelConfig{
+		{Type: "syslog", Syslog: &SyslogConfig{Network: "tcp"}},
+		{Type: "syslog", Syslog: &SyslogConfig{Facility: "nope"}},
+		{Type: "syslog", Syslog: &SyslogConfig{Network: "udp6"}},
+	} {
+		if err := ValidateChannelConfig(ch); err == nil {
+			t.Fatalf("expected validation error for %+v", ch.Syslog)
+		}
+	}
+}
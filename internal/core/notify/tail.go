@@ -0,0 +1,186 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 兜底检查间隔：inotify 可用时防止遗漏（如网络文件系统不产生事件）
+const tailFallbackInterval = 30 * time.Second
+
+// logTail 按行读取日志文件，跟踪模式下处理 logrotate 的两种轮转方式：
+// create（旧文件改名后新建）与 copytruncate（复制后原地截断）
+type logTail struct {
+	path    string
+	file    *os.File
+	reader  *bufio.Reader
+	offset  int64  // 已处理的完整行的结束偏移
+	partial string // 已读取但尚未以换行结尾的内容，等待写入方补全
+}
+
+func newLogTail(path string, file *os.File, offset int64) *logTail {
+	t := &logTail{path: path}
+	t.reset(file, offset)
+	return t
+}
+
+func (t *logTail) reset(file *os.File, offset int64) {
+	t.file = file
+	t.reader = bufio.NewReader(file)
+	t.offset = offset
+	t.partial = ""
+}
+
+// readLines 读取到文件末尾，对每个完整行调用 fn（offset 为该行结束后的偏移）
+func (t *logTail) readLines(ctx context.Context, fn func(line string, offset int64)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return context.Canceled
+		default:
+		}
+
+		chunk, err := t.reader.ReadString('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				t.partial += chunk
+				return nil
+			}
+			return fmt.Errorf("读取日志失败: %w", err)
+		}
+		line := t.partial + chunk
+		t.partial = ""
+		t.offset += int64(len(line))
+		fn(strings.TrimRight(line, "\r\n"), t.offset)
+	}
+}
+
+// checkRotation 在读到文件末尾后检查是否发生轮转，返回 true 表示已重新定位到新内容的开头
+func (t *logTail) checkRotation(ctx context.Context, fn func(line string, offset int64)) (bool, error) {
+	current, err := t.file.Stat()
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(t.path)
+	if err != nil {
+		// create 方式轮转时，旧文件已改名而新文件尚未创建，继续读取旧文件
+		return false, nil
+	}
+
+	if os.SameFile(current, info) {
+		if info.Size() >= t.offset+int64(len(t.partial)) {
+			return false, nil
+		}
+		// copytruncate：文件被原地截断，从头读取截断后写入的内容
+		debugf("notify: %s 已被截断，从头读取", t.path)
+		if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+			return false, err
+		}
+		t.reset(t.file, 0)
+		return true, nil
+	}
+
+	// create：写入方可能在改名后仍向旧文件追加，先读完旧文件再切换
+	if err := t.readLines(ctx, fn); err != nil {
+		return false, err
+	}
+	if t.partial != "" {
+		line := t.partial
+		t.partial = ""
+		t.offset += int64(len(line))
+		fn(strings.TrimRight(line, "\r\n"), t.offset)
+	}
+	file, err := os.Open(t.path)
+	if err != nil {
+		return false, err
+	}
+	debugf("notify: %s 已轮转，切换到新文件", t.path)
+	t.file.Close()
+	t.reset(file, 0)
+	return true, nil
+}
+
+func (t *logTail) Close() error {
+	return t.file.Close()
+}
+
+// logChangeWaiter 等待日志文件变化：优先通过 inotify 监听文件及其所在目录，不可用时按 poll 间隔轮询
+type logChangeWaiter struct {
+	path    string
+	poll    time.Duration
+	watcher fileWatcher
+}
+
+func newLogChangeWaiter(path string, poll time.Duration) *logChangeWaiter {
+	w := &logChangeWaiter{path: path, poll: poll}
+	watcher, err := newFileWatcher()
+	if err != nil {
+		debugf("notify: inotify 不可用，%s 改为每 %v 轮询: %v", path, poll, err)
+		return w
+	}
+	// 目录监听覆盖新文件的创建与写入，文件监听覆盖改名（改名后仍指向旧文件）
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		debugf("notify: %v，%s 改为每 %v 轮询", err, path, poll)
+		watcher.Close()
+		return w
+	}
+	w.watcher = watcher
+	w.rewatch()
+	return w
+}
+
+// rewatch 轮转后监听新文件
+func (w *logChangeWaiter) rewatch() {
+	if w.watcher == nil {
+		return
+	}
+	if err := w.watcher.Add(w.path); err != nil {
+		debugf("notify: %v", err)
+	}
+}
+
+// wait 等待文件变化或兜底检查时间到达，ctx 结束时返回错误
+func (w *logChangeWaiter) wait(ctx context.Context) error {
+	interval := tailFallbackInterval
+	if w.watcher == nil {
+		interval = w.poll
+	}
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	var events <-chan string
+	if w.watcher != nil {
+		events = w.watcher.Events()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return nil
+		case path, ok := <-events:
+			if !ok {
+				// 监听器异常退出，退化为轮询
+				w.watcher = nil
+				return nil
+			}
+			// 目录中其他文件的变化忽略
+			if path == w.path {
+				return nil
+			}
+		}
+	}
+}
+
+func (w *logChangeWaiter) Close() {
+	if w.watcher != nil {
+		w.watcher.Close()
+	}
+}
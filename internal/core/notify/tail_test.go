@@ -0,0 +1,110 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadLogFileFollowRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "auth.log")
+	if err := os.WriteFile(path, []byte("Mar  3 10:00:00 web sshd[1]: Server listening on 0.0.0.0 port 22.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ports := make(chan int, 16)
+	done := make(chan error, 1)
+	go func() {
+		_, err := readLogFile(ctx, path, 0, func(event *LoginEvent, _ int64) {
+			ports <- event.Port
+		}, true, 50*time.Millisecond, nil, nil)
+		done <- err
+	}()
+
+	line := func(port int) string {
+		return fmt.Sprintf("Mar  3 10:00:00 web sshd[1]: Accepted password for alice from 203.0.113.9 port %d ssh2\n", port)
+	}
+	appendTo := func(name, data string) {
+		f, err := os.OpenFile(name, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expect := func(want int) {
+		t.Helper()
+		select {
+		case got := <-ports:
+			if got != want {
+				t.Fatalf("got port %d, want %d", got, want)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("timed out waiting for port %d", want)
+		}
+	}
+	// 等待读取器定位到文件末尾
+	time.Sleep(200 * time.Millisecond)
+
+	// 不完整的行等补全后再处理
+	full := line(1001)
+	appendTo(path, full[:20])
+	time.Sleep(200 * time.Millisecond)
+	appendTo(path, full[20:])
+	expect(1001)
+
+	// create：改名后写入方仍向旧文件追加，随后写入新文件
+	rotated := path + ".1"
+	if err := os.Rename(path, rotated); err != nil {
+		t.Fatal(err)
+	}
+	appendTo(rotated, line(1002))
+	time.Sleep(100 * time.Millisecond)
+	appendTo(path, line(1003))
+	expect(1002)
+	expect(1003)
+
+	// copytruncate：原地截断后写入
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	appendTo(path, line(1004))
+	expect(1004)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatalf("readLogFile did not stop")
+	}
+	select {
+	case port := <-ports:
+		t.Fatalf("unexpected duplicate event for port %d", port)
+	default:
+	}
+}
+
+func TestReadLogFileRotatedSinceLastRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth.log")
+	data := "Mar  3 10:00:00 web sshd[1]: Accepted password for alice from 203.0.113.9 port 2001 ssh2\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	offset, err := readLogFile(context.Background(), path, 4096, func(*LoginEvent, int64) { count++ }, false, 0, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 || offset != int64(len(data)) {
+		t.Fatalf("expected file to be read from start, count=%d offset=%d", count, offset)
+	}
+}
@@ -0,0 +1,162 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	defaultTelegramAPIBase = "https://api.telegram.org"
+	// sendMessage 单条消息的最大长度（字符）；单个字段（如 sudo 命令）最多占用其中一部分，为原始日志留出空间
+	telegramMaxMessageLen = 4096
+	telegramMaxFieldLen   = 1024
+)
+
+// TelegramNotifier 通过 Telegram Bot API 发送通知
+type TelegramNotifier struct {
+	token      string
+	chatID     string
+	threadID   int
+	apiBase    string
+	httpClient *http.Client
+}
+
+// NewTelegramNotifier 创建 Telegram 通知器
+func NewTelegramNotifier(cfg *TelegramConfig) *TelegramNotifier {
+	apiBase := strings.TrimRight(cfg.APIBase, "/")
+	if apiBase == "" {
+		apiBase = defaultTelegramAPIBase
+	}
+	return &TelegramNotifier{
+		token:      cfg.Token,
+		chatID:     cfg.ChatID,
+		threadID:   cfg.ThreadID,
+		apiBase:    apiBase,
+		httpClient: newChannelHTTPClient(),
+	}
+}
+
+// Send 调用 sendMessage 发送 MarkdownV2 格式的消息
+func (t *TelegramNotifier) Send(event LoginEvent) error {
+	payload := map[string]any{
+		"chat_id":                  t.chatID,
+		"text":                     formatTelegramMessage(event),
+		"parse_mode":               "MarkdownV2",
+		"disable_web_page_preview": true,
+	}
+	if t.threadID > 0 {
+		payload["message_thread_id"] = t.threadID
+	}
+
+	// 限流时 Bot API 在 parameters.retry_after 中给出等待秒数
+	_, err := postJSONWithBackoff(t.httpClient, t.apiBase+"/bot"+t.token+"/sendMessage", payload, nil)
+	if err != nil {
+		var statusErr *httpStatusError
+		if errors.As(err, &statusErr) {
+			// Bot API 在 description 中给出失败原因
+			var resp struct {
+				Description string `json:"description"`
+			}
+			if json.Unmarshal([]byte(statusErr.Body), &resp) == nil && resp.Description != "" {
+				return fmt.Errorf("telegram 返回错误（状态码 %d）: %s", statusErr.StatusCode, resp.Description)
+			}
+		}
+		// 请求 URL 中包含 bot token，避免写入日志
+		return errors.New(strings.ReplaceAll(fmt.Sprintf("发送 telegram 消息失败: %v", err), t.token, "***"))
+	}
+	return nil
+}
+
+// Test 测试 Telegram 配置
+func (t *TelegramNotifier) Test() error {
+	if err := t.Send(channelTestEvent()); err != nil {
+		return fmt.Errorf("telegram 测试失败: %w", err)
+	}
+	return nil
+}
+
+// formatTelegramMessage 生成 MarkdownV2 消息：标签加粗，原始日志放入代码块。
+// 超过单条消息长度时截断字段值与代码块内容，保证标记完整闭合
+func formatTelegramMessage(event LoginEvent) string {
+	var b strings.Builder
+	b.WriteString("*服务器登录提醒*")
+	remaining := telegramMaxMessageLen - utf8.RuneCountInString(b.String())
+	for _, f := range eventFields(event) {
+		prefix := "\n*" + escapeMarkdownV2(f.Label) + ":* "
+		budget := remaining - utf8.RuneCountInString(prefix)
+		if budget <= 0 {
+			break
+		}
+		value := truncateMarkdownV2(escapeMarkdownV2(f.Value), min(budget, telegramMaxFieldLen))
+		b.WriteString(prefix + value)
+		remaining = budget - utf8.RuneCountInString(value)
+	}
+	if event.Message != "" {
+		if budget := remaining - utf8.RuneCountInString("\n```\n\n```"); budget > 0 {
+			b.WriteString("\n```\n" + truncateMarkdownV2(escapeMarkdownV2Code(event.Message), budget) + "\n```")
+		}
+	}
+	return b.String()
+}
+
+// truncateMarkdownV2 将已转义的文本截断到 max 个字符，不拆开转义序列，截断时以省略号结尾
+func truncateMarkdownV2(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)[:max-1]
+	// 末尾连续的反斜杠为奇数个时，最后一个是被截断的转义符
+	n := 0
+	for i := len(runes) - 1; i >= 0 && runes[i] == '\\'; i-- {
+		n++
+	}
+	if n%2 == 1 {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// escapeMarkdownV2 转义 MarkdownV2 普通文本中的保留字符
+func escapeMarkdownV2(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("_*[]()~`>#+-=|{}.!\\", r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// escapeMarkdownV2Code 转义代码块内容，只需处理 ` 与 \
+func escapeMarkdownV2Code(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "`", "\\`").Replace(s)
+}
+
+// TelegramInput 配置 Telegram 渠道的参数
+type TelegramInput struct {
+	Name     string
+	Token    string
+	ChatID   string
+	ThreadID int
+	APIBase  string
+}
+
+// configureTelegram 测试并保存 Telegram 渠道
+func configureTelegram(input TelegramInput) error {
+	return configureChannel(ChannelConfig{
+		Name:    input.Name,
+		Enabled: true,
+		Type:    "telegram",
+		Telegram: &TelegramConfig{
+			Token:    input.Token,
+			ChatID:   input.ChatID,
+			ThreadID: input.ThreadID,
+			APIBase:  input.APIBase,
+		},
+	})
+}
@@ -0,0 +1,141 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEscapeMarkdownV2(t *testing.T) {
+	got := escapeMarkdownV2("deploy_user (1.2.3.4) [x]!")
+	want := `deploy\_user \(1\.2\.3\.4\) \[x\]\!`
+	if got != want {
+		t.Fatalf("escapeMarkdownV2 = %q, want %q", got, want)
+	}
+	if got := escapeMarkdownV2Code("a`b\\c"); got != "a\\`b\\\\c" {
+		t.Fatalf("escapeMarkdownV2Code = %q", got)
+	}
+}
+
+func TestTelegramNotifierSend(t *testing.T) {
+	var got map[string]any
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	n := NewTelegramNotifier(&TelegramConfig{Token: "123:abc", ChatID: "-100", ThreadID: 7, APIBase: server.URL + "/"})
+	event := LoginEvent{
+		Type:      EventLoginSuccess,
+		User:      "deploy_user",
+		IP:        "203.0.113.9",
+		Port:      40022,
+		Timestamp: time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC),
+		Hostname:  "web-1",
+		Message:   "Accepted publickey for deploy_user",
+	}
+	if err := n.Send(event); err != nil {
+		t.Fatal(err)
+	}
+
+	if path != "/bot123:abc/sendMessage" {
+		t.Fatalf("unexpected path %s", path)
+	}
+	if got["chat_id"] != "-100" || got["message_thread_id"] != float64(7) || got["parse_mode"] != "MarkdownV2" {
+		t.Fatalf("unexpected payload: %v", got)
+	}
+	text, _ := got["text"].(string)
+	for _, want := range []string{`*用户:* deploy\_user`, `*来源IP:* 203\.0\.113\.9`, `*服务器:* web\-1`, "```\nAccepted publickey for deploy_user\n```"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("text missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestTelegramNotifierError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`))
+	}))
+	defer server.Close()
+
+	err := NewTelegramNotifier(&TelegramConfig{Token: "123:abc", ChatID: "1", APIBase: server.URL}).Send(LoginEvent{Type: EventLoginFailed})
+	if err == nil || !strings.Contains(err.Error(), "chat not found") {
+		t.Fatalf("expected description in error, got %v", err)
+	}
+
+	// 连接失败时不能在错误中暴露 token
+	err = NewTelegramNotifier(&TelegramConfig{Token: "123:secret", ChatID: "1", APIBase: "http://127.0.0.1:1"}).Send(LoginEvent{Type: EventLoginFailed})
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Fatalf("expected redacted error, got %v", err)
+	}
+}
+
+func TestValidateTelegramChannel(t *testing.T) {
+	ch := &ChannelConfig{Type: "telegram", Telegram: &TelegramConfig{Token: "123:abc", ChatID: "-100"}}
+	if err := ValidateChannelConfig(ch); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ch.Telegram.APIBase = "ftp://example.com"
+	if err := ValidateChannelConfig(ch); err == nil {
+		t.Fatalf("expected invalid api_base")
+	}
+	if err := ValidateChannelConfig(&ChannelConfig{Type: "telegram", Telegram: &TelegramConfig{Token: "123:abc"}}); err == nil {
+		t.Fatalf("expected missing chat_id error")
+	}
+}
+
+func TestTelegramNotifierRetryAfter(t *testing.T) {
+	waits := stubChannelSleep(t)
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 3","parameters":{"retry_after":3}}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	if err := NewTelegramNotifier(&TelegramConfig{Token: "123:abc", ChatID: "1", APIBase: server.URL}).Send(LoginEvent{Type: EventLoginFailed}); err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 2 || len(*waits) != 1 || (*waits)[0] != 3*time.Second {
+		t.Fatalf("retry_after not honoured: attempts=%d waits=%v", attempts, *waits)
+	}
+}
+
+func TestFormatTelegramMessageTruncates(t *testing.T) {
+	event := LoginEvent{
+		Type:    EventSudoCommand,
+		User:    "alice",
+		Command: strings.Repeat("a.", 3000),
+		Message: strings.Repeat("`x", 3000),
+	}
+	text := formatTelegramMessage(event)
+	if n := utf8.RuneCountInString(text); n > telegramMaxMessageLen {
+		t.Fatalf("message length %d exceeds limit", n)
+	}
+	if !strings.Contains(text, "*用户:* alice") || !strings.Contains(text, "…") || !strings.HasSuffix(text, "\n```") {
+		t.Fatalf("unexpected truncated message:\n%s", text)
+	}
+
+	// 截断不能留下孤立的转义符
+	if got := truncateMarkdownV2(`ab\.cd`, 4); got != "ab…" {
+		t.Fatalf("truncateMarkdownV2 = %q", got)
+	}
+	if got := truncateMarkdownV2("short", 10); got != "short" {
+		t.Fatalf("truncateMarkdownV2 = %q", got)
+	}
+}
@@ -1,6 +1,11 @@
 package notify
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
 
 // NotifyType 定义通知类型
 type NotifyType int
@@ -11,23 +16,134 @@ const (
 )
 
 const (
-	EventLoginSuccess = "login_success"
-	EventLoginFailed  = "login_failed"
+	EventLoginSuccess          = "login_success"
+	EventLoginFailed           = "login_failed"
+	EventHoneypotAttempt       = "honeypot_attempt"        // 蜜罐捕获的登录尝试
+	EventScanDetected          = "scan_detected"           // 同一来源的扫描行为聚合
+	EventScanProbe             = "scan_probe"              // 扫描探测日志（仅参与聚合，不单独通知）
+	EventSessionOpened         = "session_opened"          // 会话打开（仅用于会话跟踪，不单独通知）
+	EventSessionClosed         = "session_closed"          // 会话结束，携带会话时长
+	EventSudoCommand           = "sudo_command"            // sudo 执行命令
+	EventSudoFailed            = "sudo_failed"             // sudo 被拒绝（密码错误、不在 sudoers 中）
+	EventSuSession             = "su_session"              // su 切换用户成功
+	EventSuFailed              = "su_failed"               // su 认证失败
+	EventAccountChanged        = "account_changed"         // 账户、组、密码或 sudoers 变更
+	EventAuthorizedKeysChanged = "authorized_keys_changed" // 用户 authorized_keys 变更
+	EventSSHDConfigChanged     = "sshd_config_changed"     // sshd 配置变更
+)
+
+// login_failed 的细分类型，记录在 LoginEvent.Subtype 中
+const (
+	SubtypeInvalidUser        = "invalid_user"         // 用户不存在
+	SubtypeAuthFailed         = "auth_failed"          // 已存在用户认证失败
+	SubtypePreauthDisconnect  = "preauth_disconnect"   // 认证完成前断开
+	SubtypeMaxAuthExceeded    = "max_auth_exceeded"    // 超过 MaxAuthTries
+	SubtypeKexFailed          = "kex_failed"           // 密钥交换协商失败
+	SubtypeTooManyConnections = "too_many_connections" // 超过 MaxStartups 被丢弃
+)
+
+// 事件级别，由低到高
+const (
+	SeverityInfo     = "info"
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
 )
 
+// isNotifiableEventType 判断是否为可通知的事件类型或细分类型（可用于 --notify-on）
+func isNotifiableEventType(eventType string) bool {
+	switch eventType {
+	case EventLoginSuccess, EventLoginFailed, EventHoneypotAttempt, EventScanDetected, EventSessionClosed,
+		EventSudoCommand, EventSudoFailed, EventSuSession, EventSuFailed,
+		EventAccountChanged, EventAuthorizedKeysChanged, EventSSHDConfigChanged,
+		SubtypeInvalidUser, SubtypeAuthFailed, SubtypePreauthDisconnect, SubtypeMaxAuthExceeded,
+		SubtypeKexFailed, SubtypeTooManyConnections:
+		return true
+	default:
+		return false
+	}
+}
+
+// isFailureEvent 判断是否为失败类事件（参与失败去重与限流）
+func isFailureEvent(eventType string) bool {
+	switch eventType {
+	case EventLoginFailed, EventHoneypotAttempt, EventScanDetected, EventSudoFailed, EventSuFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+// eventSeverity 按事件类型与细分类型给出默认级别，root 登录成功时结合常用国家判断是否升级
+func eventSeverity(event *LoginEvent, policy severityPolicy) string {
+	if event.Action != "" {
+		// 已触发自动处置
+		return SeverityCritical
+	}
+	switch event.Type {
+	case EventLoginFailed:
+		switch event.Subtype {
+		case SubtypePreauthDisconnect, SubtypeKexFailed:
+			return SeverityInfo
+		case SubtypeMaxAuthExceeded:
+			return SeverityMedium
+		case SubtypeTooManyConnections:
+			return SeverityHigh
+		default:
+			return SeverityLow
+		}
+	case EventLoginSuccess:
+		if event.User == "root" {
+			// root 直接登录成功，来源不在常用国家时按最高级别处理
+			if policy.abroad(event) {
+				return SeverityCritical
+			}
+			return SeverityHigh
+		}
+		return SeverityMedium
+	case EventSessionClosed:
+		return SeverityInfo
+	case EventHoneypotAttempt:
+		return SeverityLow
+	case EventSudoFailed, EventSuFailed, EventAccountChanged, EventSSHDConfigChanged:
+		return SeverityHigh
+	case EventAuthorizedKeysChanged:
+		return SeverityCritical
+	default:
+		return SeverityMedium
+	}
+}
+
+// displayType 返回用于展示的事件类型，有细分类型时为 "login_failed/invalid_user"
+func (e *LoginEvent) displayType() string {
+	if e.Subtype == "" {
+		return e.Type
+	}
+	return e.Type + "/" + e.Subtype
+}
+
 // LoginEvent 定义登录事件
 type LoginEvent struct {
-	Type      string    // 事件类型：login_success 或 login_failed
-	User      string    // 登录用户
-	IP        string    // 来源IP
-	Method    string    // 认证方式 password/publickey/keyboard-interactive
-	Port      int       // 来源端口
-	Timestamp time.Time // 事件时间
-	Hostname  string    // 主机名
-	Location  string    // IP地理位置（可选）
-	LogPath   string    // 日志来源路径（文件路径或 journald 单元）
-	Message   string    // 原始日志消息
-	HostIP    string    // 当前主机 IP（优先 IPv4）
+	Type       string        // 事件类型：login_success/login_failed/honeypot_attempt
+	Subtype    string        // 细分类型：invalid_user/auth_failed/preauth_disconnect 等（可选）
+	Severity   string        // 事件级别：info/low/medium/high/critical
+	User       string        // 登录用户
+	IP         string        // 来源IP
+	Method     string        // 认证方式 password/publickey/keyboard-interactive
+	Port       int           // 来源端口
+	Timestamp  time.Time     // 事件时间
+	Hostname   string        // 主机名
+	Location   string        // IP地理位置（可选）
+	LogPath    string        // 日志来源路径（文件路径或 journald 单元）
+	Message    string        // 原始日志消息
+	HostIP     string        // 当前主机 IP（优先 IPv4）
+	Action     string        // 自动处置结果（可选）
+	PID        int           // sshd 进程号（可选，用于关联会话）
+	Duration   time.Duration // 会话时长（session_closed）
+	TargetUser string        // 提权目标用户（sudo/su）
+	Command    string        // sudo 执行的命令
+	Verified   bool          // 日志来自 journald 记录的 uid 0 sshd 进程（_COMM/_UID 由 journald 填写，无法伪造）
 }
 
 // Notifier 定义通知接口
@@ -41,17 +157,118 @@ type Notifier interface {
 // Config 通知配置
 type Config struct {
 	Channels []ChannelConfig `json:"channels" yaml:"channels"`
+	IPLookup *IPLookupConfig `json:"ip_lookup,omitempty" yaml:"ip_lookup,omitempty"`
+	Response *ResponseConfig `json:"response,omitempty" yaml:"response,omitempty"`
+	Severity *SeverityConfig `json:"severity,omitempty" yaml:"severity,omitempty"`
+}
+
+// SeverityConfig 事件级别配置
+type SeverityConfig struct {
+	HomeCountries []string `json:"home_countries,omitempty" yaml:"home_countries,omitempty"` // 常用登录国家代码（ISO 3166-1），root 从其他国家登录成功时升级为 critical
+}
+
+// ResponseConfig 可疑登录的自动处置配置
+type ResponseConfig struct {
+	Enabled       bool     `json:"enabled" yaml:"enabled"`
+	DenyCountries []string `json:"deny_countries,omitempty" yaml:"deny_countries,omitempty"` // 禁止登录的国家代码（ISO 3166-1，如 CN、RU）
+	AllowedKeys   []string `json:"allowed_keys,omitempty" yaml:"allowed_keys,omitempty"`     // 允许的公钥指纹（SHA256:...），为空表示不检查
+	LockAccount   bool     `json:"lock_account" yaml:"lock_account"`                         // 终止会话后同时锁定账户
+}
+
+// IPLookupConfig IP 地理位置查询配置
+type IPLookupConfig struct {
+	Mode   string `json:"mode,omitempty" yaml:"mode,omitempty"`       // 查询链：local/local-first/remote
+	CityDB string `json:"city_db,omitempty" yaml:"city_db,omitempty"` // GeoLite2-City / DB-IP City Lite mmdb 路径
+	ASNDB  string `json:"asn_db,omitempty" yaml:"asn_db,omitempty"`   // GeoLite2-ASN / DB-IP ASN Lite mmdb 路径
+	Reload bool   `json:"reload" yaml:"reload"`                       // mmdb 文件变更后自动重新加载
+}
+
+// effectiveMode 返回实际生效的查询链，未指定时有本地库则本地优先
+func (c *IPLookupConfig) effectiveMode() string {
+	mode := strings.ToLower(strings.TrimSpace(c.Mode))
+	if mode != "" {
+		return mode
+	}
+	if c.CityDB != "" || c.ASNDB != "" {
+		return ipLookupModeLocalFirst
+	}
+	return ipLookupModeRemote
 }
 
 // ChannelConfig 单个通知渠道配置
 type ChannelConfig struct {
 	Name    string `json:"name,omitempty" yaml:"name,omitempty"` // 渠道名称（可选，用于显示）
 	Enabled bool   `json:"enabled" yaml:"enabled"`               // 是否启用
-	Type    string `json:"type" yaml:"type"`                     // 类型：curl/email
+	Type    string `json:"type" yaml:"type"`                     // 类型：curl/email/telegram/lark/dingtalk/wecom/slack/discord/teams/ntfy/gotify/pushover/bark/syslog/file/exec/mqtt/matrix/webhook
 
 	// 不同类型的配置，根据 Type 使用对应字段
-	Curl  *CurlConfig  `json:"curl,omitempty" yaml:"curl,omitempty"`
-	Email *EmailConfig `json:"email,omitempty" yaml:"email,omitempty"`
+	Curl     *CurlConfig          `json:"curl,omitempty" yaml:"curl,omitempty"`
+	Email    *EmailConfig         `json:"email,omitempty" yaml:"email,omitempty"`
+	Telegram *TelegramConfig      `json:"telegram,omitempty" yaml:"telegram,omitempty"`
+	Lark     *RobotConfig         `json:"lark,omitempty" yaml:"lark,omitempty"`
+	DingTalk *RobotConfig         `json:"dingtalk,omitempty" yaml:"dingtalk,omitempty"`
+	WeCom    *RobotConfig         `json:"wecom,omitempty" yaml:"wecom,omitempty"`
+	Slack    *ChatWebhookConfig   `json:"slack,omitempty" yaml:"slack,omitempty"`
+	Discord  *ChatWebhookConfig   `json:"discord,omitempty" yaml:"discord,omitempty"`
+	Teams    *ChatWebhookConfig   `json:"teams,omitempty" yaml:"teams,omitempty"`
+	Ntfy     *NtfyConfig          `json:"ntfy,omitempty" yaml:"ntfy,omitempty"`
+	Gotify   *GotifyConfig        `json:"gotify,omitempty" yaml:"gotify,omitempty"`
+	Pushover *PushoverConfig      `json:"pushover,omitempty" yaml:"pushover,omitempty"`
+	Bark     *BarkConfig          `json:"bark,omitempty" yaml:"bark,omitempty"`
+	Syslog   *SyslogConfig        `json:"syslog,omitempty" yaml:"syslog,omitempty"`
+	File     *FileSinkConfig      `json:"file,omitempty" yaml:"file,omitempty"`
+	Exec     *ExecConfig          `json:"exec,omitempty" yaml:"exec,omitempty"`
+	MQTT     *MQTTConfig          `json:"mqtt,omitempty" yaml:"mqtt,omitempty"`
+	Matrix   *MatrixConfig        `json:"matrix,omitempty" yaml:"matrix,omitempty"`
+	Webhook  *SignedWebhookConfig `json:"webhook,omitempty" yaml:"webhook,omitempty"`
+}
+
+// robot 返回群机器人类型对应的配置
+func (c *ChannelConfig) robot(kind string) *RobotConfig {
+	switch kind {
+	case robotLark:
+		return c.Lark
+	case robotDingTalk:
+		return c.DingTalk
+	case robotWeCom:
+		return c.WeCom
+	}
+	return nil
+}
+
+func (c *ChannelConfig) setRobot(kind string, cfg *RobotConfig) {
+	switch kind {
+	case robotLark:
+		c.Lark = cfg
+	case robotDingTalk:
+		c.DingTalk = cfg
+	case robotWeCom:
+		c.WeCom = cfg
+	}
+}
+
+// chatWebhook 返回聊天平台类型对应的配置
+func (c *ChannelConfig) chatWebhook(kind string) *ChatWebhookConfig {
+	switch kind {
+	case chatSlack:
+		return c.Slack
+	case chatDiscord:
+		return c.Discord
+	case chatTeams:
+		return c.Teams
+	}
+	return nil
+}
+
+func (c *ChannelConfig) setChatWebhook(kind string, cfg *ChatWebhookConfig) {
+	switch kind {
+	case chatSlack:
+		c.Slack = cfg
+	case chatDiscord:
+		c.Discord = cfg
+	case chatTeams:
+		c.Teams = cfg
+	}
 }
 
 // CurlConfig 自定义 Curl 通知配置
@@ -61,13 +278,151 @@ type CurlConfig struct {
 }
 
 // EmailConfig 邮件通知配置
+// Subject/Body/HTML 为可选模板，变量与 curl 相同；Body 与 Subject 使用 text/template，HTML 使用 html/template（自动转义）。
+// 未设置时使用内置的主题与纯文本正文；设置 HTML 后以 multipart/alternative 同时发送纯文本与 HTML
 type EmailConfig struct {
-	To     string `json:"to" yaml:"to"`
-	From   string `json:"from" yaml:"from"`
-	Server string `json:"server" yaml:"server"`
-	Port   int    `json:"port" yaml:"port"`
-	User   string `json:"user" yaml:"user"`
-	Pass   string `json:"pass" yaml:"pass"`
+	To      AddressList `json:"to" yaml:"to"`
+	Cc      AddressList `json:"cc,omitempty" yaml:"cc,omitempty"`
+	Bcc     AddressList `json:"bcc,omitempty" yaml:"bcc,omitempty"` // 仅用于投递，不写入邮件头
+	From    string      `json:"from" yaml:"from"`
+	Server  string      `json:"server" yaml:"server"`
+	Port    int         `json:"port" yaml:"port"`
+	User    string      `json:"user" yaml:"user"`
+	Pass    string      `json:"pass" yaml:"pass"`
+	Subject string      `json:"subject,omitempty" yaml:"subject,omitempty"`
+	Body    string      `json:"body,omitempty" yaml:"body,omitempty"`
+	HTML    string      `json:"html,omitempty" yaml:"html,omitempty"`
+}
+
+// AddressList 邮件地址列表，配置文件中可写为数组或逗号分隔的字符串（兼容旧版的单个 to 地址）
+type AddressList []string
+
+// UnmarshalJSON 同时接受 "a@x, b@y" 与 ["a@x", "b@y"]；显示名中含逗号的地址需使用数组形式
+func (l *AddressList) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*l = splitAddressList(s)
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("address list must be a string or an array of strings")
+	}
+	*l = AddressList(list)
+	return nil
+}
+
+func splitAddressList(s string) AddressList {
+	var list AddressList
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			list = append(list, part)
+		}
+	}
+	return list
+}
+
+// TelegramConfig Telegram Bot 通知配置
+type TelegramConfig struct {
+	Token    string `json:"token" yaml:"token"`                             // Bot token（@BotFather 生成）
+	ChatID   string `json:"chat_id" yaml:"chat_id"`                         // 会话 ID 或 @频道名
+	ThreadID int    `json:"thread_id,omitempty" yaml:"thread_id,omitempty"` // 论坛群组的话题 ID（可选）
+	APIBase  string `json:"api_base,omitempty" yaml:"api_base,omitempty"`   // Bot API 地址，默认 https://api.telegram.org
+}
+
+// RobotConfig 飞书、钉钉、企业微信群机器人配置
+type RobotConfig struct {
+	Webhook string `json:"webhook" yaml:"webhook"`                   // 机器人 webhook 地址
+	Secret  string `json:"secret,omitempty" yaml:"secret,omitempty"` // 签名校验密钥（飞书、钉钉开启"加签"时需要，企业微信不支持）
+}
+
+// ChatWebhookConfig Slack、Discord、Microsoft Teams incoming webhook 配置
+// Template 为可选的完整 JSON 消息模板，变量与 curl 相同，{{json .User}} 输出转义后的字符串
+type ChatWebhookConfig struct {
+	Webhook  string `json:"webhook" yaml:"webhook"`
+	Template string `json:"template,omitempty" yaml:"template,omitempty"`
+}
+
+// NtfyConfig ntfy 推送配置
+type NtfyConfig struct {
+	Server   string   `json:"server,omitempty" yaml:"server,omitempty"`     // 服务地址，默认 https://ntfy.sh
+	Topic    string   `json:"topic" yaml:"topic"`                           // 主题名
+	Token    string   `json:"token,omitempty" yaml:"token,omitempty"`       // 访问令牌（tk_...），与用户名密码二选一
+	Username string   `json:"username,omitempty" yaml:"username,omitempty"` // 用户名（可选）
+	Password string   `json:"password,omitempty" yaml:"password,omitempty"` // 密码（可选）
+	Tags     []string `json:"tags,omitempty" yaml:"tags,omitempty"`         // 附加标签
+}
+
+// GotifyConfig Gotify 推送配置
+type GotifyConfig struct {
+	Server string `json:"server" yaml:"server"` // 服务地址
+	Token  string `json:"token" yaml:"token"`   // 应用 token
+}
+
+// PushoverConfig Pushover 推送配置
+type PushoverConfig struct {
+	UserKey  string `json:"user_key" yaml:"user_key"`                 // 用户或群组 key
+	AppToken string `json:"app_token" yaml:"app_token"`               // 应用 API token
+	Device   string `json:"device,omitempty" yaml:"device,omitempty"` // 只推送到指定设备（可选）
+	Retry    int    `json:"retry,omitempty" yaml:"retry,omitempty"`   // 紧急级别的重发间隔（秒，>= 30，默认 60）
+	Expire   int    `json:"expire,omitempty" yaml:"expire,omitempty"` // 紧急级别的重发持续时间（秒，<= 10800，默认 3600）
+}
+
+// BarkConfig Bark 推送配置
+type BarkConfig struct {
+	Server    string `json:"server,omitempty" yaml:"server,omitempty"` // 服务地址，默认 https://api.day.app
+	DeviceKey string `json:"device_key" yaml:"device_key"`             // 设备 key
+	Group     string `json:"group,omitempty" yaml:"group,omitempty"`   // 通知分组，默认 SSHield
+	Sound     string `json:"sound,omitempty" yaml:"sound,omitempty"`   // 铃声（可选）
+}
+
+// SyslogConfig 本地或远程 syslog 配置，消息为 RFC 5424 格式
+type SyslogConfig struct {
+	Network  string `json:"network,omitempty" yaml:"network,omitempty"`   // unix/udp/tcp，默认 unix
+	Address  string `json:"address,omitempty" yaml:"address,omitempty"`   // 套接字路径或 host:port，unix 默认 /dev/log
+	Facility string `json:"facility,omitempty" yaml:"facility,omitempty"` // facility 名称，默认 auth
+	Severity string `json:"severity,omitempty" yaml:"severity,omitempty"` // 固定 severity（可选），默认按事件级别映射
+}
+
+// FileSinkConfig JSON Lines 文件配置
+type FileSinkConfig struct {
+	Path       string `json:"path" yaml:"path"`                                   // 文件路径
+	MaxSizeMB  int    `json:"max_size_mb,omitempty" yaml:"max_size_mb,omitempty"` // 单个文件上限（MB），默认 100
+	MaxBackups int    `json:"max_backups,omitempty" yaml:"max_backups,omitempty"` // 保留的轮转文件数，默认 5
+}
+
+// ExecConfig 外部命令配置，命令直接执行，不经过 shell
+type ExecConfig struct {
+	Command       string   `json:"command" yaml:"command"`                                   // 可执行文件的绝对路径
+	Args          []string `json:"args,omitempty" yaml:"args,omitempty"`                     // 命令参数
+	Timeout       int      `json:"timeout,omitempty" yaml:"timeout,omitempty"`               // 超时秒数，默认 30
+	MaxConcurrent int      `json:"max_concurrent,omitempty" yaml:"max_concurrent,omitempty"` // 同时运行的最大实例数，默认 4
+}
+
+// MQTTConfig MQTT 发布配置
+type MQTTConfig struct {
+	Broker             string `json:"broker" yaml:"broker"`                                                 // broker 地址，tcp://host:1883 或 ssl://host:8883
+	Topic              string `json:"topic,omitempty" yaml:"topic,omitempty"`                               // 主题模板，默认 sshield/{{.Hostname}}/{{.Type}}
+	QoS                int    `json:"qos,omitempty" yaml:"qos,omitempty"`                                   // 0 或 1
+	Retain             bool   `json:"retain,omitempty" yaml:"retain,omitempty"`                             // 保留消息，订阅者连接时即可收到每个主题的最后一条
+	Username           string `json:"username,omitempty" yaml:"username,omitempty"`                         // 用户名（可选）
+	Password           string `json:"password,omitempty" yaml:"password,omitempty"`                         // 密码（可选）
+	ClientID           string `json:"client_id,omitempty" yaml:"client_id,omitempty"`                       // 客户端 ID 前缀，每次连接追加随机后缀，默认 sshield-<主机名>
+	CAFile             string `json:"ca_file,omitempty" yaml:"ca_file,omitempty"`                           // TLS 自定义 CA 证书（可选）
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty" yaml:"insecure_skip_verify,omitempty"` // 跳过 TLS 证书校验
+}
+
+// MatrixConfig Matrix 房间通知配置
+type MatrixConfig struct {
+	Homeserver  string `json:"homeserver" yaml:"homeserver"`     // homeserver 地址，如 https://matrix.example.com
+	AccessToken string `json:"access_token" yaml:"access_token"` // 机器人账号的 access token
+	RoomID      string `json:"room_id" yaml:"room_id"`           // 房间 ID（!xxx:example.com），账号需已加入
+}
+
+// SignedWebhookConfig 签名 webhook 配置，消息格式见 webhookPayload
+type SignedWebhookConfig struct {
+	URL    string `json:"url" yaml:"url"`       // 接收地址
+	Secret string `json:"secret" yaml:"secret"` // HMAC-SHA256 共享密钥
 }
 
 // GetEnabledChannels 获取所有启用的渠道配置
@@ -2,7 +2,10 @@ package notify
 
 import (
 	"fmt"
+	"net"
 	"net/mail"
+	"net/url"
+	"path/filepath"
 	"strings"
 )
 
@@ -23,6 +26,84 @@ func ValidateConfig(cfg *Config) error {
 		}
 	}
 
+	if cfg.IPLookup != nil {
+		if err := ValidateIPLookupConfig(cfg.IPLookup); err != nil {
+			return fmt.Errorf("ip_lookup: %w", err)
+		}
+	}
+
+	if cfg.Response != nil {
+		if err := ValidateResponseConfig(cfg.Response); err != nil {
+			return fmt.Errorf("response: %w", err)
+		}
+	}
+
+	if cfg.Severity != nil {
+		if err := ValidateSeverityConfig(cfg.Severity); err != nil {
+			return fmt.Errorf("severity: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ValidateIPLookupConfig 验证 IP 查询配置
+func ValidateIPLookupConfig(c *IPLookupConfig) error {
+	validationErr := &ValidationError{}
+
+	switch c.effectiveMode() {
+	case ipLookupModeRemote:
+	case ipLookupModeLocal, ipLookupModeLocalFirst:
+		if c.CityDB == "" && c.ASNDB == "" {
+			validationErr.AddError("ip_lookup.city_db", "at least one mmdb path is required for local lookup")
+		}
+	default:
+		validationErr.AddError("ip_lookup.mode", "unsupported mode: "+c.Mode+" (local/local-first/remote)")
+	}
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+	return nil
+}
+
+// ValidateResponseConfig 验证自动处置配置
+func ValidateResponseConfig(c *ResponseConfig) error {
+	validationErr := &ValidationError{}
+
+	for _, code := range c.DenyCountries {
+		if len(strings.TrimSpace(code)) != 2 {
+			validationErr.AddError("response.deny_countries", "invalid ISO 3166-1 country code: "+code)
+		}
+	}
+	for _, key := range c.AllowedKeys {
+		if !strings.HasPrefix(strings.TrimSpace(key), "SHA256:") {
+			validationErr.AddError("response.allowed_keys", "fingerprint must start with SHA256: "+key)
+		}
+	}
+	if c.Enabled && len(c.DenyCountries) == 0 && len(c.AllowedKeys) == 0 {
+		validationErr.AddError("response", "at least one of deny_countries or allowed_keys is required")
+	}
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
+	return nil
+}
+
+// ValidateSeverityConfig 验证事件级别配置
+func ValidateSeverityConfig(c *SeverityConfig) error {
+	validationErr := &ValidationError{}
+
+	for _, code := range c.HomeCountries {
+		if len(strings.TrimSpace(code)) != 2 {
+			validationErr.AddError("severity.home_countries", "invalid ISO 3166-1 country code: "+code)
+		}
+	}
+
+	if validationErr.HasErrors() {
+		return validationErr
+	}
 	return nil
 }
 
@@ -39,6 +120,32 @@ func ValidateChannelConfig(ch *ChannelConfig) error {
 		validateCurlChannel(ch, validationErr)
 	case "email":
 		validateEmailChannel(ch, validationErr)
+	case "telegram":
+		validateTelegramChannel(ch, validationErr)
+	case robotLark, robotDingTalk, robotWeCom:
+		validateRobotChannel(ch, validationErr)
+	case chatSlack, chatDiscord, chatTeams:
+		validateChatWebhookChannel(ch, validationErr)
+	case "ntfy":
+		validateNtfyChannel(ch, validationErr)
+	case "gotify":
+		validateGotifyChannel(ch, validationErr)
+	case "pushover":
+		validatePushoverChannel(ch, validationErr)
+	case "bark":
+		validateBarkChannel(ch, validationErr)
+	case "syslog":
+		validateSyslogChannel(ch, validationErr)
+	case "file":
+		validateFileSinkChannel(ch, validationErr)
+	case "exec":
+		validateExecChannel(ch, validationErr)
+	case "mqtt":
+		validateMQTTChannel(ch, validationErr)
+	case "matrix":
+		validateMatrixChannel(ch, validationErr)
+	case "webhook":
+		validateSignedWebhookChannel(ch, validationErr)
 	default:
 		validationErr.AddError("type", "unsupported notification type: "+ch.Type)
 	}
@@ -72,10 +179,18 @@ func validateEmailChannel(ch *ChannelConfig, validationErr *ValidationError) {
 	e := ch.Email
 
 	// 验证收件人邮箱
-	if e.To == "" {
+	if len(e.To) == 0 {
 		validationErr.AddError("email.to", "recipient email is required")
-	} else if _, err := mail.ParseAddress(e.To); err != nil {
-		validationErr.AddError("email.to", "invalid recipient email format")
+	}
+	for _, list := range []struct {
+		field string
+		addrs AddressList
+	}{{"email.to", e.To}, {"email.cc", e.Cc}, {"email.bcc", e.Bcc}} {
+		for _, addr := range list.addrs {
+			if _, err := mail.ParseAddress(addr); err != nil {
+				validationErr.AddError(list.field, fmt.Sprintf("invalid email address: %s", addr))
+			}
+		}
 	}
 
 	// 验证发件人邮箱
@@ -102,4 +217,309 @@ func validateEmailChannel(ch *ChannelConfig, validationErr *ValidationError) {
 	if e.Pass == "" {
 		validationErr.AddError("email.pass", "SMTP password is required")
 	}
+
+	// 验证模板
+	event := channelTestEvent()
+	if e.Subject != "" {
+		if _, err := renderEmailText(e.Subject, event); err != nil {
+			validationErr.AddError("email.subject", err.Error())
+		}
+	}
+	if e.Body != "" {
+		if _, err := renderEmailText(e.Body, event); err != nil {
+			validationErr.AddError("email.body", err.Error())
+		}
+	}
+	if e.HTML != "" {
+		if _, err := renderEmailHTML(e.HTML, event); err != nil {
+			validationErr.AddError("email.html", err.Error())
+		}
+	}
+}
+
+// validateTelegramChannel 验证 Telegram 渠道配置
+func validateTelegramChannel(ch *ChannelConfig, validationErr *ValidationError) {
+	if ch.Telegram == nil {
+		validationErr.AddError("telegram", "telegram config is required")
+		return
+	}
+
+	t := ch.Telegram
+	if strings.TrimSpace(t.Token) == "" {
+		validationErr.AddError("telegram.token", "bot token is required")
+	} else if strings.ContainsAny(t.Token, "/ ") {
+		validationErr.AddError("telegram.token", "invalid bot token format")
+	}
+	if strings.TrimSpace(t.ChatID) == "" {
+		validationErr.AddError("telegram.chat_id", "chat ID is required")
+	}
+	if t.ThreadID < 0 {
+		validationErr.AddError("telegram.thread_id", "thread ID must be positive")
+	}
+	if t.APIBase != "" {
+		if err := validateHTTPURL(t.APIBase); err != nil {
+			validationErr.AddError("telegram.api_base", "invalid API base URL: "+err.Error())
+		}
+	}
+}
+
+// validateRobotChannel 验证飞书、钉钉、企业微信群机器人配置
+func validateRobotChannel(ch *ChannelConfig, validationErr *ValidationError) {
+	kind := strings.ToLower(ch.Type)
+	r := ch.robot(kind)
+	if r == nil {
+		validationErr.AddError(kind, kind+" config is required")
+		return
+	}
+
+	if r.Webhook == "" {
+		validationErr.AddError(kind+".webhook", "webhook URL is required")
+	} else if err := validateHTTPURL(r.Webhook); err != nil {
+		validationErr.AddError(kind+".webhook", "invalid webhook URL: "+err.Error())
+	}
+	if kind == robotWeCom && r.Secret != "" {
+		validationErr.AddError(kind+".secret", "wecom robots do not support signatures")
+	}
+}
+
+// validateChatWebhookChannel 验证 Slack、Discord、Teams 渠道配置
+func validateChatWebhookChannel(ch *ChannelConfig, validationErr *ValidationError) {
+	kind := strings.ToLower(ch.Type)
+	c := ch.chatWebhook(kind)
+	if c == nil {
+		validationErr.AddError(kind, kind+" config is required")
+		return
+	}
+
+	if c.Webhook == "" {
+		validationErr.AddError(kind+".webhook", "webhook URL is required")
+	} else if err := validateHTTPURL(c.Webhook); err != nil {
+		validationErr.AddError(kind+".webhook", "invalid webhook URL: "+err.Error())
+	}
+	if c.Template != "" {
+		if _, err := renderJSONTemplate(c.Template, channelTestEvent()); err != nil {
+			validationErr.AddError(kind+".template", err.Error())
+		}
+	}
+}
+
+// validateNtfyChannel 验证 ntfy 渠道配置
+func validateNtfyChannel(ch *ChannelConfig, validationErr *ValidationError) {
+	if ch.Ntfy == nil {
+		validationErr.AddError("ntfy", "ntfy config is required")
+		return
+	}
+
+	n := ch.Ntfy
+	if n.Server != "" {
+		if err := validateHTTPURL(n.Server); err != nil {
+			validationErr.AddError("ntfy.server", "invalid server URL: "+err.Error())
+		}
+	}
+	if strings.TrimSpace(n.Topic) == "" {
+		validationErr.AddError("ntfy.topic", "topic is required")
+	} else if strings.ContainsAny(n.Topic, "/ ") {
+		validationErr.AddError("ntfy.topic", "invalid topic name")
+	}
+	if n.Token != "" && n.Username != "" {
+		validationErr.AddError("ntfy.token", "token and username cannot be used together")
+	}
+	if n.Password != "" && n.Username == "" {
+		validationErr.AddError("ntfy.username", "username is required when password is set")
+	}
+}
+
+// validateGotifyChannel 验证 Gotify 渠道配置
+func validateGotifyChannel(ch *ChannelConfig, validationErr *ValidationError) {
+	if ch.Gotify == nil {
+		validationErr.AddError("gotify", "gotify config is required")
+		return
+	}
+
+	if ch.Gotify.Server == "" {
+		validationErr.AddError("gotify.server", "server URL is required")
+	} else if err := validateHTTPURL(ch.Gotify.Server); err != nil {
+		validationErr.AddError("gotify.server", "invalid server URL: "+err.Error())
+	}
+	if strings.TrimSpace(ch.Gotify.Token) == "" {
+		validationErr.AddError("gotify.token", "application token is required")
+	}
+}
+
+// validatePushoverChannel 验证 Pushover 渠道配置
+func validatePushoverChannel(ch *ChannelConfig, validationErr *ValidationError) {
+	if ch.Pushover == nil {
+		validationErr.AddError("pushover", "pushover config is required")
+		return
+	}
+
+	p := ch.Pushover
+	if strings.TrimSpace(p.UserKey) == "" {
+		validationErr.AddError("pushover.user_key", "user key is required")
+	}
+	if strings.TrimSpace(p.AppToken) == "" {
+		validationErr.AddError("pushover.app_token", "application token is required")
+	}
+	if p.Retry != 0 && p.Retry < 30 {
+		validationErr.AddError("pushover.retry", "retry must be at least 30 seconds")
+	}
+	if p.Expire < 0 || p.Expire > 10800 {
+		validationErr.AddError("pushover.expire", "expire must be between 1 and 10800 seconds")
+	}
+}
+
+// validateBarkChannel 验证 Bark 渠道配置
+func validateBarkChannel(ch *ChannelConfig, validationErr *ValidationError) {
+	if ch.Bark == nil {
+		validationErr.AddError("bark", "bark config is required")
+		return
+	}
+
+	if ch.Bark.Server != "" {
+		if err := validateHTTPURL(ch.Bark.Server); err != nil {
+			validationErr.AddError("bark.server", "invalid server URL: "+err.Error())
+		}
+	}
+	if strings.TrimSpace(ch.Bark.DeviceKey) == "" {
+		validationErr.AddError("bark.device_key", "device key is required")
+	}
+}
+
+// validateSyslogChannel 验证 syslog 渠道配置
+func validateSyslogChannel(ch *ChannelConfig, validationErr *ValidationError) {
+	if ch.Syslog == nil {
+		validationErr.AddError("syslog", "syslog config is required")
+		return
+	}
+
+	s := ch.Syslog
+	switch strings.ToLower(s.Network) {
+	case "", "unix":
+	case "udp", "tcp":
+		if _, _, err := net.SplitHostPort(s.Address); err != nil {
+			validationErr.AddError("syslog.address", "address must be host:port for "+s.Network)
+		}
+	default:
+		validationErr.AddError("syslog.network", "network must be unix, udp or tcp")
+	}
+	if _, ok := syslogFacilities[strings.ToLower(s.Facility)]; s.Facility != "" && !ok {
+		validationErr.AddError("syslog.facility", "unknown facility: "+s.Facility)
+	}
+	if _, ok := syslogSeverities[strings.ToLower(s.Severity)]; s.Severity != "" && !ok {
+		validationErr.AddError("syslog.severity", "unknown severity: "+s.Severity)
+	}
+}
+
+// validateFileSinkChannel 验证文件渠道配置
+func validateFileSinkChannel(ch *ChannelConfig, validationErr *ValidationError) {
+	if ch.File == nil {
+		validationErr.AddError("file", "file config is required")
+		return
+	}
+
+	if ch.File.Path == "" {
+		validationErr.AddError("file.path", "file path is required")
+	} else if !filepath.IsAbs(ch.File.Path) {
+		validationErr.AddError("file.path", "file path must be absolute")
+	}
+	if ch.File.MaxSizeMB < 0 {
+		validationErr.AddError("file.max_size_mb", "max size must be positive")
+	}
+	if ch.File.MaxBackups < 0 {
+		validationErr.AddError("file.max_backups", "max backups must be positive")
+	}
+}
+
+// validateExecChannel 验证外部命令渠道配置
+func validateExecChannel(ch *ChannelConfig, validationErr *ValidationError) {
+	if ch.Exec == nil {
+		validationErr.AddError("exec", "exec config is required")
+		return
+	}
+
+	if ch.Exec.Command == "" {
+		validationErr.AddError("exec.command", "command is required")
+	} else if !filepath.IsAbs(ch.Exec.Command) {
+		validationErr.AddError("exec.command", "command must be an absolute path")
+	}
+	if ch.Exec.Timeout < 0 {
+		validationErr.AddError("exec.timeout", "timeout must be positive")
+	}
+	if ch.Exec.MaxConcurrent < 0 {
+		validationErr.AddError("exec.max_concurrent", "max concurrent must be positive")
+	}
+}
+
+// validateMQTTChannel 验证 MQTT 渠道配置
+func validateMQTTChannel(ch *ChannelConfig, validationErr *ValidationError) {
+	if ch.MQTT == nil {
+		validationErr.AddError("mqtt", "mqtt config is required")
+		return
+	}
+
+	m := ch.MQTT
+	if m.Broker == "" {
+		validationErr.AddError("mqtt.broker", "broker URL is required")
+	} else if u, err := url.Parse(m.Broker); err != nil || u.Hostname() == "" {
+		validationErr.AddError("mqtt.broker", "invalid broker URL")
+	} else {
+		switch u.Scheme {
+		case "tcp", "mqtt", "ssl", "tls", "mqtts":
+		default:
+			validationErr.AddError("mqtt.broker", "broker scheme must be tcp, mqtt, ssl, tls or mqtts")
+		}
+	}
+	if m.QoS != 0 && m.QoS != 1 {
+		validationErr.AddError("mqtt.qos", "qos must be 0 or 1")
+	}
+	if m.Password != "" && m.Username == "" {
+		validationErr.AddError("mqtt.username", "username is required when password is set")
+	}
+	if n, err := NewMQTTNotifier(m); err != nil {
+		validationErr.AddError("mqtt.topic", err.Error())
+	} else if _, err := n.renderTopic(channelTestEvent()); err != nil {
+		validationErr.AddError("mqtt.topic", err.Error())
+	}
+}
+
+// validateMatrixChannel 验证 Matrix 渠道配置
+func validateMatrixChannel(ch *ChannelConfig, validationErr *ValidationError) {
+	if ch.Matrix == nil {
+		validationErr.AddError("matrix", "matrix config is required")
+		return
+	}
+
+	m := ch.Matrix
+	if m.Homeserver == "" {
+		validationErr.AddError("matrix.homeserver", "homeserver URL is required")
+	} else if err := validateHTTPURL(m.Homeserver); err != nil {
+		validationErr.AddError("matrix.homeserver", "invalid homeserver URL: "+err.Error())
+	}
+	if strings.TrimSpace(m.AccessToken) == "" {
+		validationErr.AddError("matrix.access_token", "access token is required")
+	}
+	if m.RoomID == "" {
+		validationErr.AddError("matrix.room_id", "room ID is required")
+	} else if !strings.HasPrefix(m.RoomID, "!") || !strings.Contains(m.RoomID, ":") {
+		validationErr.AddError("matrix.room_id", "room ID must look like !opaque:server (aliases are not supported)")
+	}
+}
+
+// validateSignedWebhookChannel 验证签名 webhook 渠道配置
+func validateSignedWebhookChannel(ch *ChannelConfig, validationErr *ValidationError) {
+	if ch.Webhook == nil {
+		validationErr.AddError("webhook", "webhook config is required")
+		return
+	}
+
+	if ch.Webhook.URL == "" {
+		validationErr.AddError("webhook.url", "URL is required")
+	} else if err := validateHTTPURL(ch.Webhook.URL); err != nil {
+		validationErr.AddError("webhook.url", "invalid URL: "+err.Error())
+	}
+	if ch.Webhook.Secret == "" {
+		validationErr.AddError("webhook.secret", "signing secret is required")
+	} else if len(ch.Webhook.Secret) < 16 {
+		validationErr.AddError("webhook.secret", "signing secret must be at least 16 characters")
+	}
 }
@@ -13,6 +13,7 @@ import (
 	"os/exec"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -20,12 +21,10 @@ const (
 	sourceAuto    = "auto"
 	sourceJournal = "journal"
 	sourceFile    = "file"
+	sourceAll     = "all" // journald 与全部日志文件
 )
 
-var (
-	defaultJournalUnits = []string{"sshd.service", "ssh.service"}
-	defaultLogPaths     = []string{"/var/log/auth.log", "/var/log/secure"}
-)
+var defaultLogPaths = []string{"/var/log/auth.log", "/var/log/secure"}
 
 const journalHistoryTolerance = time.Minute
 
@@ -41,6 +40,34 @@ const (
 	NotifyOnFailed  NotifyOn = "failed"
 )
 
+// matches 判断事件是否在通知范围内，支持逗号分隔的多个类别、事件类型或细分类型（如 success,invalid_user）
+func (n NotifyOn) matches(event *LoginEvent) bool {
+	eventType := event.Type
+	for _, token := range strings.Split(string(n), ",") {
+		switch token = strings.TrimSpace(token); token {
+		case "", string(NotifyOnAll):
+			return true
+		case string(NotifyOnSuccess):
+			if eventType == EventLoginSuccess {
+				return true
+			}
+		case string(NotifyOnFailed):
+			if isFailureEvent(eventType) {
+				return true
+			}
+		case notifyOnPrivilege:
+			if isPrivilegeEvent(eventType) {
+				return true
+			}
+		default:
+			if token == eventType || (event.Subtype != "" && token == event.Subtype) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // eventDeduper 用于去重短时间内的重复事件（如 VERBOSE 级别下 Failed + Disconnected）
 type eventDeduper struct {
 	seen map[string]time.Time
@@ -53,7 +80,7 @@ func newEventDeduper() *eventDeduper {
 // isDuplicate 检查事件是否为重复事件，返回 true 表示应跳过
 func (d *eventDeduper) isDuplicate(event *LoginEvent) bool {
 	// 只对失败事件去重（成功事件不会重复）
-	if event.Type != EventLoginFailed {
+	if !isFailureEvent(event.Type) {
 		return false
 	}
 	key := fmt.Sprintf("%s:%d:%s", event.IP, event.Port, event.User)
@@ -89,7 +116,7 @@ func newFailRateLimiter(limit int, window time.Duration) *failRateLimiter {
 
 // shouldLimit 检查是否应该限制该失败事件的通知，返回 true 表示应跳过
 func (r *failRateLimiter) shouldLimit(event *LoginEvent) bool {
-	if r.limit <= 0 || event.Type != EventLoginFailed {
+	if r.limit <= 0 || !isFailureEvent(event.Type) {
 		return false
 	}
 
@@ -144,15 +171,8 @@ func newNotifyFilter(notifyOn NotifyOn, failLimit int, failWindow time.Duration)
 // shouldNotify 检查是否应该发送通知
 func (f *notifyFilter) shouldNotify(event *LoginEvent) bool {
 	// 检查通知类型过滤
-	switch f.notifyOn {
-	case NotifyOnSuccess:
-		if event.Type != EventLoginSuccess {
-			return false
-		}
-	case NotifyOnFailed:
-		if event.Type != EventLoginFailed {
-			return false
-		}
+	if !f.notifyOn.matches(event) {
+		return false
 	}
 
 	// 检查去重
@@ -169,6 +189,107 @@ func (f *notifyFilter) shouldNotify(event *LoginEvent) bool {
 	return true
 }
 
+// eventProcessor 统一处理来自各日志源的事件：扫描检测、过滤、发送与输出
+type eventProcessor struct {
+	notify   bool // 是否发送通知
+	printAll bool // 是否输出未通知的事件（sweep 模式）
+	filter   *notifyFilter
+	scan     *scanDetector
+	sessions *sessionTracker
+	respond  *responder // 自动处置，nil 表示关闭
+	loc      *time.Location
+	dispatch func(event *LoginEvent) error // 发送通知，为空时使用 dispatchEvent
+	severity severityPolicy                // 常用国家判定，零值表示只按事件类型定级
+
+	// 日志源与文件审计在不同 goroutine 中提交事件
+	mu sync.Mutex
+}
+
+// handle 处理单个事件。会话、扫描与过滤状态在锁内更新；自动处置与发送通知可能较慢，
+// 在锁外执行，避免阻塞其他日志源
+func (p *eventProcessor) handle(event *LoginEvent) {
+	for _, out := range p.process(event) {
+		p.emit(out)
+	}
+}
+
+// emission 通过扫描与过滤后待输出的事件
+type emission struct {
+	event *LoginEvent
+	send  bool // 发送通知（已通过类型过滤、去重与限流）
+	print bool // 输出到终端
+}
+
+// process 更新会话与扫描状态并做通知过滤，返回需要输出的事件（可能包含聚合的 scan_detected）
+func (p *eventProcessor) process(event *LoginEvent) []emission {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.sessions.observe(event) {
+		return nil
+	}
+	if isPrivilegeEvent(event.Type) {
+		p.sessions.attach(event)
+	}
+
+	var out []emission
+	aggregate, suppressed := p.scan.observe(event)
+	if aggregate != nil {
+		out = append(out, p.decide(aggregate))
+	}
+
+	// 扫描探测日志只参与聚合，不单独通知
+	if event.Type == EventScanProbe {
+		return out
+	}
+	if suppressed {
+		debugf("notify: 扫描来源 %s 的事件已合并到 scan_detected", event.IP)
+		if p.printAll {
+			out = append(out, emission{event: event, print: true})
+		}
+		return out
+	}
+	return append(out, p.decide(event))
+}
+
+func (p *eventProcessor) decide(event *LoginEvent) emission {
+	send := p.notify && p.filter.shouldNotify(event)
+	return emission{event: event, send: send, print: send || !p.notify || p.printAll}
+}
+
+// emit 执行自动处置并发送、输出事件。自动处置不受通知过滤影响：
+// 被去重、限流或 --notify-on 排除的违规登录同样需要终止会话
+func (p *eventProcessor) emit(out emission) {
+	event := out.event
+	p.respond.respond(event)
+	if !out.send && !out.print {
+		return
+	}
+	// IP 归属地查询可能访问远程接口，不在解析与加锁阶段进行，
+	// 且只为需要发送或输出的事件查询，避免把被过滤的来源 IP 逐个发给第三方接口
+	if event.Location == "" && event.IP != "" {
+		event.Location = LookupIPLocation(event.IP)
+		p.mu.Lock()
+		p.sessions.locate(event)
+		p.mu.Unlock()
+	}
+	if event.Severity == "" {
+		event.Severity = eventSeverity(event, p.severity)
+	}
+	if out.send {
+		dispatch := p.dispatch
+		if dispatch == nil {
+			dispatch = dispatchEvent
+		}
+		if err := dispatch(event); err != nil {
+			log.Printf("发送通知失败: %v", err)
+		}
+	}
+	if out.print {
+		printEventSummary(*event, p.loc)
+	}
+}
+
 // 解析 systemd journal 输出（journalctl -o json）的结构体
 type journalRecord struct {
 	Cursor     string `json:"__CURSOR"`
@@ -176,39 +297,59 @@ type journalRecord struct {
 	Hostname   string `json:"_HOSTNAME"`
 	RealtimeTS string `json:"__REALTIME_TIMESTAMP"`
 	Unit       string `json:"_SYSTEMD_UNIT"`
+	Identifier string `json:"SYSLOG_IDENTIFIER"`
+	Comm       string `json:"_COMM"`
+	UID        string `json:"_UID"`
+	PID        string `json:"_PID"`
 }
 
 // WatchOptions 控制 watch 模式行为
 type WatchOptions struct {
-	CursorPath   string
-	Source       string
-	JournalUnits []string
-	LogPaths     []string
-	PollTimeout  time.Duration
-	DisplayLoc   *time.Location
-	NotifyOn     NotifyOn      // 通知类型：all/success/failed
-	FailLimit    int           // 每 IP 失败通知限制数量，0 表示不限制
-	FailWindow   time.Duration // 失败限制时间窗口
+	CursorPath    string
+	Source        string
+	JournalUnits  []string
+	Identifiers   []string // sshd 的 syslog 标识（支持通配符），为空使用默认值
+	Comms         []string // sshd 的进程名（支持通配符），为空使用默认值
+	JournalReader string   // journald 读取方式：auto/native/journalctl
+	LogPaths      []string
+	PollTimeout   time.Duration
+	DisplayLoc    *time.Location
+	LogLoc        *time.Location // 日志时间戳不含时区时使用的时区，nil 表示本机时区
+	NotifyOn      NotifyOn       // 通知类型：all/success/failed/privilege 或事件类型列表
+	FailLimit     int            // 每 IP 失败通知限制数量，0 表示不限制
+	FailWindow    time.Duration  // 失败限制时间窗口
+	ScanWindow    time.Duration  // 扫描检测时间窗口，0 表示关闭
+	ScanLimit     int            // 窗口内触发扫描判定的阈值
+	DryRun        bool           // 自动处置仅演练，不实际终止会话
+	AuditFiles    bool           // 审计 authorized_keys、sudoers 与 sshd 配置的变更
 }
 
 // SweepOptions 控制 sweep 模式行为
 type SweepOptions struct {
-	CursorPath   string
-	Source       string
-	JournalUnits []string
-	LogPaths     []string
-	Since        time.Duration
-	Notify       bool
-	DisplayLoc   *time.Location
-	NotifyOn     NotifyOn      // 通知类型：all/success/failed
-	FailLimit    int           // 每 IP 失败通知限制数量，0 表示不限制
-	FailWindow   time.Duration // 失败限制时间窗口
+	CursorPath    string
+	Source        string
+	JournalUnits  []string
+	Identifiers   []string // sshd 的 syslog 标识（支持通配符），为空使用默认值
+	Comms         []string // sshd 的进程名（支持通配符），为空使用默认值
+	JournalReader string   // journald 读取方式：auto/native/journalctl
+	LogPaths      []string
+	Since         time.Duration
+	Notify        bool
+	DisplayLoc    *time.Location
+	LogLoc        *time.Location // 日志时间戳不含时区时使用的时区，nil 表示本机时区
+	NotifyOn      NotifyOn       // 通知类型：all/success/failed/privilege 或事件类型列表
+	FailLimit     int            // 每 IP 失败通知限制数量，0 表示不限制
+	FailWindow    time.Duration  // 失败限制时间窗口
+	ScanWindow    time.Duration  // 扫描检测时间窗口，0 表示关闭
+	ScanLimit     int            // 窗口内触发扫描判定的阈值
 }
 
 type sourceSelection struct {
 	Source      string
-	Units       []string
-	LogPath     string
+	Match       *sourceMatcher
+	Reader      string   // journald 读取方式
+	Journal     bool     // 是否读取 journald
+	LogPaths    []string // 需要读取的日志文件，多个日志源时并行读取并合并
 	Description string
 }
 
@@ -231,7 +372,7 @@ func RunWatch(ctx context.Context, opts WatchOptions) error {
 		return err
 	}
 
-	selection, err := determineSource(opts.Source, opts.JournalUnits, opts.LogPaths, state, 0, true)
+	selection, err := determineSource(opts.Source, newSourceMatcher(opts.JournalUnits, opts.Identifiers, opts.Comms), opts.JournalReader, opts.LogPaths, state, 0, true)
 	if err != nil {
 		return err
 	}
@@ -243,17 +384,32 @@ func RunWatch(ctx context.Context, opts WatchOptions) error {
 	if opts.FailLimit > 0 {
 		fmt.Printf(">>> 失败限流：每 IP %d 次 / %v\n", opts.FailLimit, opts.FailWindow)
 	}
-	loc := normalizeLocation(opts.DisplayLoc)
-	filter := newNotifyFilter(opts.NotifyOn, opts.FailLimit, opts.FailWindow)
+	if opts.ScanWindow > 0 {
+		fmt.Printf(">>> 扫描检测：%v 内阈值 %d\n", opts.ScanWindow, opts.ScanLimit)
+	}
+	if opts.DryRun {
+		fmt.Println(">>> 自动处置：dry-run（仅记录，不终止会话）")
+	}
+	sessions := newDefaultSessionTracker()
+	if sessions.prune() {
+		sessions.save()
+	}
+	proc := &eventProcessor{
+		notify:   true,
+		filter:   newNotifyFilter(opts.NotifyOn, opts.FailLimit, opts.FailWindow),
+		scan:     newScanDetector(opts.ScanWindow, opts.ScanLimit),
+		sessions: sessions,
+		// 自动处置只在 watch 中启用，且只处理启动之后的登录
+		respond:  newResponder(opts.DryRun, time.Now()),
+		severity: defaultSeverityPolicy(),
+		loc:      normalizeLocation(opts.DisplayLoc),
+	}
 
-	switch selection.Source {
-	case sourceJournal:
-		return runJournalWithFilter(ctx, store, state, selection.Units, opts.PollTimeout, true, 0, loc, true, filter)
-	case sourceFile:
-		return followLogFileWithFilter(ctx, store, state, selection.LogPath, opts.PollTimeout, loc, filter)
-	default:
-		return fmt.Errorf("未知监听源: %s", selection.Source)
+	if opts.AuditFiles {
+		startFileAudit(ctx, proc)
 	}
+
+	return runLogSources(ctx, store, state, selection, opts.PollTimeout, true, 0, opts.LogLoc, proc)
 }
 
 // RunSweep 处理近期 SSH 登录事件后退出
@@ -272,7 +428,7 @@ func RunSweep(ctx context.Context, opts SweepOptions) error {
 		return err
 	}
 
-	selection, err := determineSource(opts.Source, opts.JournalUnits, opts.LogPaths, state, opts.Since, false)
+	selection, err := determineSource(opts.Source, newSourceMatcher(opts.JournalUnits, opts.Identifiers, opts.Comms), opts.JournalReader, opts.LogPaths, state, opts.Since, false)
 	if err != nil {
 		return err
 	}
@@ -284,28 +440,34 @@ func RunSweep(ctx context.Context, opts SweepOptions) error {
 	if opts.Notify && opts.FailLimit > 0 {
 		fmt.Printf(">>> 失败限流：每 IP %d 次 / %v\n", opts.FailLimit, opts.FailWindow)
 	}
-	loc := normalizeLocation(opts.DisplayLoc)
-	var filter *notifyFilter
-	if opts.Notify {
-		filter = newNotifyFilter(opts.NotifyOn, opts.FailLimit, opts.FailWindow)
-	}
+	return runLogSources(ctx, store, state, selection, 0, false, opts.Since, opts.LogLoc, newSweepProcessor(opts))
+}
 
-	switch selection.Source {
-	case sourceJournal:
-		return runJournalWithFilter(ctx, store, state, selection.Units, 0, false, opts.Since, loc, opts.Notify, filter)
-	case sourceFile:
-		return sweepLogFileWithFilter(ctx, store, state, selection.LogPath, opts.Since, loc, opts.Notify, filter)
-	default:
-		return fmt.Errorf("未知监听源: %s", selection.Source)
+// newSweepProcessor sweep 处理历史日志：会话表只在内存中重建，且从不执行自动处置，
+// 避免 --notify 时因几天前的事件终止在线会话或锁定账户
+func newSweepProcessor(opts SweepOptions) *eventProcessor {
+	return &eventProcessor{
+		notify:   opts.Notify,
+		printAll: true,
+		filter:   newNotifyFilter(opts.NotifyOn, opts.FailLimit, opts.FailWindow),
+		scan:     newScanDetector(opts.ScanWindow, opts.ScanLimit),
+		sessions: &sessionTracker{},
+		severity: defaultSeverityPolicy(),
+		loc:      normalizeLocation(opts.DisplayLoc),
 	}
 }
 
-func determineSource(source string, units, paths []string, state *SourceState, since time.Duration, follow bool) (*sourceSelection, error) {
-	s := &sourceSelection{}
-
-	if len(units) == 0 {
-		units = append([]string{}, defaultJournalUnits...)
+func determineSource(source string, match *sourceMatcher, reader string, paths []string, state *SourceState, since time.Duration, follow bool) (*sourceSelection, error) {
+	reader = strings.ToLower(strings.TrimSpace(reader))
+	switch reader {
+	case "":
+		reader = journalReaderAuto
+	case journalReaderAuto, journalReaderNative, journalReaderJournalctl:
+	default:
+		return nil, fmt.Errorf("不支持的 journal-reader：%s（可选 auto|native|journalctl）", reader)
 	}
+	s := &sourceSelection{Match: match, Reader: reader}
+
 	if len(paths) == 0 {
 		paths = append([]string{}, defaultLogPaths...)
 	}
@@ -315,33 +477,54 @@ func determineSource(source string, units, paths []string, state *SourceState, s
 		source = sourceAuto
 	}
 
-	journalOK, journalCount := probeJournal(units, state, since)
+	journalOK, journalCount := probeJournal(match, reader, state, since)
 	journalRecent := journalCount > 0
-	logPath, logExists := firstExisting(paths)
+	logPaths := existingLogFiles(paths)
+	logExists := len(logPaths) > 0
+	logPath := ""
+	if logExists {
+		logPath = logPaths[0]
+	}
 
 	switch source {
 	case sourceJournal:
 		if !journalOK {
-			return nil, fmt.Errorf("journalctl 不可用或无法访问")
+			return nil, fmt.Errorf("journal 文件与 journalctl 均不可用或无法访问")
 		}
 		s.Source = sourceJournal
-		s.Units = units
-		s.Description = fmt.Sprintf("journald（units=%v）", units)
+		s.Journal = true
+		s.Description = fmt.Sprintf("journald（%s）", match.describe())
 		return s, nil
 	case sourceFile:
 		if !logExists {
 			return nil, fmt.Errorf("未找到有效的日志文件：%v", paths)
 		}
 		s.Source = sourceFile
-		s.LogPath = logPath
-		s.Description = fmt.Sprintf("文件日志：%s", logPath)
+		s.LogPaths = logPaths
+		s.Description = fmt.Sprintf("文件日志：%s", strings.Join(logPaths, ", "))
+		return s, nil
+	case sourceAll:
+		if !journalOK && !logExists {
+			return nil, fmt.Errorf("未检测到可用的日志源（journald 不可用，且未找到 %v）", paths)
+		}
+		s.Source = sourceAll
+		s.Journal = journalOK
+		s.LogPaths = logPaths
+		var parts []string
+		if journalOK {
+			parts = append(parts, fmt.Sprintf("journald（%s）", match.describe()))
+		}
+		if logExists {
+			parts = append(parts, fmt.Sprintf("文件日志：%s", strings.Join(logPaths, ", ")))
+		}
+		s.Description = strings.Join(parts, " + ")
 		return s, nil
 	case sourceAuto:
 		if follow {
 			if journalOK {
 				s.Source = sourceJournal
-				s.Units = units
-				desc := fmt.Sprintf("journald（units=%v）", units)
+				s.Journal = true
+				desc := fmt.Sprintf("journald（%s）", match.describe())
 				if !journalRecent {
 					desc += "，等待新事件"
 				}
@@ -350,37 +533,54 @@ func determineSource(source string, units, paths []string, state *SourceState, s
 			}
 			if logExists {
 				s.Source = sourceFile
-				s.LogPath = logPath
+				s.LogPaths = []string{logPath}
 				s.Description = fmt.Sprintf("文件日志：%s", logPath)
 				return s, nil
 			}
 		} else {
 			if journalOK && journalRecent {
 				s.Source = sourceJournal
-				s.Units = units
-				s.Description = fmt.Sprintf("journald（units=%v，命中近期事件）", units)
+				s.Journal = true
+				s.Description = fmt.Sprintf("journald（%s，命中近期事件）", match.describe())
 				return s, nil
 			}
 			if logExists {
 				s.Source = sourceFile
-				s.LogPath = logPath
+				s.LogPaths = []string{logPath}
 				s.Description = fmt.Sprintf("文件日志：%s", logPath)
 				return s, nil
 			}
 			if journalOK {
 				s.Source = sourceJournal
-				s.Units = units
-				s.Description = fmt.Sprintf("journald（units=%v，无匹配事件）", units)
+				s.Journal = true
+				s.Description = fmt.Sprintf("journald（%s，无匹配事件）", match.describe())
 				return s, nil
 			}
 		}
 		return nil, fmt.Errorf("未检测到可用的日志源（journalctl 不可用，且未找到 %v）", paths)
 	default:
-		return nil, fmt.Errorf("不支持的 source：%s（可选 auto|journal|file）", source)
+		return nil, fmt.Errorf("不支持的 source：%s（可选 auto|journal|file|all）", source)
 	}
 }
 
-func probeJournal(units []string, state *SourceState, since time.Duration) (bool, int) {
+func probeJournal(match *sourceMatcher, reader string, state *SourceState, since time.Duration) (bool, int) {
+	// 优先直接读取 journal 文件，避免每次启动额外运行一次 journalctl
+	if reader != journalReaderJournalctl {
+		native, err := openNativeJournal(match)
+		count := 0
+		if err == nil {
+			count, err = native.probe(state, since)
+		}
+		if err == nil {
+			debugf("notify: probeJournal native result: matchedCount=%d", count)
+			return true, count
+		}
+		debugf("notify: probeJournal native unavailable: %v", err)
+		if reader == journalReaderNative {
+			return false, 0
+		}
+	}
+
 	if _, err := exec.LookPath("journalctl"); err != nil {
 		debugf("notify: journalctl not found")
 		return false, 0
@@ -397,9 +597,7 @@ func probeJournal(units []string, state *SourceState, since time.Duration) (bool
 		t := time.Now().Add(-window).Format("2006-01-02 15:04:05")
 		args = append(args, "--since", t)
 	}
-	for _, unit := range units {
-		args = append(args, "-u", unit)
-	}
+	args = append(args, match.journalArgs()...)
 
 	debugf("notify: probeJournal cmd: journalctl %v", args)
 
@@ -432,6 +630,9 @@ func probeJournal(units []string, state *SourceState, since time.Duration) (bool
 			debugf("notify: probeJournal json parse error: %v", err)
 			continue
 		}
+		if !match.matchJournal(record.Unit, record.Identifier, record.Comm) {
+			continue
+		}
 		ts := parseRealtime(record.RealtimeTS)
 		debugf("notify: probeJournal message: %s", record.Message)
 		if _, ok := parseJournalMessage(record.Message, record.Hostname, ts); ok {
@@ -447,37 +648,79 @@ func probeJournal(units []string, state *SourceState, since time.Duration) (bool
 	return true, count
 }
 
-func firstExisting(paths []string) (string, bool) {
-	for _, p := range paths {
-		if p == "" {
-			continue
-		}
-		if info, err := os.Stat(p); err == nil && info.Mode().IsRegular() {
-			return p, true
-		}
-	}
-	return "", false
-}
-
-func runJournalWithFilter(ctx context.Context, store *CursorStore, state *SourceState, units []string, poll time.Duration, follow bool, since time.Duration, loc *time.Location, notify bool, filter *notifyFilter) error {
+func runJournalWithFilter(ctx context.Context, store *CursorStore, state *SourceState, match *sourceMatcher, reader string, poll time.Duration, follow bool, since time.Duration, proc eventHandler) error {
 	if state == nil {
 		state = &SourceState{}
 	}
 
 	startTime := time.Now()
 	skipHistorical := follow && state.JournalCursor == "" && since <= 0
-	// 如果没有 filter，创建一个默认的（兼容旧调用）
-	if filter == nil {
-		filter = newNotifyFilter(NotifyOnAll, 0, 0)
+
+	handle := func(record journalRecord) {
+		if !match.matchJournal(record.Unit, record.Identifier, record.Comm) {
+			return
+		}
+
+		ts := parseRealtime(record.RealtimeTS)
+		event, ok := parseJournalMessage(record.Message, record.Hostname, ts)
+		if !ok {
+			return
+		}
+		event.PID, _ = strconv.Atoi(record.PID)
+		event.Verified = match.verifiedSSHD(record.Comm, record.UID)
+
+		if skipHistorical && shouldSkipHistoricalEvent(startTime, ts) {
+			skipHistorical = true
+			debugf("notify: 跳过历史 journald 事件 cursor=%s ts=%s", record.Cursor, ts.Format(time.RFC3339))
+			state.JournalCursor = record.Cursor
+			if err := store.Save(state); err != nil {
+				log.Printf("写入状态失败: %v", err)
+			}
+			return
+		}
+		skipHistorical = false
+
+		logSource := record.Unit
+		if logSource == "" {
+			logSource = record.Identifier
+		}
+		if logSource != "" {
+			event.LogPath = fmt.Sprintf("journald:%s", logSource)
+		} else {
+			event.LogPath = "journald"
+		}
+
+		proc.handle(event)
+
+		state.JournalCursor = record.Cursor
+		if err := store.Save(state); err != nil {
+			log.Printf("写入状态失败: %v", err)
+		}
 	}
 
+	if reader != journalReaderJournalctl {
+		native, err := openNativeJournal(match)
+		if err == nil {
+			err = runNativeJournal(ctx, native, state, poll, follow, since, handle)
+			if err == nil || reader == journalReaderNative || !errors.Is(err, errJournalUnsupported) {
+				return err
+			}
+		} else if reader == journalReaderNative {
+			return fmt.Errorf("无法直接读取 journal 文件: %w", err)
+		}
+		log.Printf("无法直接读取 journal 文件，改用 journalctl: %v", err)
+	}
+
+	return runJournalctl(ctx, state, match, follow, since, handle)
+}
+
+// runJournalctl 通过 journalctl -o json 读取 journald 事件
+func runJournalctl(ctx context.Context, state *SourceState, match *sourceMatcher, follow bool, since time.Duration, handle func(journalRecord)) error {
 	args := []string{"--no-pager", "-o", "json"}
 	if follow {
 		args = append(args, "--follow")
 	}
-	for _, unit := range units {
-		args = append(args, "-u", unit)
-	}
+	args = append(args, match.journalArgs()...)
 
 	if !follow && since > 0 {
 		sinceTime := time.Now().Add(-since).Format("2006-01-02 15:04:05")
@@ -525,57 +768,7 @@ func runJournalWithFilter(ctx context.Context, store *CursorStore, state *Source
 			log.Printf("解析 journald 输出失败: %v", err)
 			continue
 		}
-
-		ts := parseRealtime(record.RealtimeTS)
-		event, ok := parseJournalMessage(record.Message, record.Hostname, ts)
-		if !ok {
-			continue
-		}
-
-		if skipHistorical && shouldSkipHistoricalEvent(startTime, ts) {
-			skipHistorical = true
-			debugf("notify: 跳过历史 journald 事件 cursor=%s ts=%s", record.Cursor, ts.Format(time.RFC3339))
-			state.JournalCursor = record.Cursor
-			if err := store.Save(state); err != nil {
-				log.Printf("写入状态失败: %v", err)
-			}
-			continue
-		}
-		skipHistorical = false
-
-		logSource := record.Unit
-		if logSource == "" {
-			logSource = strings.Join(units, ",")
-		}
-		if logSource != "" {
-			event.LogPath = fmt.Sprintf("journald:%s", logSource)
-		} else {
-			event.LogPath = "journald"
-		}
-
-		// 使用 filter 检查是否应该发送通知
-		shouldSend := notify && filter.shouldNotify(event)
-		if notify && !shouldSend {
-			// 更新游标但不发送通知
-			state.JournalCursor = record.Cursor
-			if err := store.Save(state); err != nil {
-				log.Printf("写入状态失败: %v", err)
-			}
-			continue
-		}
-
-		if shouldSend {
-			if err := dispatchEvent(event); err != nil {
-				log.Printf("发送通知失败: %v", err)
-			}
-		}
-
-		printEventSummary(*event, loc)
-
-		state.JournalCursor = record.Cursor
-		if err := store.Save(state); err != nil {
-			log.Printf("写入状态失败: %v", err)
-		}
+		handle(record)
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -599,7 +792,7 @@ func shouldSkipHistoricalEvent(start, event time.Time) bool {
 	return event.Before(start.Add(-journalHistoryTolerance))
 }
 
-func followLogFileWithFilter(ctx context.Context, store *CursorStore, state *SourceState, path string, poll time.Duration, loc *time.Location, filter *notifyFilter) error {
+func followLogFileWithFilter(ctx context.Context, store *CursorStore, state *SourceState, path string, match *sourceMatcher, poll time.Duration, loc *time.Location, proc eventHandler) error {
 	if poll <= 0 {
 		poll = time.Second
 	}
@@ -609,25 +802,9 @@ func followLogFileWithFilter(ctx context.Context, store *CursorStore, state *Sou
 		offset = 0
 	}
 
-	if filter == nil {
-		filter = newNotifyFilter(NotifyOnAll, 0, 0)
-	}
-
 	process := func(event *LoginEvent, newOffset int64) {
 		event.LogPath = path
-		// 使用 filter 检查是否应该发送通知
-		if !filter.shouldNotify(event) {
-			offset = newOffset
-			state.FileOffsets[path] = offset
-			if err := store.Save(state); err != nil {
-				log.Printf("写入状态失败: %v", err)
-			}
-			return
-		}
-		if err := dispatchEvent(event); err != nil {
-			log.Printf("发送通知失败: %v", err)
-		}
-		printEventSummary(*event, loc)
+		proc.handle(event)
 		offset = newOffset
 		state.FileOffsets[path] = offset
 		if err := store.Save(state); err != nil {
@@ -636,7 +813,7 @@ func followLogFileWithFilter(ctx context.Context, store *CursorStore, state *Sou
 	}
 
 	for {
-		_, err := readLogFile(ctx, path, offset, process, true, poll)
+		_, err := readLogFile(ctx, path, offset, process, true, poll, loc, match)
 		if err != nil {
 			if errors.Is(err, context.Canceled) {
 				return nil
@@ -653,7 +830,7 @@ func followLogFileWithFilter(ctx context.Context, store *CursorStore, state *Sou
 	}
 }
 
-func sweepLogFileWithFilter(ctx context.Context, store *CursorStore, state *SourceState, path string, since time.Duration, loc *time.Location, notify bool, filter *notifyFilter) error {
+func sweepLogFileWithFilter(ctx context.Context, store *CursorStore, state *SourceState, path string, match *sourceMatcher, since time.Duration, loc *time.Location, proc eventHandler) error {
 	offset := state.FileOffsets[path]
 	startOffset := offset
 	cutoff := time.Time{}
@@ -662,10 +839,6 @@ func sweepLogFileWithFilter(ctx context.Context, store *CursorStore, state *Sour
 		cutoff = time.Now().Add(-since)
 	}
 
-	if filter == nil {
-		filter = newNotifyFilter(NotifyOnAll, 0, 0)
-	}
-
 	latest := offset
 	process := func(event *LoginEvent, newOffset int64) {
 		if newOffset > latest {
@@ -675,17 +848,32 @@ func sweepLogFileWithFilter(ctx context.Context, store *CursorStore, state *Sour
 			return
 		}
 		event.LogPath = path
-		// 使用 filter 检查是否应该发送通知
-		shouldSend := notify && filter.shouldNotify(event)
-		if shouldSend {
-			if err := dispatchEvent(event); err != nil {
-				log.Printf("发送通知失败: %v", err)
+		proc.handle(event)
+	}
+
+	if !cutoff.IsZero() {
+		// 回溯时先按时间顺序读取 logrotate 轮转出的历史文件，最后一次写入早于截止时间的整体跳过
+		for _, archive := range findLogArchives(path) {
+			if archive.modTime.Before(cutoff) {
+				continue
+			}
+			err := readLogArchive(ctx, archive.path, func(event *LoginEvent) {
+				if event.Timestamp.Before(cutoff) {
+					return
+				}
+				event.LogPath = archive.path
+				proc.handle(event)
+			}, loc, match)
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return err
+				}
+				log.Printf("读取历史日志 %s 失败: %v", archive.path, err)
 			}
 		}
-		printEventSummary(*event, loc)
 	}
 
-	finalOffset, err := readLogFile(ctx, path, startOffset, process, false, 0)
+	finalOffset, err := readLogFile(ctx, path, startOffset, process, false, 0, loc, match)
 	if err != nil {
 		return err
 	}
@@ -696,15 +884,15 @@ func sweepLogFileWithFilter(ctx context.Context, store *CursorStore, state *Sour
 	return store.Save(state)
 }
 
-func readLogFile(ctx context.Context, path string, startOffset int64, handle func(*LoginEvent, int64), follow bool, poll time.Duration) (int64, error) {
+func readLogFile(ctx context.Context, path string, startOffset int64, handle func(*LoginEvent, int64), follow bool, poll time.Duration, loc *time.Location, match *sourceMatcher) (int64, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return startOffset, fmt.Errorf("打开日志文件失败: %w", err)
 	}
-	defer file.Close()
 
 	info, err := file.Stat()
 	if err != nil {
+		file.Close()
 		return startOffset, fmt.Errorf("读取文件信息失败: %w", err)
 	}
 
@@ -713,71 +901,49 @@ func readLogFile(ctx context.Context, path string, startOffset int64, handle fun
 		offset = info.Size()
 	}
 	if offset > info.Size() {
-		offset = info.Size()
+		// 上次运行之后文件已被轮转或截断，从头读取
+		offset = 0
 	}
 
 	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
 		return offset, fmt.Errorf("定位文件偏移失败: %w", err)
 	}
 
-	reader := bufio.NewReader(file)
-	for {
-		select {
-		case <-ctx.Done():
-			return offset, context.Canceled
-		default:
+	tail := newLogTail(path, file, offset)
+	defer tail.Close()
+	process := func(line string, offset int64) {
+		if event, ok := parseAuthLogLine(line, loc, match); ok {
+			handle(event, offset)
 		}
+	}
+
+	if !follow {
+		err := tail.readLines(ctx, process)
+		return tail.offset, err
+	}
 
-		line, err := reader.ReadString('\n')
+	waiter := newLogChangeWaiter(path, poll)
+	defer waiter.Close()
+	for {
+		if err := tail.readLines(ctx, process); err != nil {
+			return tail.offset, err
+		}
+		rotated, err := tail.checkRotation(ctx, process)
 		if err != nil {
-			if errors.Is(err, io.EOF) {
-				if !follow {
-					return offset, nil
-				}
-				time.Sleep(poll)
-				if err := reopenIfRotated(&file, path, &reader, &offset); err != nil {
-					log.Printf("处理日志轮转失败: %v", err)
-				}
-				continue
+			if errors.Is(err, context.Canceled) {
+				return tail.offset, err
 			}
-			return offset, fmt.Errorf("读取日志失败: %w", err)
+			log.Printf("处理日志轮转失败: %v", err)
 		}
-
-		offset += int64(len(line))
-		event, ok := parseAuthLogLine(strings.TrimRight(line, "\r\n"))
-		if !ok {
+		if rotated {
+			waiter.rewatch()
 			continue
 		}
-		handle(event, offset)
-	}
-}
-
-func reopenIfRotated(file **os.File, path string, reader **bufio.Reader, offset *int64) error {
-	currentInfo, err := (*file).Stat()
-	if err != nil {
-		return err
-	}
-
-	newInfo, err := os.Stat(path)
-	if err != nil {
-		return err
-	}
-
-	if os.SameFile(currentInfo, newInfo) && newInfo.Size() >= *offset {
-		return nil
-	}
-
-	(*file).Close()
-
-	newFile, err := os.Open(path)
-	if err != nil {
-		return err
+		if err := waiter.wait(ctx); err != nil {
+			return tail.offset, context.Canceled
+		}
 	}
-
-	*file = newFile
-	*reader = bufio.NewReader(newFile)
-	*offset = 0
-	return nil
 }
 
 func parseRealtime(ts string) time.Time {
@@ -846,6 +1012,75 @@ func buildChannelNotifier(ch ChannelConfig) (Notifier, error) {
 			return nil, fmt.Errorf("email 配置为空")
 		}
 		return NewEmailNotifierFromChannel(ch.Email), nil
+	case "telegram":
+		if ch.Telegram == nil {
+			return nil, fmt.Errorf("telegram 配置为空")
+		}
+		return NewTelegramNotifier(ch.Telegram), nil
+	case robotLark, robotDingTalk, robotWeCom:
+		kind := strings.ToLower(ch.Type)
+		cfg := ch.robot(kind)
+		if cfg == nil {
+			return nil, fmt.Errorf("%s 配置为空", kind)
+		}
+		return NewRobotNotifier(kind, cfg), nil
+	case chatSlack, chatDiscord, chatTeams:
+		kind := strings.ToLower(ch.Type)
+		cfg := ch.chatWebhook(kind)
+		if cfg == nil {
+			return nil, fmt.Errorf("%s 配置为空", kind)
+		}
+		return NewChatNotifier(kind, cfg), nil
+	case "ntfy":
+		if ch.Ntfy == nil {
+			return nil, fmt.Errorf("ntfy 配置为空")
+		}
+		return NewNtfyNotifier(ch.Ntfy), nil
+	case "gotify":
+		if ch.Gotify == nil {
+			return nil, fmt.Errorf("gotify 配置为空")
+		}
+		return NewGotifyNotifier(ch.Gotify), nil
+	case "pushover":
+		if ch.Pushover == nil {
+			return nil, fmt.Errorf("pushover 配置为空")
+		}
+		return NewPushoverNotifier(ch.Pushover), nil
+	case "bark":
+		if ch.Bark == nil {
+			return nil, fmt.Errorf("bark 配置为空")
+		}
+		return NewBarkNotifier(ch.Bark), nil
+	case "syslog":
+		if ch.Syslog == nil {
+			return nil, fmt.Errorf("syslog 配置为空")
+		}
+		return NewSyslogNotifier(ch.Syslog), nil
+	case "file":
+		if ch.File == nil {
+			return nil, fmt.Errorf("file 配置为空")
+		}
+		return NewFileSinkNotifier(ch.File), nil
+	case "exec":
+		if ch.Exec == nil {
+			return nil, fmt.Errorf("exec 配置为空")
+		}
+		return NewExecNotifier(ch.Exec), nil
+	case "mqtt":
+		if ch.MQTT == nil {
+			return nil, fmt.Errorf("mqtt 配置为空")
+		}
+		return NewMQTTNotifier(ch.MQTT)
+	case "matrix":
+		if ch.Matrix == nil {
+			return nil, fmt.Errorf("matrix 配置为空")
+		}
+		return NewMatrixNotifier(ch.Matrix), nil
+	case "webhook":
+		if ch.Webhook == nil {
+			return nil, fmt.Errorf("webhook 配置为空")
+		}
+		return NewSignedWebhookNotifier(ch.Webhook), nil
 	default:
 		return nil, fmt.Errorf("未知通知类型: %s", ch.Type)
 	}
@@ -872,14 +1107,32 @@ func printEventSummary(event LoginEvent, loc *time.Location) {
 		logPath = "-"
 	}
 
-	fmt.Fprintf(os.Stdout, "[%s] %s 用户=%s IP=%s 端口=%s 方式=%s 主机=%s 日志路径=%s\n",
+	extra := ""
+	if event.TargetUser != "" {
+		extra += " 目标用户=" + event.TargetUser
+	}
+	if event.Command != "" {
+		extra += fmt.Sprintf(" 命令=%q", event.Command)
+	}
+	if event.Duration > 0 {
+		extra += " 时长=" + formatSessionDuration(event.Duration)
+	}
+	if event.Action != "" {
+		extra += " 处置=" + event.Action
+	}
+	if event.Severity != "" {
+		extra += " 级别=" + event.Severity
+	}
+
+	fmt.Fprintf(os.Stdout, "[%s] %s 用户=%s IP=%s 端口=%s 方式=%s 主机=%s 日志路径=%s%s\n",
 		displayTime,
-		event.Type,
+		event.displayType(),
 		event.User,
 		event.IP,
 		port,
 		method,
 		event.Hostname,
 		logPath,
+		extra,
 	)
 }
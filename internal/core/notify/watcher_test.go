@@ -31,3 +31,45 @@ func mustParseTime(t *testing.T, value string) time.Time {
 	}
 	return parsed
 }
+
+func TestEventProcessorLocatesAfterParsing(t *testing.T) {
+	line := "Mar  3 10:00:00 web-1 sshd[4321]: Accepted password for alice from 10.0.0.5 port 50022 ssh2"
+	event, ok := parseAuthLogLine(line, time.UTC, newSourceMatcher(nil, nil, nil))
+	if !ok {
+		t.Fatalf("failed to parse %q", line)
+	}
+	if event.Location != "" {
+		t.Fatalf("parsing must not look up the IP location, got %q", event.Location)
+	}
+
+	proc := &eventProcessor{
+		notify:   true,
+		filter:   newNotifyFilter(NotifyOnAll, 0, 0),
+		sessions: &sessionTracker{},
+		dispatch: func(*LoginEvent) error { return nil },
+	}
+	proc.handle(event)
+	if event.Location != "内网" {
+		t.Fatalf("Location = %q, want 内网", event.Location)
+	}
+	if len(proc.sessions.sessions) != 1 || proc.sessions.sessions[0].Location != "内网" {
+		t.Fatalf("session location not updated: %+v", proc.sessions.sessions)
+	}
+}
+
+func TestSweepProcessorNeverResponds(t *testing.T) {
+	proc := newSweepProcessor(SweepOptions{Notify: true, NotifyOn: NotifyOnAll})
+	if proc.respond != nil {
+		t.Fatalf("sweep must not enable auto-response")
+	}
+	if proc.sessions == nil || proc.sessions.path != "" {
+		t.Fatalf("sweep must use an in-memory session table")
+	}
+
+	var sent []LoginEvent
+	proc.dispatch = func(event *LoginEvent) error { sent = append(sent, *event); return nil }
+	proc.handle(evilKeyLogin(5000, time.Now()))
+	if len(sent) != 1 || sent[0].Action != "" {
+		t.Fatalf("sweep must notify without responding, got %+v", sent)
+	}
+}
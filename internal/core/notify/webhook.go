@@ -33,22 +33,7 @@ func NewCurlNotifier(curlCmd string) (*CurlNotifier, error) {
 
 // Send 使用 curl 命令发送通知
 func (c *CurlNotifier) Send(event LoginEvent) error {
-	// 构建模板数据
-	data := map[string]any{
-		"Type":      event.Type,
-		"User":      event.User,
-		"IP":        event.IP,
-		"Port":      event.Port,
-		"Method":    event.Method,
-		"Hostname":  event.Hostname,
-		"Timestamp": formatShanghaiRFC3339(event.Timestamp),
-		"Location":  event.Location,
-		"LogPath":   event.LogPath,
-		"Message":   event.Message,
-		"HostIP":    event.HostIP,
-	}
-
-	resp, err := c.parsedCurl.Execute(data)
+	resp, err := c.parsedCurl.Execute(eventTemplateData(event))
 	if err != nil {
 		return fmt.Errorf("执行 curl 请求失败: %w", err)
 	}
@@ -66,6 +51,30 @@ func (c *CurlNotifier) Send(event LoginEvent) error {
 	return nil
 }
 
+// eventTemplateData 返回 curl 命令与自定义消息模板可用的变量
+func eventTemplateData(event LoginEvent) map[string]any {
+	return map[string]any{
+		"Type":       event.Type,
+		"Subtype":    event.Subtype,
+		"Severity":   event.Severity,
+		"User":       event.User,
+		"IP":         event.IP,
+		"Port":       event.Port,
+		"Method":     event.Method,
+		"Hostname":   event.Hostname,
+		"Timestamp":  formatShanghaiRFC3339(event.Timestamp),
+		"Location":   event.Location,
+		"LogPath":    event.LogPath,
+		"Message":    event.Message,
+		"HostIP":     event.HostIP,
+		"Action":     event.Action,
+		"PID":        event.PID,
+		"Duration":   formatSessionDuration(event.Duration),
+		"TargetUser": event.TargetUser,
+		"Command":    event.Command,
+	}
+}
+
 // Test 测试 curl 配置
 func (c *CurlNotifier) Test() error {
 	testEvent := LoginEvent{
@@ -113,7 +122,7 @@ func formatLoginMessage(event LoginEvent) string {
 	}
 	timestamp := formatShanghaiRFC3339(event.Timestamp)
 
-	return fmt.Sprintf(`服务器登录提醒
+	content := fmt.Sprintf(`服务器登录提醒
 事件类型: %s
 服务器: %s
 用户: %s
@@ -124,7 +133,7 @@ func formatLoginMessage(event LoginEvent) string {
 时间: %s
 日志路径: %s
 日志: %s`,
-		event.Type,
+		event.displayType(),
 		event.Hostname,
 		event.User,
 		event.IP,
@@ -134,6 +143,10 @@ func formatLoginMessage(event LoginEvent) string {
 		timestamp,
 		logPath,
 		message)
+	if extra := formatExtraLines(event); extra != "" {
+		content += "\n" + strings.TrimSuffix(extra, "\n")
+	}
+	return content
 }
 
 // configureCurl 配置基于 curl 命令的通知
@@ -167,6 +180,28 @@ func configureCurl(curlCmd, name string) error {
 	return addOrUpdateChannel(channel)
 }
 
+// configureChannel 校验、测试并保存渠道配置
+func configureChannel(channel ChannelConfig) error {
+	if err := ValidateChannelConfig(&channel); err != nil {
+		return err
+	}
+	notifier, err := buildChannelNotifier(channel)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("正在测试 %s 配置...\n", channel.Type)
+	if err := notifier.Test(); err != nil {
+		return err
+	}
+	fmt.Println("✓ 测试成功")
+
+	if channel.Name == "" {
+		channel.Name = generateChannelName(channel.Type)
+	}
+	return addOrUpdateChannel(channel)
+}
+
 // addOrUpdateChannel 添加或更新渠道配置（按 Name 判断是否为同一渠道）
 func addOrUpdateChannel(newChannel ChannelConfig) error {
 	cm := NewConfigManager()
@@ -197,7 +232,11 @@ func addOrUpdateChannel(newChannel ChannelConfig) error {
 		fmt.Printf("✓ 已添加渠道: %s\n", newChannel.Name)
 	}
 
-	// 备份并保存
+	return saveConfigWithBackup(cm, cfg)
+}
+
+// saveConfigWithBackup 备份现有配置后保存，失败时自动恢复
+func saveConfigWithBackup(cm *ConfigManager, cfg *Config) error {
 	if cm.configExists() {
 		if err := cm.BackupConfig(); err != nil {
 			return fmt.Errorf("备份配置失败: %w", err)
@@ -73,6 +73,7 @@ func newInstallCommand() *cobra.Command {
   --notify-on success    只通知登录成功（推荐，减少打扰）
   --notify-on failed     只通知登录失败
   --notify-on all        通知所有事件（默认）
+  --notify-on privilege  只通知 sudo/su 提权事件（可与其他类型逗号组合）
 
 失败限流选项：
   --fail-limit 5 --fail-window 1h   每个 IP 每小时最多 5 条失败通知
@@ -92,7 +93,7 @@ func newInstallCommand() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&opts.notifyOn, "notify-on", "all", "通知类型：all｜success｜failed")
+	cmd.Flags().StringVar(&opts.notifyOn, "notify-on", "all", "通知类型：all｜success｜failed｜privilege、事件类型或细分类型，可逗号分隔")
 	cmd.Flags().IntVar(&opts.failLimit, "fail-limit", 0, "每个 IP 失败通知限制数量（0 表示不限制）")
 	cmd.Flags().StringVar(&opts.failWindow, "fail-window", "1h", "失败限制时间窗口（支持 s/m/h/d/w/M）")
 
@@ -13,6 +13,8 @@ import (
 	"strings"
 	"time"
 	"unicode"
+
+	"github.com/Hootrix/sshield/internal/debug"
 )
 
 // KeyType 定义SSH密钥类型
@@ -31,10 +33,7 @@ const (
 )
 
 func debugf(format string, args ...interface{}) {
-	if os.Getenv("SSHIELD_DEBUG") == "" {
-		return
-	}
-	fmt.Printf("[sshield-debug] "+format+"\n", args...)
+	debug.Printf(format, args...)
 }
 
 // KeyTypeConfig 定义密钥生成的配置
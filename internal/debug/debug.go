@@ -0,0 +1,15 @@
+// Package debug 输出调试日志，仅在设置环境变量 SSHIELD_DEBUG 时生效
+package debug
+
+import (
+	"fmt"
+	"os"
+)
+
+// Printf 输出一行带 [sshield-debug] 前缀的调试日志
+func Printf(format string, args ...interface{}) {
+	if os.Getenv("SSHIELD_DEBUG") == "" {
+		return
+	}
+	fmt.Printf("[sshield-debug] "+format+"\n", args...)
+}